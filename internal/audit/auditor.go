@@ -2,6 +2,7 @@
 package audit
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,63 +12,161 @@ import (
 	"time"
 )
 
-const maxAuditLogBytes = 10 << 20 // 10MB per file
+// DefaultMaxFileBytes is the rotation size when Options.MaxFileBytes is not set.
+const DefaultMaxFileBytes = 10 << 20 // 10MB per file
 
-// Auditor handles audit logging to a file with size-based rotation (10MB per file, filename includes creation date).
+// Log formats accepted by NewAuditor.
+const (
+	FormatText  = "text"  // AUDIT_* lines, each entry ending in ######AUDIT_END######
+	FormatJSONL = "jsonl" // one JSON Entry per line, chained by SHA-256 (see Verify)
+)
+
+// Options configure an Auditor. Zero values keep the defaults: text format, 10MB files, every file kept, no compression.
+type Options struct {
+	Format       string // FormatText or FormatJSONL
+	MaxFileBytes int64  // a new file is started when an entry would take the current one to this size
+
+	// Retention, applied to rotated files (never the one being written) at startup, after each rotation and hourly:
+	// files last written more than MaxAge ago are removed, then the oldest ones while all files together take more
+	// than MaxTotalBytes or number more than MaxFiles. Zero disables each limit.
+	MaxAge        time.Duration
+	MaxTotalBytes int64
+	MaxFiles      int
+
+	// Compress gzips rotated files in the background, as base_YYYY-MM-DD_HHMMSS.ext.gz; Search and Verify read them.
+	Compress bool
+}
+
+// Auditor handles audit logging to a file with size-based rotation (filename includes creation date).
 type Auditor struct {
 	file        *os.File
+	path        string // of file
 	mu          sync.Mutex
 	currentSize int64
 	maxSize     int64
 	dir         string
 	base        string
 	ext         string
+	format      string
+	opts        Options
+
+	// seq and prevHash are the number and hash of the last JSONL record written, across rotated files.
+	seq      int64
+	prevHash string
+
+	// onError receives write, rotation and maintenance errors; failing suppresses repeats until a write succeeds.
+	onError func(error)
+	errMu   sync.Mutex
+	failing bool
+
+	// maintain wakes the maintenance goroutine (compression and retention); it stops when stop is closed.
+	maintain chan struct{}
+	stop     chan struct{}
+	stopped  chan struct{}
 }
 
-// NewAuditor creates a new Auditor. On startup reuses the most recent existing log file that is under 10MB; only creates a new file (with creation date in name) when none exists or all are full.
-func NewAuditor(logFile string) (*Auditor, error) {
-	dir := filepath.Dir(logFile)
-	base := strings.TrimSuffix(filepath.Base(logFile), filepath.Ext(logFile))
-	if base == "" {
-		base = "audit"
+// NewAuditor creates a new Auditor. On startup reuses the most recent existing log file that is under the rotation size and in the same format; only creates a new file (with creation date in name) when none exists or all are full.
+// A JSONL log continues the hash chain from the last record of the existing files.
+func NewAuditor(logFile string, opts Options) (*Auditor, error) {
+	dir, base, ext := splitLogFile(logFile)
+
+	if opts.Format == "" {
+		opts.Format = FormatText
 	}
-	ext := filepath.Ext(logFile)
-	if ext == "" {
-		ext = ".log"
+	if opts.Format != FormatText && opts.Format != FormatJSONL {
+		return nil, fmt.Errorf("unknown audit log format %q", opts.Format)
+	}
+	if opts.MaxFileBytes <= 0 {
+		opts.MaxFileBytes = DefaultMaxFileBytes
 	}
 
 	a := &Auditor{
-		maxSize: maxAuditLogBytes,
-		dir:     dir,
-		base:    base,
-		ext:     ext,
+		maxSize:  opts.MaxFileBytes,
+		dir:      dir,
+		base:     base,
+		ext:      ext,
+		format:   opts.Format,
+		opts:     opts,
+		maintain: make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	if opts.Format == FormatJSONL {
+		a.seq, a.prevHash = lastRecord(logFiles(dir, base, ext))
 	}
 	if err := a.openOrCreate(); err != nil {
 		return nil, err
 	}
+	go a.maintenanceLoop()
+	a.wakeMaintenance()
 	return a, nil
 }
 
-// openOrCreate finds the most recent existing log file under maxSize and opens it for append, or creates a new file if none.
-func (a *Auditor) openOrCreate() error {
-	pattern := filepath.Join(a.dir, a.base+"_*"+a.ext)
-	matches, err := filepath.Glob(pattern)
+// SetErrorHandler makes the Auditor report errors it cannot return to f: entries that could not be written,
+// failed rotations (the entry then goes to the current file), and failed compression or retention. A failing
+// write is reported once until writes succeed again. Without a handler the errors go to stderr.
+func (a *Auditor) SetErrorHandler(f func(error)) {
+	a.errMu.Lock()
+	defer a.errMu.Unlock()
+	a.onError = f
+}
+
+// reportError passes err to the error handler.
+func (a *Auditor) reportError(err error) {
+	a.errMu.Lock()
+	f := a.onError
+	a.errMu.Unlock()
+	if f == nil {
+		fmt.Fprintf(os.Stderr, "oracle-mcp: %v\n", err)
+		return
+	}
+	f(err)
+}
+
+// logFiles returns the log files of base and ext in dir, oldest first (names are base_2006-01-02_150405.ext, or
+// .ext.gz once compressed). While a file is being compressed only the uncompressed one is listed.
+func logFiles(dir, base, ext string) []string {
+	plain, err := filepath.Glob(filepath.Join(dir, base+"_*"+ext))
 	if err != nil {
-		return a.rotateOpen()
+		return nil
+	}
+	compressed, _ := filepath.Glob(filepath.Join(dir, base+"_*"+ext+gzipExt))
+	seen := make(map[string]bool, len(plain))
+	for _, path := range plain {
+		seen[path] = true
 	}
-	// Filename base_2006-01-02_150405.log; sort descending = newest first
-	sort.Sort(sort.Reverse(sort.StringSlice(matches)))
-	for _, path := range matches {
+	matches := plain
+	for _, path := range compressed {
+		if !seen[strings.TrimSuffix(path, gzipExt)] {
+			matches = append(matches, path)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return strings.TrimSuffix(matches[i], gzipExt) < strings.TrimSuffix(matches[j], gzipExt)
+	})
+	return matches
+}
+
+// openOrCreate finds the most recent existing log file under maxSize and opens it for append, or creates a new file if none.
+func (a *Auditor) openOrCreate() error {
+	matches := logFiles(a.dir, a.base, a.ext)
+	// Newest first
+	for i := len(matches) - 1; i >= 0; i-- {
+		path := matches[i]
+		if strings.HasSuffix(path, gzipExt) {
+			continue
+		}
 		info, err := os.Stat(path)
 		if err != nil || info.IsDir() {
 			continue
 		}
-		if info.Size() < a.maxSize {
+		if info.Size() < a.maxSize && appendable(path, a.format) {
 			file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
 			if err != nil {
 				continue
 			}
 			a.file = file
+			a.path = path
 			a.currentSize = info.Size()
 			return nil
 		}
@@ -75,12 +174,9 @@ func (a *Auditor) openOrCreate() error {
 	return a.rotateOpen()
 }
 
-// rotateOpen closes the current file (if any) and opens a new one with name base_YYYY-MM-DD_HHMMSS.ext.
+// rotateOpen opens a new file with name base_YYYY-MM-DD_HHMMSS.ext and closes the current one (if any). When the
+// new file cannot be opened, the current one stays open.
 func (a *Auditor) rotateOpen() error {
-	if a.file != nil {
-		a.file.Close()
-		a.file = nil
-	}
 	name := fmt.Sprintf("%s_%s%s", a.base, time.Now().Format("2006-01-02_150405"), a.ext)
 	path := filepath.Join(a.dir, name)
 	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
@@ -92,51 +188,133 @@ func (a *Auditor) rotateOpen() error {
 		file.Close()
 		return fmt.Errorf("failed to stat audit log file: %w", err)
 	}
+	if a.file != nil {
+		a.file.Close()
+	}
 	a.file = file
+	a.path = path
 	a.currentSize = info.Size()
 	return nil
 }
 
-// Log writes an audit entry to the log file. When the current file reaches 10MB, a new file is opened (name includes creation date).
-func (a *Auditor) Log(sql string, matchedKeywords []string, approved bool, action string, connection string) {
+// Log writes an audit entry to the log file. When the current file reaches the rotation size, a new file is opened (name includes creation date).
+// binds are display strings for bind variables (e.g. ":id = 42 (number)"); when present they are written as one AUDIT_BINDS line.
+func (a *Auditor) Log(sql string, matchedKeywords []string, binds []string, approved bool, action string, connection string) {
+	a.LogRule(sql, matchedKeywords, binds, approved, action, connection, "")
+}
+
+// LogRule is Log for calls checked by the policy engine: rule, the name of the rule that decided, is written as
+// AUDIT_RULE when not empty.
+func (a *Auditor) LogRule(sql string, matchedKeywords []string, binds []string, approved bool, action string, connection string, rule string) {
+	a.Write(Entry{
+		Connection: connection,
+		Keywords:   matchedKeywords,
+		Approved:   approved,
+		Action:     action,
+		Rule:       rule,
+		Binds:      binds,
+		SQL:        sql,
+	})
+}
+
+// Write writes e to the log; Time is set when zero. The text format keeps only the fields Log writes, the JSONL
+// format writes all of them and sets Seq and PrevHash.
+func (a *Auditor) Write(e Entry) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	timestamp := time.Now().Format(time.RFC3339)
-	keywords := "none"
-	if len(matchedKeywords) > 0 {
-		keywords = strings.Join(matchedKeywords, ",")
+	if e.Time.IsZero() {
+		e.Time = time.Now()
 	}
-	if connection == "" {
-		connection = "default"
+	if e.Connection == "" {
+		e.Connection = "default"
 	}
-
-	header := fmt.Sprintf("AUDIT_TIME=%s\nAUDIT_CONNECTION=%s\nAUDIT_KEYWORDS=%s\nAUDIT_APPROVED=%v\nAUDIT_ACTION=%s\nAUDIT_SQL=\n",
-		timestamp, connection, keywords, approved, action)
-	entry := header + sql
-	if !strings.HasSuffix(sql, "\n") {
-		entry += "\n"
+	var entry, hash string
+	if a.format == FormatJSONL {
+		e.Seq = a.seq + 1
+		e.PrevHash = a.prevHash
+		if e.Keywords == nil {
+			e.Keywords = []string{}
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return
+		}
+		hash = hashLine(line)
+		entry = string(line) + "\n"
+	} else {
+		entry = textEntry(e)
 	}
-	entry += "######AUDIT_END######\n"
 	size := int64(len(entry))
 
 	if a.currentSize+size >= a.maxSize && a.currentSize > 0 {
 		if err := a.rotateOpen(); err != nil {
 			// On rotate failure still write to current file to avoid losing the log entry
-			_, _ = a.file.WriteString(entry)
-			a.currentSize += size
-			a.file.Sync()
-			return
+			a.reportError(fmt.Errorf("audit log rotation failed, still writing to %s: %w", a.path, err))
+		} else {
+			a.wakeMaintenance()
 		}
 	}
 
-	a.file.WriteString(entry)
-	a.currentSize += size
-	a.file.Sync()
+	_, err := a.file.WriteString(entry)
+	if err == nil {
+		err = a.file.Sync()
+	}
+	if err == nil {
+		// The chain only advances past records that are in the file
+		a.currentSize += size
+		if a.format == FormatJSONL {
+			a.seq, a.prevHash = e.Seq, hash
+		}
+	} else {
+		// Drop a partly written record so the next one starts on its own line
+		a.file.Truncate(a.currentSize)
+	}
+	a.errMu.Lock()
+	report := err != nil && !a.failing
+	a.failing = err != nil
+	a.errMu.Unlock()
+	if report {
+		a.reportError(fmt.Errorf("failed to write audit log %s: %w", a.path, err))
+	}
 }
 
-// Close closes the audit log file.
+// textEntry formats e in the AUDIT_* text format.
+func textEntry(e Entry) string {
+	keywords := "none"
+	if len(e.Keywords) > 0 {
+		keywords = strings.Join(e.Keywords, ",")
+	}
+
+	header := fmt.Sprintf("AUDIT_TIME=%s\nAUDIT_CONNECTION=%s\nAUDIT_KEYWORDS=%s\nAUDIT_APPROVED=%v\nAUDIT_ACTION=%s\n",
+		e.Time.Format(time.RFC3339), e.Connection, keywords, e.Approved, e.Action)
+	if e.Rule != "" {
+		header += "AUDIT_RULE=" + e.Rule + "\n"
+	}
+	if e.Token != "" {
+		header += "AUDIT_TOKEN=" + e.Token + "\n"
+	}
+	if len(e.Binds) > 0 {
+		header += "AUDIT_BINDS=" + strings.Join(e.Binds, "; ") + "\n"
+	}
+	header += "AUDIT_SQL=\n"
+	entry := header + e.SQL
+	if !strings.HasSuffix(e.SQL, "\n") {
+		entry += "\n"
+	}
+	return entry + textEntryEnd
+}
+
+// Close waits for running compression and retention to finish and closes the audit log file.
 func (a *Auditor) Close() error {
+	select {
+	case <-a.stop:
+	default:
+		close(a.stop)
+	}
+	<-a.stopped
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	if a.file != nil {
 		return a.file.Close()
 	}
@@ -0,0 +1,153 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Entry is one audit record. In the JSONL format it is written as one JSON object per line.
+type Entry struct {
+	// Seq numbers the records of a JSONL log from 1, across rotated files; 0 for entries read from a text log.
+	Seq           int64     `json:"seq,omitempty"`
+	Time          time.Time `json:"time"`
+	Connection    string    `json:"connection"`
+	StatementType string    `json:"statement_type,omitempty"`
+	Keywords      []string  `json:"keywords"`
+	Approved      bool      `json:"approved"`
+	Action        string    `json:"action"`
+	Rule          string    `json:"rule,omitempty"`
+	SQL           string    `json:"sql"`
+	Binds         []string  `json:"binds,omitempty"`
+	// DurationMS is the time from receiving the call to writing the record, review included.
+	DurationMS int64 `json:"duration_ms"`
+	// RowsAffected is set for calls that ran SQL: rows changed, or rows written by query_to_*_file.
+	RowsAffected *int64  `json:"rows_affected,omitempty"`
+	Client       *Client `json:"client,omitempty"`
+	// Token is the name of the bearer token of the http session that made the call; "" on stdio.
+	Token     string `json:"token,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	// PrevHash is the hex SHA-256 of the previous record's line (without its newline); "" for the first record.
+	PrevHash string `json:"prev_hash,omitempty"`
+}
+
+// Client is the MCP client that made the call, as given in initialize.
+type Client struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// hashLine returns the hex SHA-256 of one JSONL record, the PrevHash of the record after it.
+func hashLine(line []byte) string {
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
+
+// appendable reports whether new entries in format can be appended to the log file at path: it is empty, or holds
+// complete entries in the same format.
+func appendable(path string, format string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	if len(data) == 0 {
+		return true
+	}
+	if (data[0] == '{') != (format == FormatJSONL) {
+		return false
+	}
+	// A JSONL record cut short (e.g. by a crash) would run into the next one
+	return format != FormatJSONL || data[len(data)-1] == '\n'
+}
+
+// lastRecord returns the Seq and hash of the last complete JSONL record in files (oldest first), so a new Auditor
+// continues the chain; 0 and "" when there is none.
+func lastRecord(files []string) (int64, string) {
+	for i := len(files) - 1; i >= 0; i-- {
+		data, err := readLogFile(files[i])
+		if err != nil || len(data) == 0 || data[0] != '{' {
+			continue
+		}
+		lines := bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+		for j := len(lines) - 1; j >= 0; j-- {
+			var e Entry
+			if json.Unmarshal(lines[j], &e) == nil && e.Seq > 0 {
+				return e.Seq, hashLine(lines[j])
+			}
+		}
+	}
+	return 0, ""
+}
+
+// VerifyResult describes a JSONL audit log whose hash chain is intact.
+type VerifyResult struct {
+	Files   int   // JSONL files checked
+	Records int64 // records checked
+	// FirstSeq is the Seq of the oldest record; above 1 when older files were removed.
+	FirstSeq int64
+	LastSeq  int64
+	// Head is the hash of the newest record. Records removed from the end of the newest file leave an intact chain,
+	// so compare Head with one noted earlier (or with what a log collector received) to detect that.
+	Head string
+	// Skipped lists files in the text format, which carry no hashes.
+	Skipped []string
+}
+
+// Verify checks the hash chain of the JSONL audit log at logFile (the logging.log_file path; its rotated files
+// base_YYYY-MM-DD_HHMMSS.ext, compressed or not, are read oldest first). It returns an error naming the file and line of the first
+// record that was edited, removed, reordered or cut short.
+func Verify(logFile string) (*VerifyResult, error) {
+	dir, base, ext := splitLogFile(logFile)
+	files := logFiles(dir, base, ext)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no audit log files match %s", filepath.Join(dir, base+"_*"+ext))
+	}
+
+	res := &VerifyResult{}
+	for _, path := range files {
+		data, err := readLogFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		if len(data) == 0 {
+			continue
+		}
+		if data[0] != '{' {
+			res.Skipped = append(res.Skipped, path)
+			continue
+		}
+		res.Files++
+		if data[len(data)-1] != '\n' {
+			return nil, fmt.Errorf("%s: the last record is incomplete (the file was truncated)", path)
+		}
+		for i, line := range bytes.Split(data[:len(data)-1], []byte("\n")) {
+			var e Entry
+			if err := json.Unmarshal(line, &e); err != nil || e.Seq <= 0 {
+				return nil, fmt.Errorf("%s:%d: not an audit record", path, i+1)
+			}
+			switch {
+			case res.Records == 0:
+				if e.Seq == 1 && e.PrevHash != "" {
+					return nil, fmt.Errorf("%s:%d: record 1 has a prev_hash", path, i+1)
+				}
+				res.FirstSeq = e.Seq
+			case e.Seq != res.LastSeq+1:
+				return nil, fmt.Errorf("%s:%d: record %d follows record %d: records were removed or reordered", path, i+1, e.Seq, res.LastSeq)
+			case e.PrevHash != res.Head:
+				return nil, fmt.Errorf("%s:%d: prev_hash of record %d does not match record %d: the log was modified", path, i+1, e.Seq, res.LastSeq)
+			}
+			res.Records++
+			res.LastSeq = e.Seq
+			res.Head = hashLine(line)
+		}
+	}
+	if res.Records == 0 {
+		return nil, fmt.Errorf("no JSONL audit records in %s", filepath.Join(dir, base+"_*"+ext))
+	}
+	return res, nil
+}
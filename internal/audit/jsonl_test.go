@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeChain writes n JSONL entries to a log in dir, each with a new Auditor that continues the chain.
+func writeChain(t *testing.T, dir string, n int) {
+	t.Helper()
+	logFile := filepath.Join(dir, "audit.jsonl")
+	for i := 0; i < n; i++ {
+		a, err := NewAuditor(logFile, Options{Format: FormatJSONL})
+		if err != nil {
+			t.Fatalf("NewAuditor: %v", err)
+		}
+		a.Log("DELETE FROM emp WHERE id = "+strings.Repeat("1", i+1), []string{"delete"}, nil, true, "SUCCESS", "db1")
+		a.Close()
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	writeChain(t, dir, 3)
+	res, err := Verify(filepath.Join(dir, "audit.jsonl"))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if res.Records != 3 || res.FirstSeq != 1 || res.LastSeq != 3 || res.Head == "" {
+		t.Errorf("result = %+v", res)
+	}
+}
+
+func TestVerify_RotatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeChain(t, dir, 4)
+	// Split the records over two rotated files
+	path := logFiles(dir, "audit", ".jsonl")[0]
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	older := filepath.Join(dir, "audit_2020-01-01_000000.jsonl")
+	newer := filepath.Join(dir, "audit_2020-01-02_000000.jsonl")
+	os.Remove(path)
+	os.WriteFile(older, bytes.Join(lines[:2], nil), 0644)
+	os.WriteFile(newer, bytes.Join(lines[2:], nil), 0644)
+	if res, err := Verify(filepath.Join(dir, "audit.jsonl")); err != nil || res.Files != 2 || res.Records != 4 {
+		t.Fatalf("Verify = %+v, %v", res, err)
+	}
+
+	// Records cut from the end of the older file break the link to the newer one
+	os.WriteFile(older, lines[0], 0644)
+	if _, err := Verify(filepath.Join(dir, "audit.jsonl")); err == nil || !strings.Contains(err.Error(), "audit_2020-01-02_000000.jsonl:1: record 3 follows record 1") {
+		t.Errorf("Verify error = %v", err)
+	}
+
+	// Removing the oldest file, as retention does, leaves a chain that starts later
+	os.Remove(older)
+	if res, err := Verify(filepath.Join(dir, "audit.jsonl")); err != nil || res.FirstSeq != 3 || res.LastSeq != 4 {
+		t.Errorf("Verify after removing the oldest file = %+v, %v", res, err)
+	}
+
+	// A new Auditor continues from the newest record
+	a, err := NewAuditor(filepath.Join(dir, "audit.jsonl"), Options{Format: FormatJSONL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Log("COMMIT", nil, nil, true, "COMMIT", "db1")
+	a.Close()
+	if res, err := Verify(filepath.Join(dir, "audit.jsonl")); err != nil || res.LastSeq != 5 {
+		t.Errorf("Verify after appending = %+v, %v", res, err)
+	}
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(data []byte) []byte
+		want   string
+	}{
+		{"edited", func(data []byte) []byte {
+			return bytes.Replace(data, []byte(`"approved":true`), []byte(`"approved":false`), 1)
+		}, "the log was modified"},
+		{"removed", func(data []byte) []byte {
+			lines := bytes.SplitAfter(data, []byte("\n"))
+			return bytes.Join(append(lines[:1], lines[2:]...), nil)
+		}, "records were removed"},
+		{"cut short", func(data []byte) []byte { return data[:len(data)-5] }, "incomplete"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeChain(t, dir, 4)
+			first := logFiles(dir, "audit", ".jsonl")[0]
+			data, err := os.ReadFile(first)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(first, tt.change(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err = Verify(filepath.Join(dir, "audit.jsonl"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Verify error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditor_FailedWriteDoesNotAdvanceChain(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "audit.jsonl")
+	a, err := NewAuditor(logFile, Options{Format: FormatJSONL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	a.SetErrorHandler(func(error) {})
+	a.Log("SELECT 1 FROM dual", nil, nil, true, "SUCCESS", "db1")
+
+	// A read-only handle fails every write, as a full or lost disk does
+	writable := a.file
+	readOnly, err := os.Open(a.file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.file = readOnly
+	a.Log("SELECT 2 FROM dual", nil, nil, true, "SUCCESS", "db1")
+	a.file = writable
+	readOnly.Close()
+
+	a.Log("SELECT 3 FROM dual", nil, nil, true, "SUCCESS", "db1")
+	if res, err := Verify(logFile); err != nil || res.Records != 2 || res.LastSeq != 2 {
+		t.Errorf("Verify = %+v, %v; want records 1 and 2 chained", res, err)
+	}
+}
@@ -0,0 +1,189 @@
+package audit
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+// gzipExt is appended to the name of a compressed log file.
+const gzipExt = ".gz"
+
+// maintenanceInterval is how often retention runs on a server that does not rotate, so MaxAge still applies.
+const maintenanceInterval = time.Hour
+
+// wakeMaintenance asks the maintenance goroutine to compress and apply retention; it never blocks.
+func (a *Auditor) wakeMaintenance() {
+	select {
+	case a.maintain <- struct{}{}:
+	default:
+	}
+}
+
+// maintenanceLoop compresses rotated files and applies retention when woken and hourly, until Close.
+func (a *Auditor) maintenanceLoop() {
+	defer close(a.stopped)
+	ticker := time.NewTicker(maintenanceInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.stop:
+			// Finish a rotation that happened just before Close
+			select {
+			case <-a.maintain:
+				a.runMaintenance()
+			default:
+			}
+			return
+		case <-a.maintain:
+		case <-ticker.C:
+		}
+		a.runMaintenance()
+	}
+}
+
+// runMaintenance compresses the rotated files, then removes the ones retention no longer keeps.
+func (a *Auditor) runMaintenance() {
+	a.mu.Lock()
+	current := a.path
+	a.mu.Unlock()
+
+	if a.opts.Compress {
+		for _, path := range logFiles(a.dir, a.base, a.ext) {
+			if path == current || strings.HasSuffix(path, gzipExt) {
+				continue
+			}
+			if err := compressFile(path); err != nil {
+				a.reportError(fmt.Errorf("failed to compress audit log: %w", err))
+			}
+		}
+	}
+	if err := a.applyRetention(current); err != nil {
+		a.reportError(fmt.Errorf("audit log retention: %w", err))
+	}
+}
+
+// compressFile writes path to path.gz and removes path. The compressed file appears under its final name only
+// once it is complete, and keeps the modification time of path so MaxAge still counts from the last write.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	tmp := path + gzipExt + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chtimes(tmp, info.ModTime(), info.ModTime())
+	}
+	if err == nil {
+		err = os.Rename(tmp, path+gzipExt)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	src.Close()
+	return os.Remove(path)
+}
+
+// applyRetention removes rotated files past MaxAge, then the oldest ones while the files exceed MaxTotalBytes or
+// MaxFiles. current, the file being written, is counted but never removed.
+func (a *Auditor) applyRetention(current string) error {
+	if a.opts.MaxAge <= 0 && a.opts.MaxTotalBytes <= 0 && a.opts.MaxFiles <= 0 {
+		return nil
+	}
+	type logFile struct {
+		path string
+		size int64
+	}
+	var files []logFile
+	var total int64
+	var firstErr error
+	for _, path := range logFiles(a.dir, a.base, a.ext) {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if path != current && a.opts.MaxAge > 0 && time.Since(info.ModTime()) > a.opts.MaxAge {
+			if err := os.Remove(path); err != nil && firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		files = append(files, logFile{path, info.Size()})
+		total += info.Size()
+	}
+	// Oldest first
+	count := len(files)
+	for _, f := range files {
+		over := (a.opts.MaxTotalBytes > 0 && total > a.opts.MaxTotalBytes) || (a.opts.MaxFiles > 0 && count > a.opts.MaxFiles)
+		if !over {
+			break
+		}
+		if f.path == current {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		total -= f.size
+		count--
+	}
+	return firstErr
+}
+
+// readLogFile returns the contents of a log file, decompressing .gz files. A plain file that was compressed since
+// it was listed is read from its .gz.
+func readLogFile(path string) ([]byte, error) {
+	if !strings.HasSuffix(path, gzipExt) {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			if gz, gzErr := readLogFile(path + gzipExt); gzErr == nil {
+				return gz, nil
+			}
+		}
+		return data, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return data, nil
+}
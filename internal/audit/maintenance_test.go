@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeRotated writes a full rotated text log file named base_<stamp>.log with one entry, last modified at mod.
+func writeRotated(t *testing.T, dir, stamp string, mod time.Time) string {
+	t.Helper()
+	path := filepath.Join(dir, "audit_"+stamp+".log")
+	entry := textEntry(Entry{Time: mod, Connection: "db1", Action: "SUCCESS", SQL: "UPDATE t SET x = 1 -- " + stamp + strings.Repeat(" ", 100)})
+	if err := os.WriteFile(path, []byte(entry), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAuditor_CompressAndRetention(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	oldest := writeRotated(t, dir, "2020-01-01_000000", now.Add(-60*24*time.Hour))
+	writeRotated(t, dir, "2020-01-02_000000", now.Add(-3*time.Hour))
+	writeRotated(t, dir, "2020-01-03_000000", now.Add(-2*time.Hour))
+	writeRotated(t, dir, "2020-01-04_000000", now.Add(-1*time.Hour))
+
+	a, err := NewAuditor(filepath.Join(dir, "audit.log"), Options{MaxFileBytes: 100, MaxAge: 30 * 24 * time.Hour, MaxFiles: 3, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Log("SELECT 1 FROM dual", nil, nil, true, "SUCCESS", "db1")
+	a.Close()
+
+	// The 60-day-old file is past MaxAge; of the rest, MaxFiles keeps the newest two rotated files and the current one
+	files := logFiles(dir, "audit", ".log")
+	if len(files) != 3 {
+		t.Fatalf("files = %v, want 3", files)
+	}
+	if _, err := os.Stat(oldest); !os.IsNotExist(err) {
+		t.Errorf("%s was not removed", oldest)
+	}
+	for _, path := range files[:2] {
+		if !strings.HasSuffix(path, ".log.gz") {
+			t.Errorf("%s was not compressed", path)
+		}
+	}
+	if strings.HasSuffix(files[2], ".gz") {
+		t.Errorf("current file %s was compressed", files[2])
+	}
+	// Compression keeps the original modification time, which MaxAge is measured from
+	info, err := os.Stat(files[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := info.ModTime().Sub(now.Add(-1 * time.Hour)); d < -time.Second || d > time.Second {
+		t.Errorf("%s modified at %v, want %v", files[1], info.ModTime(), now.Add(-1*time.Hour))
+	}
+
+	res, err := Search(filepath.Join(dir, "audit.log"), Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 3 || res.Entries[0].SQL != "SELECT 1 FROM dual" || !strings.Contains(res.Entries[2].SQL, "2020-01-03") {
+		t.Errorf("search over compressed files = %+v", res)
+	}
+}
+
+func TestReadLogFile_CompressedSinceListed(t *testing.T) {
+	dir := t.TempDir()
+	path := writeRotated(t, dir, "2020-01-01_000000", time.Now())
+	if err := compressFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := readLogFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "2020-01-01_000000") {
+		t.Errorf("readLogFile = %q, want the compressed contents", data)
+	}
+	if _, err := readLogFile(filepath.Join(dir, "audit_missing.log")); !os.IsNotExist(err) {
+		t.Errorf("readLogFile of a missing file: err = %v, want not exist", err)
+	}
+}
+
+func TestAuditor_ReportsWriteErrors(t *testing.T) {
+	a, err := NewAuditor(filepath.Join(t.TempDir(), "audit.log"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	var reported []error
+	a.SetErrorHandler(func(err error) { reported = append(reported, err) })
+
+	a.file.Close() // as when the disk is gone
+	a.Log("SELECT 1 FROM dual", nil, nil, true, "SUCCESS", "db1")
+	a.Log("SELECT 2 FROM dual", nil, nil, true, "SUCCESS", "db1")
+	if len(reported) != 1 || !strings.Contains(reported[0].Error(), "failed to write audit log") {
+		t.Errorf("reported = %v, want one write error", reported)
+	}
+}
@@ -0,0 +1,181 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Query selects audit entries for Search. Zero fields do not filter.
+type Query struct {
+	Since time.Time // entries at or after Since
+	Until time.Time // entries before Until
+	// Connection matches the connection name exactly (case-insensitive).
+	Connection string
+	// Action matches the action, or its prefix before ": " (EXECUTION_ERROR matches "EXECUTION_ERROR: ORA-00942 ...").
+	Action string
+	// Keyword matches one of the matched danger keywords (case-insensitive).
+	Keyword string
+	// SQLContains matches a substring of the SQL (case-insensitive).
+	SQLContains string
+
+	Offset int // entries to skip, newest first
+	Limit  int // entries to return; 0 returns all
+}
+
+// SearchResult is one page of matching entries, newest first.
+type SearchResult struct {
+	Total   int     `json:"total"` // entries matching the query, on all pages
+	Entries []Entry `json:"entries"`
+}
+
+// Search reads the audit log at logFile (the logging.log_file path; entries are in its rotated files
+// base_YYYY-MM-DD_HHMMSS.ext or .ext.gz, in the text or the JSONL format) and returns the entries that match q.
+func Search(logFile string, q Query) (*SearchResult, error) {
+	return searchFiles(logFiles(splitLogFile(logFile)), q)
+}
+
+// Search is the package Search on the log a writes.
+func (a *Auditor) Search(q Query) (*SearchResult, error) {
+	return searchFiles(logFiles(a.dir, a.base, a.ext), q)
+}
+
+// searchFiles reads files (oldest first) newest first and returns the page of q.
+func searchFiles(files []string, q Query) (*SearchResult, error) {
+	res := &SearchResult{Entries: []Entry{}}
+	for i := len(files) - 1; i >= 0; i-- {
+		entries, err := ReadFile(files[i])
+		if err != nil {
+			return nil, err
+		}
+		// Files hold entries in the order written
+		for j := len(entries) - 1; j >= 0; j-- {
+			e := entries[j]
+			if !q.matches(e) {
+				continue
+			}
+			if res.Total >= q.Offset && (q.Limit == 0 || len(res.Entries) < q.Limit) {
+				res.Entries = append(res.Entries, e)
+			}
+			res.Total++
+		}
+	}
+	return res, nil
+}
+
+// matches reports whether e passes every filter of q.
+func (q Query) matches(e Entry) bool {
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !e.Time.Before(q.Until) {
+		return false
+	}
+	if q.Connection != "" && !strings.EqualFold(e.Connection, q.Connection) {
+		return false
+	}
+	if q.Action != "" {
+		action, _, _ := strings.Cut(e.Action, ":")
+		if !strings.EqualFold(e.Action, q.Action) && !strings.EqualFold(strings.TrimSpace(action), q.Action) {
+			return false
+		}
+	}
+	if q.Keyword != "" {
+		found := false
+		for _, kw := range e.Keywords {
+			if strings.EqualFold(kw, q.Keyword) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return q.SQLContains == "" || strings.Contains(strings.ToLower(e.SQL), strings.ToLower(q.SQLContains))
+}
+
+// ReadFile returns the entries of one audit log file in either format, gzip-compressed or not, in file order. A record cut short at the
+// end of the file (one being written) is skipped.
+func ReadFile(path string) ([]Entry, error) {
+	data, err := readLogFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	if len(data) > 0 && data[0] == '{' {
+		return parseJSONL(data), nil
+	}
+	return parseText(data), nil
+}
+
+// parseJSONL parses the complete lines of a JSONL log.
+func parseJSONL(data []byte) []Entry {
+	var entries []Entry
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var e Entry
+		if len(line) > 0 && json.Unmarshal(line, &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// textEntryEnd ends each record of the text format.
+const textEntryEnd = "######AUDIT_END######\n"
+
+// parseText parses the records of a text log: AUDIT_* header lines, then the SQL after AUDIT_SQL=.
+func parseText(data []byte) []Entry {
+	records := strings.Split(string(data), textEntryEnd)
+	// The last element is what follows the last separator: empty, or a record still being written
+	records = records[:len(records)-1]
+	entries := make([]Entry, 0, len(records))
+	for _, rec := range records {
+		header, sql, ok := strings.Cut(rec, "AUDIT_SQL=\n")
+		if !ok {
+			continue
+		}
+		e := Entry{SQL: strings.TrimSuffix(sql, "\n")}
+		for _, line := range strings.Split(header, "\n") {
+			key, value, _ := strings.Cut(line, "=")
+			switch key {
+			case "AUDIT_TIME":
+				e.Time, _ = time.Parse(time.RFC3339, value)
+			case "AUDIT_CONNECTION":
+				e.Connection = value
+			case "AUDIT_KEYWORDS":
+				if value != "none" && value != "" {
+					e.Keywords = strings.Split(value, ",")
+				}
+			case "AUDIT_APPROVED":
+				e.Approved = value == "true"
+			case "AUDIT_ACTION":
+				e.Action = value
+			case "AUDIT_RULE":
+				e.Rule = value
+			case "AUDIT_TOKEN":
+				e.Token = value
+			case "AUDIT_BINDS":
+				e.Binds = strings.Split(value, "; ")
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// splitLogFile splits the logging.log_file path into the directory, base name and extension of its rotated files.
+func splitLogFile(logFile string) (dir, base, ext string) {
+	dir = filepath.Dir(logFile)
+	ext = filepath.Ext(logFile)
+	base = strings.TrimSuffix(filepath.Base(logFile), ext)
+	if base == "" {
+		base = "audit"
+	}
+	if ext == "" {
+		ext = ".log"
+	}
+	return dir, base, ext
+}
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSearch(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	// An older text file and a newer JSONL file, as after switching logging.audit_format
+	text, err := NewAuditor(filepath.Join(dir, "audit.log"), Options{Format: FormatText})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text.Write(Entry{Time: day, Connection: "prod", Keywords: []string{"drop"}, Action: "USER_REJECTED", SQL: "DROP TABLE emp"})
+	text.Write(Entry{Time: day.Add(time.Hour), Connection: "prod", Approved: true, Action: "EXECUTION_ERROR: ORA-00942: table or view does not exist", Binds: []string{":id = 1 (number)"}, SQL: "DELETE FROM emp\nWHERE id = :id"})
+	text.Close()
+	os.Rename(logFiles(dir, "audit", ".log")[0], filepath.Join(dir, "audit_2024-05-01_100000.log"))
+	jsonl, err := NewAuditor(filepath.Join(dir, "audit.log"), Options{Format: FormatJSONL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer jsonl.Close()
+	for i := 0; i < 3; i++ {
+		jsonl.Write(Entry{Time: day.Add(24*time.Hour + time.Duration(i)*time.Minute), Connection: "dev", Approved: true, Action: "SUCCESS", SQL: "SELECT 1 FROM dual"})
+	}
+
+	search := func(q Query) *SearchResult {
+		t.Helper()
+		res, err := jsonl.Search(q)
+		if err != nil {
+			t.Fatalf("Search(%+v): %v", q, err)
+		}
+		return res
+	}
+	if res := search(Query{}); res.Total != 5 || res.Entries[0].Seq != 3 || res.Entries[4].SQL != "DROP TABLE emp" {
+		t.Fatalf("all entries = %+v", res)
+	}
+	res := search(Query{Action: "execution_error"})
+	if res.Total != 1 {
+		t.Fatalf("EXECUTION_ERROR entries = %+v", res)
+	}
+	if e := res.Entries[0]; !e.Approved || e.SQL != "DELETE FROM emp\nWHERE id = :id" || len(e.Binds) != 1 || !e.Time.Equal(day.Add(time.Hour)) {
+		t.Errorf("text entry = %+v", e)
+	}
+	if res := search(Query{Since: day, Until: day.Add(24 * time.Hour), Connection: "PROD", Keyword: "DROP"}); res.Total != 1 || res.Entries[0].Action != "USER_REJECTED" {
+		t.Errorf("filtered entries = %+v", res)
+	}
+	if res := search(Query{SQLContains: "from DUAL", Offset: 1, Limit: 1}); res.Total != 3 || len(res.Entries) != 1 || res.Entries[0].Seq != 2 {
+		t.Errorf("second page = %+v", res)
+	}
+}
@@ -4,7 +4,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -15,16 +17,157 @@ type Config struct {
 	Oracle   OracleConfig   `yaml:"oracle"`
 	Security SecurityConfig `yaml:"security"`
 	Logging  LoggingConfig  `yaml:"logging"`
+	Server   ServerConfig   `yaml:"server"`
 
 	// ConfigPath is the path to the loaded config file (set by Load); used to resolve relative paths like audit log.
 	ConfigPath string `yaml:"-"`
 }
 
 // OracleConfig holds Oracle database connection settings.
-// Connections: name -> DSN. Names are used as the "connection" argument in execute_sql.
+// Connections: name -> DSN, or name -> ConnectionConfig. Names are used as the "connection" argument in execute_sql.
 // If only one connection is configured, it is used for all SQL (connection argument optional).
 type OracleConfig struct {
-	Connections map[string]string `yaml:"connections"`
+	Connections map[string]ConnectionConfig `yaml:"connections"`
+	// TransactionIdleTimeoutSeconds rolls back a transaction opened with begin_transaction after this many seconds
+	// without a statement, commit, rollback or savepoint on it. Default 300.
+	TransactionIdleTimeoutSeconds int `yaml:"transaction_idle_timeout_seconds"`
+	// MaxRows is the default row limit for execute_sql / execute_sql_file results (0 = no limit); calls may pass max_rows.
+	// A truncated result keeps its cursor open for fetch_more. Default 500.
+	MaxRows int `yaml:"max_rows"`
+	// ResultHandleTTLSeconds closes a truncated result's cursor after this many seconds without fetch_more. Default 300.
+	ResultHandleTTLSeconds int `yaml:"result_handle_ttl_seconds"`
+	// QueryTimeoutSeconds cancels a statement, query-to-file export or fetch_more page that runs longer (0 = no limit).
+	// Calls may pass a shorter timeout_seconds. Default 0.
+	QueryTimeoutSeconds int `yaml:"query_timeout_seconds"`
+	// QueryTimeouts overrides QueryTimeoutSeconds per connection name (0 = no limit for that connection).
+	QueryTimeouts map[string]int `yaml:"query_timeouts"`
+}
+
+// ConnectionConfig is one entry of oracle.connections. In YAML it is either the DSN string or a mapping with
+// the DSN or its parts:
+//
+//	prod:
+//	  dsn: "user/pass@//host:1521/service"
+//	  read_only: true
+//	adb:
+//	  user: app
+//	  password: ${ADB_PASSWORD}
+//	  service: mydb_high
+//	  wallet_path: /opt/oracle/wallet_mydb
+type ConnectionConfig struct {
+	DSN string `yaml:"dsn"`
+	// User, Password, Host, Port, Service and WalletPath are assembled into the DSN (see DataSource) when dsn is
+	// not set. Without Host, Service is a net service name from tnsnames.ora; Port defaults to 1521. WalletPath is
+	// the directory of tnsnames.ora, sqlnet.ora and the wallet files, used like TNS_ADMIN for this connection.
+	User       string `yaml:"user"`
+	Password   string `yaml:"password"`
+	Host       string `yaml:"host"`
+	Port       int    `yaml:"port"`
+	Service    string `yaml:"service"`
+	WalletPath string `yaml:"wallet_path"`
+	// ReadOnly accepts only queries on the connection: other statements are refused before they reach Oracle, and
+	// each call runs in a read-only transaction (SET TRANSACTION READ ONLY), so a write fails even if one gets through.
+	ReadOnly bool `yaml:"read_only"`
+}
+
+// defaultOraclePort is the listener port of a structured connection without port.
+const defaultOraclePort = 1521
+
+// structured reports whether any of the DSN parts is set.
+func (c ConnectionConfig) structured() bool {
+	return c.User != "" || c.Password != "" || c.Host != "" || c.Port != 0 || c.Service != "" || c.WalletPath != ""
+}
+
+// DataSource returns the data source for the Oracle driver: DSN, or the parts assembled into godror's
+// key=value form (user, password, connectString and, with a wallet, configDir), which takes any character in a password.
+func (c ConnectionConfig) DataSource() string {
+	if c.DSN != "" || !c.structured() {
+		return c.DSN
+	}
+	connectString := c.Service
+	if c.Host != "" {
+		port := c.Port
+		if port == 0 {
+			port = defaultOraclePort
+		}
+		connectString = fmt.Sprintf("%s:%d/%s", c.Host, port, c.Service)
+	}
+	params := []string{
+		"user=" + quoteParam(c.User),
+		"password=" + quoteParam(c.Password),
+		"connectString=" + quoteParam(connectString),
+	}
+	if c.WalletPath != "" {
+		params = append(params, "configDir="+quoteParam(c.WalletPath))
+	}
+	return strings.Join(params, " ")
+}
+
+// quoteParam quotes a value of a godror key=value data source.
+func quoteParam(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// validate checks that the connection has either a DSN or the parts of one. Errors never include the password.
+func (c ConnectionConfig) validate() error {
+	if c.DSN != "" && c.structured() {
+		return fmt.Errorf("set either dsn or user, password, host, port, service and wallet_path, not both")
+	}
+	if c.DSN != "" {
+		if strings.TrimSpace(c.DSN) == "" {
+			return fmt.Errorf("dsn is required")
+		}
+		return nil
+	}
+	switch {
+	case !c.structured():
+		return fmt.Errorf("dsn is required (or user, password and service)")
+	case c.User == "":
+		return fmt.Errorf("user is required")
+	case c.Password == "":
+		return fmt.Errorf("password is required")
+	case c.Service == "":
+		return fmt.Errorf("service is required")
+	case c.Port < 0 || c.Port > 65535:
+		return fmt.Errorf("port must be between 1 and 65535, got %d", c.Port)
+	case c.Port != 0 && c.Host == "":
+		return fmt.Errorf("port is set without host")
+	}
+	return nil
+}
+
+// UnmarshalYAML accepts a plain DSN string as well as the mapping form.
+func (c *ConnectionConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&c.DSN)
+	}
+	type plain ConnectionConfig
+	return value.Decode((*plain)(c))
+}
+
+// ServerConfig selects how MCP clients reach the server.
+type ServerConfig struct {
+	// Transport is "stdio" (default: the one client that started the process) or "http": MCP Streamable HTTP on
+	// Listen at /mcp, plus the older HTTP+SSE transport (/sse and /messages) for clients that need it.
+	Transport string `yaml:"transport"`
+	// Listen is the host:port for the http transport. Default "127.0.0.1:8080".
+	Listen string `yaml:"listen"`
+	// AuthTokens are the bearer tokens the http transport accepts (Authorization: Bearer <token>). Required for http.
+	AuthTokens []AuthToken `yaml:"auth_tokens"`
+	// AllowedOrigins lists browser origins (e.g. "https://inspector.example.com") allowed besides the server's own host.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	// TLSCertFile and TLSKeyFile serve https when both are set.
+	TLSCertFile string `yaml:"tls_cert_file"`
+	TLSKeyFile  string `yaml:"tls_key_file"`
+	// SessionIdleTimeoutSeconds closes an http session without requests or open streams for this long, rolling back
+	// its open transactions. Default 1800.
+	SessionIdleTimeoutSeconds int `yaml:"session_idle_timeout_seconds"`
+}
+
+// AuthToken is a bearer token of the http transport. Name identifies whoever holds it in the audit log.
+type AuthToken struct {
+	Name  string `yaml:"name"`
+	Token string `yaml:"token"`
 }
 
 // SecurityConfig holds security-related settings.
@@ -32,6 +175,51 @@ type SecurityConfig struct {
 	DangerKeywords      []string `yaml:"danger_keywords"`
 	DangerKeywordMatch  string   `yaml:"danger_keyword_match"` // "whole_text" (default) or "tokens"
 	RequireConfirmForDDL bool    `yaml:"require_confirm_for_ddl"`
+	// ConfirmMode selects how review is shown: "dialog" (default, OS window), "elicitation"
+	// (MCP elicitation/create inside the client; falls back to dialog when the client does not support it)
+	// or "browser" (review page on a one-time localhost URL in the default browser).
+	ConfirmMode string `yaml:"confirm_mode"`
+	// BrowserConfirmTimeoutSeconds is how long the browser review page waits; no answer counts as Cancel. Default 300.
+	BrowserConfirmTimeoutSeconds int `yaml:"browser_confirm_timeout_seconds"`
+	// ConfirmUnboundedDML reviews UPDATE, DELETE and MERGE statements without a limiting WHERE clause (none, or an
+	// always true one such as 1 = 1); the dialog shows a COUNT(*) estimate of the rows they change. Default true.
+	ConfirmUnboundedDML bool `yaml:"confirm_unbounded_dml"`
+	// SecondConfirmRowThreshold asks for a second, explicit confirmation when the estimate is above this many rows
+	// (0 = never). Default 10000.
+	SecondConfirmRowThreshold int64 `yaml:"second_confirm_row_threshold"`
+	// Rules are checked for each statement before danger_keywords, require_confirm_for_ddl and
+	// confirm_unbounded_dml, which act as the last three rules (action confirm); the first rule that matches
+	// decides. See PolicyRule.
+	Rules []PolicyRule `yaml:"rules"`
+	// Redact masks passwords (IDENTIFIED BY, DBMS_CREDENTIAL), secret binds and personal data in string literals
+	// (e-mail addresses, card and social security numbers) in the audit log, notifications and stderr; the review
+	// shows the masked SQL with a Reveal option. The SQL run is unchanged. Default true.
+	Redact bool `yaml:"redact"`
+	// RedactPatterns are more regular expressions (Go syntax) to mask: their capturing groups, or the whole match.
+	RedactPatterns []string `yaml:"redact_patterns"`
+}
+
+// PolicyRule is one entry of security.rules. It matches a statement when every criterion it sets matches; a list
+// matches when any of its entries does, and a rule without criteria matches every statement. Patterns are
+// case-insensitive and may use the wildcards * and ?.
+type PolicyRule struct {
+	Name string `yaml:"name"`
+	// Action is "allow" (run without review), "confirm" (review first) or "deny" (refuse without review).
+	Action string `yaml:"action"`
+	// StatementTypes are leading keywords, e.g. "UPDATE", "DROP USER" or "CREATE PROCEDURE"; modifiers such as
+	// OR REPLACE, PUBLIC or UNIQUE are skipped.
+	StatementTypes []string `yaml:"statement_types"`
+	// Objects are patterns for the objects the statement works on: "HR.EMPLOYEES", "HR.*", "*.SALARIES" or
+	// "AUDIT_*" (a pattern without a dot matches the name in any schema).
+	Objects []string `yaml:"objects"`
+	// Schemas are patterns for the schemas of those objects; unqualified names are in the connection user's schema.
+	Schemas []string `yaml:"schemas"`
+	// Connections are patterns for the connection name.
+	Connections []string `yaml:"connections"`
+	// Tokens are keywords matched like danger_keywords in tokens mode, e.g. "grant dba".
+	Tokens []string `yaml:"tokens"`
+	// Message is returned to the client when the rule denies a statement.
+	Message string `yaml:"message"`
 }
 
 // LoggingConfig holds logging settings.
@@ -39,13 +227,29 @@ type LoggingConfig struct {
 	AuditLog       bool   `yaml:"audit_log"`
 	VerboseLogging bool   `yaml:"verbose_logging"` // when true, log one line per execute_sql: [debug] Execute Action: <type>, Connection: <name>
 	LogFile        string `yaml:"log_file"`
+	// AuditFormat is "text" (AUDIT_* lines ending in ######AUDIT_END######) or "jsonl" (one JSON object per line,
+	// each carrying the SHA-256 of the record before it).
+	AuditFormat string `yaml:"audit_format"`
+	// AuditMaxFileMB starts a new audit log file when the current one reaches this size.
+	AuditMaxFileMB int `yaml:"audit_max_file_mb"`
+	// AuditRetentionDays removes rotated audit log files last written more than this many days ago (0 = keep).
+	AuditRetentionDays int `yaml:"audit_retention_days"`
+	// AuditMaxTotalMB and AuditMaxFiles remove the oldest rotated files while all audit log files together are
+	// larger or more (0 = no limit).
+	AuditMaxTotalMB int `yaml:"audit_max_total_mb"`
+	AuditMaxFiles   int `yaml:"audit_max_files"`
+	// AuditCompress gzips rotated audit log files in the background.
+	AuditCompress bool `yaml:"audit_compress"`
 }
 
 // DefaultConfig returns a configuration with sensible defaults.
 func DefaultConfig() *Config {
 	return &Config{
 		Oracle: OracleConfig{
-			Connections: nil,
+			Connections:                   nil,
+			TransactionIdleTimeoutSeconds: 300,
+			MaxRows:                       500,
+			ResultHandleTTLSeconds:        300,
 		},
 		Security: SecurityConfig{
 			DangerKeywords: []string{
@@ -58,11 +262,24 @@ func DefaultConfig() *Config {
 			},
 			DangerKeywordMatch:  "whole_text",
 			RequireConfirmForDDL: true,
+			ConfirmMode:          "dialog",
+			BrowserConfirmTimeoutSeconds: 300,
+			ConfirmUnboundedDML:          true,
+			SecondConfirmRowThreshold:    10000,
+			Redact:                       true,
 		},
 		Logging: LoggingConfig{
 			AuditLog:       true,
 			VerboseLogging: true,
 			LogFile:        "audit.log",
+			AuditFormat:    "text",
+			AuditMaxFileMB: 10,
+			AuditCompress:  true,
+		},
+		Server: ServerConfig{
+			Transport:                 "stdio",
+			Listen:                    "127.0.0.1:8080",
+			SessionIdleTimeoutSeconds: 1800,
 		},
 	}
 }
@@ -86,17 +303,28 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
-// LoadFromFile reads and parses a configuration file from the specified path.
+// LoadFromFile reads and parses a configuration file from the specified path. Values may reference environment
+// variables as ${NAME} and be read from a file as file:<path> (relative to the config file), so secrets need not
+// be in it.
 func LoadFromFile(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	config := DefaultConfig()
-	if err := yaml.Unmarshal(data, config); err != nil {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if err := interpolate(&root, filepath.Dir(path)); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	config := DefaultConfig()
+	if root.Kind != 0 {
+		if err := root.Decode(config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", maskValues(err))
+		}
+	}
 
 	// Normalize danger keywords to lowercase
 	for i, kw := range config.Security.DangerKeywords {
@@ -108,6 +336,26 @@ func LoadFromFile(path string) (*Config, error) {
 	} else {
 		config.Security.DangerKeywordMatch = strings.ToLower(strings.TrimSpace(config.Security.DangerKeywordMatch))
 	}
+	config.Security.ConfirmMode = strings.ToLower(strings.TrimSpace(config.Security.ConfirmMode))
+	for i := range config.Security.Rules {
+		rule := &config.Security.Rules[i]
+		rule.Name = strings.TrimSpace(rule.Name)
+		rule.Action = strings.ToLower(strings.TrimSpace(rule.Action))
+	}
+	if config.Security.ConfirmMode == "" {
+		config.Security.ConfirmMode = "dialog"
+	}
+	config.Logging.AuditFormat = strings.ToLower(strings.TrimSpace(config.Logging.AuditFormat))
+	if config.Logging.AuditFormat == "" {
+		config.Logging.AuditFormat = "text"
+	}
+	config.Server.Transport = strings.ToLower(strings.TrimSpace(config.Server.Transport))
+	if config.Server.Transport == "" {
+		config.Server.Transport = "stdio"
+	}
+	for i := range config.Server.AuthTokens {
+		config.Server.AuthTokens[i].Name = strings.TrimSpace(config.Server.AuthTokens[i].Name)
+	}
 
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -121,16 +369,151 @@ func (c *Config) Validate() error {
 	if len(c.Oracle.Connections) == 0 {
 		return fmt.Errorf("oracle.connections is required and must have at least one entry")
 	}
+	for name, conn := range c.Oracle.Connections {
+		if err := conn.validate(); err != nil {
+			return fmt.Errorf("oracle.connections.%s: %w", name, err)
+		}
+	}
+	if c.Oracle.TransactionIdleTimeoutSeconds <= 0 {
+		return fmt.Errorf("oracle.transaction_idle_timeout_seconds must be positive, got %d", c.Oracle.TransactionIdleTimeoutSeconds)
+	}
+	if c.Oracle.MaxRows < 0 {
+		return fmt.Errorf("oracle.max_rows must be 0 (no limit) or positive, got %d", c.Oracle.MaxRows)
+	}
+	if c.Oracle.ResultHandleTTLSeconds <= 0 {
+		return fmt.Errorf("oracle.result_handle_ttl_seconds must be positive, got %d", c.Oracle.ResultHandleTTLSeconds)
+	}
+	if c.Oracle.QueryTimeoutSeconds < 0 {
+		return fmt.Errorf("oracle.query_timeout_seconds must be 0 (no limit) or positive, got %d", c.Oracle.QueryTimeoutSeconds)
+	}
+	for name, seconds := range c.Oracle.QueryTimeouts {
+		if _, ok := c.Oracle.Connections[name]; !ok {
+			return fmt.Errorf("oracle.query_timeouts: unknown connection %q", name)
+		}
+		if seconds < 0 {
+			return fmt.Errorf("oracle.query_timeouts.%s must be 0 (no limit) or positive, got %d", name, seconds)
+		}
+	}
 	mode := c.Security.DangerKeywordMatch
 	if mode != "whole_text" && mode != "tokens" {
 		return fmt.Errorf("security.danger_keyword_match must be \"whole_text\" or \"tokens\", got %q", mode)
 	}
+	switch c.Security.ConfirmMode {
+	case "dialog", "elicitation", "browser":
+	default:
+		return fmt.Errorf("security.confirm_mode must be \"dialog\", \"elicitation\" or \"browser\", got %q", c.Security.ConfirmMode)
+	}
+	if c.Security.BrowserConfirmTimeoutSeconds <= 0 {
+		return fmt.Errorf("security.browser_confirm_timeout_seconds must be positive, got %d", c.Security.BrowserConfirmTimeoutSeconds)
+	}
+	if c.Security.SecondConfirmRowThreshold < 0 {
+		return fmt.Errorf("security.second_confirm_row_threshold must be 0 (never) or positive, got %d", c.Security.SecondConfirmRowThreshold)
+	}
+	if err := validateRules(c.Security.Rules); err != nil {
+		return err
+	}
+	for i, expr := range c.Security.RedactPatterns {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("security.redact_patterns[%d]: %v", i, err)
+		}
+	}
+	if f := c.Logging.AuditFormat; f != "text" && f != "jsonl" {
+		return fmt.Errorf("logging.audit_format must be \"text\" or \"jsonl\", got %q", f)
+	}
+	if c.Logging.AuditMaxFileMB <= 0 {
+		return fmt.Errorf("logging.audit_max_file_mb must be positive, got %d", c.Logging.AuditMaxFileMB)
+	}
+	if c.Logging.AuditRetentionDays < 0 {
+		return fmt.Errorf("logging.audit_retention_days must be 0 (keep) or positive, got %d", c.Logging.AuditRetentionDays)
+	}
+	if c.Logging.AuditMaxTotalMB < 0 {
+		return fmt.Errorf("logging.audit_max_total_mb must be 0 (no limit) or positive, got %d", c.Logging.AuditMaxTotalMB)
+	}
+	if c.Logging.AuditMaxFiles < 0 {
+		return fmt.Errorf("logging.audit_max_files must be 0 (no limit) or positive, got %d", c.Logging.AuditMaxFiles)
+	}
+	switch c.Server.Transport {
+	case "stdio":
+	case "http":
+		if c.Server.Listen == "" {
+			return fmt.Errorf("server.listen is required for the http transport")
+		}
+		if len(c.Server.AuthTokens) == 0 {
+			return fmt.Errorf("server.auth_tokens must have at least one token for the http transport")
+		}
+		names := make(map[string]bool)
+		for i, token := range c.Server.AuthTokens {
+			if token.Name == "" {
+				return fmt.Errorf("server.auth_tokens[%d] needs a name", i)
+			}
+			if names[token.Name] {
+				return fmt.Errorf("server.auth_tokens[%d]: duplicate name %q", i, token.Name)
+			}
+			names[token.Name] = true
+			if len(strings.TrimSpace(token.Token)) < 16 {
+				return fmt.Errorf("server.auth_tokens[%d] (%s) is too short (use at least 16 characters)", i, token.Name)
+			}
+		}
+		if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+			return fmt.Errorf("server.tls_cert_file and server.tls_key_file must be set together")
+		}
+		if c.Server.SessionIdleTimeoutSeconds <= 0 {
+			return fmt.Errorf("server.session_idle_timeout_seconds must be positive, got %d", c.Server.SessionIdleTimeoutSeconds)
+		}
+	default:
+		return fmt.Errorf("server.transport must be \"stdio\" or \"http\", got %q", c.Server.Transport)
+	}
+	return nil
+}
+
+// validateRules checks security.rules: each needs a unique name, a known action and valid patterns.
+func validateRules(rules []PolicyRule) error {
+	names := make(map[string]bool, len(rules))
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return fmt.Errorf("security.rules[%d]: name is required", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("security.rules[%d]: duplicate name %q", i, rule.Name)
+		}
+		names[rule.Name] = true
+		switch rule.Action {
+		case "allow", "confirm", "deny":
+		default:
+			return fmt.Errorf("security.rules[%d] (%s): action must be \"allow\", \"confirm\" or \"deny\", got %q", i, rule.Name, rule.Action)
+		}
+		for _, patterns := range [][]string{rule.Objects, rule.Schemas, rule.Connections} {
+			for _, p := range patterns {
+				if _, err := path.Match(p, ""); err != nil {
+					return fmt.Errorf("security.rules[%d] (%s): invalid pattern %q", i, rule.Name, p)
+				}
+			}
+		}
+	}
 	return nil
 }
 
-// OracleConnections returns the configured connection map (name -> DSN).
+// OracleConnections returns the configured connection map (name -> DSN, see ConnectionConfig.DataSource).
 func (c *Config) OracleConnections() map[string]string {
-	return c.Oracle.Connections
+	if c.Oracle.Connections == nil {
+		return nil
+	}
+	out := make(map[string]string, len(c.Oracle.Connections))
+	for name, conn := range c.Oracle.Connections {
+		out[name] = conn.DataSource()
+	}
+	return out
+}
+
+// ReadOnlyConnections returns the names of the connections with read_only set.
+func (c *Config) ReadOnlyConnections() []string {
+	var names []string
+	for name, conn := range c.Oracle.Connections {
+		if conn.ReadOnly {
+			names = append(names, name)
+		}
+	}
+	return names
 }
 
 // findConfigPath searches for the configuration file in standard locations.
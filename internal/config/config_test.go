@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes content as config.yaml in a new directory and returns its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFromFile_Interpolation(t *testing.T) {
+	t.Setenv("DB_HOST", "db.example.com")
+	t.Setenv("DB_PORT", "1522")
+	t.Setenv("PROD_DSN", "scott/tiger@//prod:1521/ORCL")
+	t.Setenv("NULL_PASSWORD", "null")
+	path := writeConfig(t, `
+oracle:
+  connections:
+    prod: ${PROD_DSN}
+    app:
+      user: app
+      password: file:secrets/app_password
+      host: ${DB_HOST}
+      port: !!int ${DB_PORT}
+      service: "ORCL${DB_PORT}"
+    adb:
+      user: admin
+      password: 'pa"ss\word'
+      service: mydb_high
+      wallet_path: /opt/wallet
+    literal:
+      user: a$${NOT_A_VARIABLE}
+      password: ${NULL_PASSWORD}
+      service: \file:orcl
+`)
+	os.Mkdir(filepath.Join(filepath.Dir(path), "secrets"), 0700)
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), "secrets", "app_password"), []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile: %v", err)
+	}
+	got := cfg.OracleConnections()
+	want := map[string]string{
+		"prod": "scott/tiger@//prod:1521/ORCL",
+		"app":  `user="app" password="s3cret" connectString="db.example.com:1522/ORCL1522"`,
+		"adb":  `user="admin" password="pa\"ss\\word" connectString="mydb_high" configDir="/opt/wallet"`,
+		// escapes, and a substituted value that stays a string
+		"literal": `user="a${NOT_A_VARIABLE}" password="null" connectString="file:orcl"`,
+	}
+	for name, dsn := range want {
+		if got[name] != dsn {
+			t.Errorf("connection %s = %q, want %q", name, got[name], dsn)
+		}
+	}
+}
+
+func TestLoadFromFile_ErrorsDoNotEchoPasswords(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "tiger")
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unset variable", "oracle:\n  connections:\n    db: ${NO_SUCH_VARIABLE_FOR_TEST}\n", "environment variable NO_SUCH_VARIABLE_FOR_TEST is not set"},
+		{"missing file", "oracle:\n  connections:\n    db: file:no_such_file\n", "cannot read secret file"},
+		{"dsn and parts", "oracle:\n  connections:\n    db:\n      dsn: scott/tiger@db\n      password: tiger\n", "not both"},
+		{"no service", "oracle:\n  connections:\n    db:\n      user: scott\n      password: ${DB_PASSWORD}\n", "service is required"},
+		{"no password", "oracle:\n  connections:\n    db:\n      user: scott\n      service: orcl\n", "password is required"},
+		{"port without host", "oracle:\n  connections:\n    db:\n      user: scott\n      password: tiger\n      service: orcl\n      port: 1521\n", "port is set without host"},
+		{"type error", "oracle:\n  connections: scott/tiger@db\n", "cannot unmarshal"},
+		{"token without name", "oracle:\n  connections:\n    db: x/y@db\nserver:\n  transport: http\n  auth_tokens:\n    - token: tiger-tiger-tiger-tiger\n", "auth_tokens[0] needs a name"},
+		{"short token", "oracle:\n  connections:\n    db: x/y@db\nserver:\n  transport: http\n  auth_tokens:\n    - name: ci\n      token: tiger\n", "auth_tokens[0] (ci) is too short"},
+		{"duplicate token name", "oracle:\n  connections:\n    db: x/y@db\nserver:\n  transport: http\n  auth_tokens:\n    - {name: ci, token: tiger-tiger-tiger-tiger}\n    - {name: ci, token: lion-lion-lion-lion}\n", `duplicate name "ci"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFromFile(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want %q", err, tt.want)
+			}
+			if strings.Contains(err.Error(), "tiger") {
+				t.Errorf("error echoes the password: %v", err)
+			}
+		})
+	}
+}
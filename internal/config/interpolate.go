@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envRef matches a ${NAME} reference to an environment variable in a config value, or the escape $${ for a
+// literal ${.
+var envRef = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// secretFilePrefix marks a config value read from a file, e.g. "file:/run/secrets/db_password".
+const secretFilePrefix = "file:"
+
+// interpolate replaces, in every value under node, each ${NAME} with the environment variable NAME (unset is an
+// error; $${ is a literal ${), then a whole value file:<path> with the contents of the file without its trailing
+// newline; relative paths are relative to dir. A value starting with \file: is the literal file:... with one
+// backslash removed. Mapping keys are left alone.
+func interpolate(node *yaml.Node, dir string) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := interpolate(child, dir); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := interpolate(node.Content[i], dir); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		return interpolateValue(node, dir)
+	}
+	return nil
+}
+
+// interpolateValue resolves the references in one value. Errors name the line and the variable or file, never
+// the value.
+func interpolateValue(node *yaml.Node, dir string) error {
+	var missing string
+	value := envRef.ReplaceAllStringFunc(node.Value, func(ref string) string {
+		if ref == "$${" {
+			return "${"
+		}
+		name := envRef.FindStringSubmatch(ref)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok && missing == "" {
+			missing = name
+		}
+		return v
+	})
+	if missing != "" {
+		return fmt.Errorf("line %d: environment variable %s is not set", node.Line, missing)
+	}
+	if rest := strings.TrimLeft(value, `\`); rest != value && strings.HasPrefix(rest, secretFilePrefix) {
+		value = value[1:]
+	} else if path, ok := strings.CutPrefix(value, secretFilePrefix); ok {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("line %d: cannot read secret file: %w", node.Line, err)
+		}
+		value = strings.TrimRight(string(data), "\r\n")
+	}
+	// The tag is kept: a substituted value stays a string (a password "null" or "123" is not retyped) unless the
+	// file tags it, e.g. port: !!int ${DB_PORT}
+	node.Value = value
+	return nil
+}
+
+// quotedValue matches a value quoted in a yaml type error, e.g. `scott/t...`.
+var quotedValue = regexp.MustCompile("`[^`]*`")
+
+// maskValues removes the values yaml quotes in type errors, which may be passwords or DSNs; the line stays.
+func maskValues(err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+	msgs := make([]string, len(typeErr.Errors))
+	for i, msg := range typeErr.Errors {
+		msgs[i] = quotedValue.ReplaceAllString(msg, "***")
+	}
+	return &yaml.TypeError{Errors: msgs}
+}
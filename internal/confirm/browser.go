@@ -0,0 +1,231 @@
+package confirm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBrowserTimeout is how long the browser review page waits for Execute/Cancel before rejecting.
+const DefaultBrowserTimeout = 5 * time.Minute
+
+// BrowserConfirmer shows the review in the default web browser (security.confirm_mode: "browser").
+// Each Confirm call serves the syntax-highlighted review page from an ephemeral 127.0.0.1 listener under a
+// one-time random token, opens it, and waits for Execute or Cancel. No answer within Timeout counts as rejection.
+type BrowserConfirmer struct {
+	Timeout time.Duration
+
+	// open launches the review URL; defaults to the OS browser. Tests replace it to drive the page over HTTP.
+	open func(url string) error
+}
+
+// NewBrowserConfirmer creates a BrowserConfirmer; timeout <= 0 uses DefaultBrowserTimeout.
+func NewBrowserConfirmer(timeout time.Duration) *BrowserConfirmer {
+	if timeout <= 0 {
+		timeout = DefaultBrowserTimeout
+	}
+	return &BrowserConfirmer{Timeout: timeout, open: openBrowser}
+}
+
+// Confirm serves the review page and returns true if the user clicks Execute before the timeout.
+func (c *BrowserConfirmer) Confirm(ctx context.Context, req *ConfirmRequest) (bool, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return false, fmt.Errorf("confirm: cannot listen for review page: %w", err)
+	}
+	token, err := randomToken()
+	if err != nil {
+		ln.Close()
+		return false, fmt.Errorf("confirm: cannot create review token: %w", err)
+	}
+
+	review := &reviewPage{
+		req:      req,
+		host:     ln.Addr().String(),
+		base:     "/review/" + token,
+		deadline: time.Now().Add(c.Timeout),
+		decision: make(chan bool, 1),
+	}
+	srv := &http.Server{Handler: review, ReadHeaderTimeout: 10 * time.Second}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	url := "http://" + review.host + review.base
+	open := c.open
+	if open == nil {
+		open = openBrowser
+	}
+	if err := open(url); err != nil {
+		// Headless or no default browser: the user can still open the URL by hand (e.g. over an SSH tunnel).
+		fmt.Fprintf(os.Stderr, "oracle-mcp: cannot open browser (%v); review the SQL at %s\n", err, url)
+	}
+
+	timer := time.NewTimer(c.Timeout)
+	defer timer.Stop()
+	select {
+	case approved := <-review.decision:
+		return approved, nil
+	case <-timer.C:
+		review.close()
+		fmt.Fprintf(os.Stderr, "oracle-mcp: review not answered within %s; treated as Cancel\n", c.Timeout)
+		return false, nil
+	case <-ctx.Done():
+		review.close()
+		return false, ctx.Err()
+	}
+}
+
+// reviewPage serves one review: the page, the highlighted SQL document, and the one-shot decision endpoint.
+type reviewPage struct {
+	req      *ConfirmRequest
+	host     string // 127.0.0.1:port; other Host headers are refused (DNS rebinding)
+	base     string // /review/<token>
+	deadline time.Time
+	decision chan bool
+
+	mu   sync.Mutex
+	done bool
+}
+
+// close marks the token as used so later requests get 410 Gone.
+func (p *reviewPage) close() {
+	p.mu.Lock()
+	p.done = true
+	p.mu.Unlock()
+}
+
+func (p *reviewPage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Host != p.host {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, p.base) {
+		http.NotFound(w, r)
+		return
+	}
+	p.mu.Lock()
+	done := p.done
+	p.mu.Unlock()
+	if done {
+		http.Error(w, "This review has already been answered or has expired.", http.StatusGone)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "SAMEORIGIN")
+	// ?reveal=1 shows the SQL and binds with masked values, page and SQL document alike
+	req := p.req
+	if r.URL.Query().Get("reveal") == "1" {
+		req = req.Revealed()
+	}
+
+	switch r.URL.Path {
+	case p.base:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, p.renderPage(req))
+	case p.base + "/sql":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, highlightMatchedKeywordsInHTML(sqlHighlightHTML(req.SQL), highlightTermsForReview(req)))
+	case p.base + "/decision":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		action := r.FormValue("action")
+		if action != "execute" && action != "cancel" {
+			http.Error(w, "unknown action", http.StatusBadRequest)
+			return
+		}
+		p.mu.Lock()
+		if p.done {
+			p.mu.Unlock()
+			http.Error(w, "This review has already been answered or has expired.", http.StatusGone)
+			return
+		}
+		p.done = true
+		p.mu.Unlock()
+		p.decision <- action == "execute"
+
+		msg := "Cancelled. The SQL will not be executed."
+		if action == "execute" {
+			msg = "Approved. The SQL is being executed."
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, `<!DOCTYPE html><html><head><meta charset="UTF-8"><title>Confirm SQL</title></head>`+
+			`<body style="font-family:sans-serif;padding:24px"><p>%s</p><p>You can close this tab.</p></body></html>`, html.EscapeString(msg))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// renderPage returns the review page of req: header bar in the connection's color, highlighted SQL, Execute/Cancel,
+// and a Reveal link when values are masked. Neither button has autofocus, so typing in the page cannot approve by accident.
+func (p *reviewPage) renderPage(req *ConfirmRequest) string {
+	header := strings.ReplaceAll(html.EscapeString(buildConfirmHeader(req)), "\n", "<br>")
+	title := html.EscapeString("Confirm SQL — " + connectionLabel(req))
+	sqlURL := p.base + "/sql"
+	var warning string
+	if req.IsDDL {
+		warning = `<p class="warn">WARNING: Oracle DDL is auto-committed and cannot be rolled back!</p>`
+	}
+	if req.Masked() {
+		warning += fmt.Sprintf(`<p class="note">%s <a href="%s?reveal=1">Reveal</a></p>`, html.EscapeString(maskedNote), p.base)
+	} else if req != p.req {
+		sqlURL += "?reveal=1"
+	}
+	return fmt.Sprintf(`<!DOCTYPE html><html><head><meta charset="UTF-8"><title>%s</title><style>
+body { margin: 0; font-family: sans-serif; display: flex; flex-direction: column; height: 100vh; }
+.header { background: #%s; padding: 10px 14px; font-weight: bold; }
+iframe { flex: 1; border: 0; border-bottom: 1px solid #ddd; }
+.bar { display: flex; align-items: center; gap: 10px; padding: 10px 14px; }
+.bar .expires { flex: 1; color: #57606a; font-size: 10pt; }
+.warn { margin: 6px 14px; color: #cf2222; font-weight: bold; }
+.note { margin: 6px 14px; color: #57606a; }
+button { min-width: 90px; padding: 6px 12px; }
+</style></head><body>
+<div class="header">%s</div>
+<iframe src="%s" title="SQL"></iframe>
+%s<form class="bar" method="post" action="%s/decision">
+<span class="expires">This review expires at %s; no answer counts as Cancel.</span>
+<button type="submit" name="action" value="execute">Execute</button>
+<button type="submit" name="action" value="cancel">Cancel</button>
+</form>
+</body></html>`,
+		title, headerBarColor(req.ConnectionIndex), header, sqlURL, warning, p.base, p.deadline.Format("15:04:05"))
+}
+
+// randomToken returns 32 random bytes, hex-encoded, for the one-time review URL.
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// openBrowser opens url in the user's default browser.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	case "darwin":
+		cmd = exec.Command("open", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
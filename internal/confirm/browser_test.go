@@ -0,0 +1,154 @@
+package confirm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// browserAnswering returns a BrowserConfirmer whose "browser" fetches the review page and posts action
+// (empty action: only load the page and never answer). Loaded page bodies are sent to pages.
+// Failures simply leave the review unanswered, which the tests observe as a rejection or timeout.
+func browserAnswering(action string, timeout time.Duration, pages chan<- string) *BrowserConfirmer {
+	c := NewBrowserConfirmer(timeout)
+	c.open = func(reviewURL string) error {
+		go func() {
+			resp, err := http.Get(reviewURL)
+			if err != nil {
+				return
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if pages != nil {
+				pages <- string(body)
+			}
+			if action == "" {
+				return
+			}
+			if resp, err := http.PostForm(reviewURL+"/decision", url.Values{"action": {action}}); err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+	return c
+}
+
+func TestBrowserConfirmer_Execute(t *testing.T) {
+	pages := make(chan string, 1)
+	c := browserAnswering("execute", 10*time.Second, pages)
+	approved, err := c.Confirm(context.Background(), &ConfirmRequest{
+		SQL:             "DROP TABLE t",
+		MatchedKeywords: []string{"drop"},
+		StatementType:   "DROP",
+		IsDDL:           true,
+		Connection:      "db2",
+		ConnectionIndex: 1,
+	})
+	if err != nil || !approved {
+		t.Fatalf("Confirm = %v, %v; want true, nil", approved, err)
+	}
+	page := <-pages
+	for _, want := range []string{"Database: db2", "Keywords: drop", "#90CAF9", "WARNING: Oracle DDL", `value="execute"`} {
+		if !strings.Contains(page, want) {
+			t.Errorf("review page missing %q", want)
+		}
+	}
+}
+
+func TestBrowserConfirmer_Cancel(t *testing.T) {
+	c := browserAnswering("cancel", 10*time.Second, nil)
+	approved, err := c.Confirm(context.Background(), &ConfirmRequest{SQL: "DELETE FROM t", StatementType: "DELETE"})
+	if err != nil || approved {
+		t.Fatalf("Confirm = %v, %v; want false, nil", approved, err)
+	}
+}
+
+func TestBrowserConfirmer_TimeoutRejects(t *testing.T) {
+	c := browserAnswering("", 200*time.Millisecond, nil)
+	start := time.Now()
+	approved, err := c.Confirm(context.Background(), &ConfirmRequest{SQL: "DELETE FROM t", StatementType: "DELETE"})
+	if err != nil || approved {
+		t.Fatalf("Confirm = %v, %v; want false, nil", approved, err)
+	}
+	if time.Since(start) < 200*time.Millisecond {
+		t.Error("Confirm returned before the timeout")
+	}
+}
+
+func TestBrowserConfirmer_ContextEndsReview(t *testing.T) {
+	pages := make(chan string, 1)
+	c := browserAnswering("", 10*time.Second, pages)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-pages
+		cancel()
+	}()
+	approved, err := c.Confirm(ctx, &ConfirmRequest{SQL: "DELETE FROM t", StatementType: "DELETE"})
+	if !errors.Is(err, context.Canceled) || approved {
+		t.Fatalf("Confirm = %v, %v; want false, context.Canceled", approved, err)
+	}
+}
+
+func TestReviewPage_RejectsForeignHost(t *testing.T) {
+	p := &reviewPage{req: &ConfirmRequest{SQL: "SELECT 1 FROM dual"}, host: "127.0.0.1:1234", base: "/review/x", decision: make(chan bool, 1)}
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://evil.example/review/x", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestReviewPage_OneTimeDecision(t *testing.T) {
+	p := &reviewPage{req: &ConfirmRequest{SQL: "DELETE FROM t"}, host: "127.0.0.1:1234", base: "/review/x", decision: make(chan bool, 1)}
+	post := func(action string) int {
+		r := httptest.NewRequest(http.MethodPost, "http://127.0.0.1:1234/review/x/decision", strings.NewReader("action="+action))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, r)
+		return w.Code
+	}
+	if code := post("cancel"); code != http.StatusOK {
+		t.Fatalf("first decision status = %d, want 200", code)
+	}
+	if approved := <-p.decision; approved {
+		t.Error("cancel delivered as approval")
+	}
+	if code := post("execute"); code != http.StatusGone {
+		t.Errorf("second decision status = %d, want %d", code, http.StatusGone)
+	}
+}
+
+func TestReviewPage_Reveal(t *testing.T) {
+	p := &reviewPage{req: &ConfirmRequest{
+		SQL: "ALTER USER app IDENTIFIED BY ***", RevealSQL: "ALTER USER app IDENTIFIED BY s3cret",
+		Binds: []string{":pwd = '***' (string)"}, RevealBinds: []string{":pwd = 'hunter2' (string)"},
+	}, host: "127.0.0.1:1234", base: "/review/x", decision: make(chan bool, 1)}
+	get := func(path string) string {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://127.0.0.1:1234"+path, nil))
+		return w.Body.String()
+	}
+
+	page, sql := get("/review/x"), get("/review/x/sql")
+	if strings.Contains(page+sql, "s3cret") || strings.Contains(page, "hunter2") {
+		t.Error("masked review shows a secret")
+	}
+	if !strings.Contains(page, `href="/review/x?reveal=1"`) || !strings.Contains(page, `src="/review/x/sql"`) {
+		t.Errorf("masked review page lacks the Reveal link:\n%s", page)
+	}
+
+	page, sql = get("/review/x?reveal=1"), get("/review/x/sql?reveal=1")
+	if !strings.Contains(sql, "s3cret") || !strings.Contains(page, "hunter2") || !strings.Contains(page, `src="/review/x/sql?reveal=1"`) {
+		t.Errorf("revealed review does not show the SQL as it runs:\n%s", page)
+	}
+	if strings.Contains(page, "Reveal</a>") {
+		t.Error("revealed review still offers Reveal")
+	}
+}
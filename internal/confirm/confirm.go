@@ -0,0 +1,277 @@
+// Package confirm provides Human-in-the-loop confirmation dialogs.
+// Platform-specific backends live in confirm_<goos>.go; this file holds the request type and helpers they share.
+package confirm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Confirmer asks a human to approve or reject a SQL execution.
+// Confirm returns (true, nil) on approve, (false, nil) on reject, and an error when no answer could be obtained.
+// When ctx ends first (the client cancelled the call or its session closed), the review is closed and ctx's error
+// is returned. The server receives one in mcp.NewServer; DialogConfirmer is the desktop implementation and
+// Scripted is for tests.
+type Confirmer interface {
+	Confirm(ctx context.Context, req *ConfirmRequest) (bool, error)
+}
+
+// ConfirmRequest contains the data for a confirmation dialog.
+type ConfirmRequest struct {
+	SQL             string
+	MatchedKeywords []string
+	// MatchedKeywordsForHighlight, if non-empty, limits red keyword markup to these terms (Java: hits on formatted text only).
+	MatchedKeywordsForHighlight []string
+	MatchedActions              []string // command_match statement types (Java parity); merged into red highlight when set
+	StatementType               string
+	IsDDL                       bool
+	Connection                  string // Database alias from config (e.g. "database1", "database2") for title/display
+	// ConnectionIndex is the 0-based index in the configured connections list; selects header bar color (same palette as Java).
+	ConnectionIndex int
+	SourceLabel     string   // Optional, e.g. "File: path/to/file.sql" for execute_sql_file
+	Binds           []string // Bind variables for display, e.g. ":id = 42 (number)"; see oracle.FormatBinds
+	Transaction     string   // Non-empty when a transaction is open on the connection, e.g. "open since 10:42:01"
+	Rule            string   // Name of the security.rules entry that asked for review; "" for danger_keywords/DDL
+	// AffectedRows describes the rows the UPDATE/DELETE/MERGE statements will change, e.g.
+	// "about 12,345 (DELETE on line 1: no WHERE clause)"; counted before review. Empty when the SQL has none.
+	AffectedRows string
+	// Warning is shown first, e.g. for the second confirmation of a change above the row threshold.
+	Warning string
+	// RevealSQL and RevealBinds are SQL and Binds before secrets were masked (security.redact); RevealSQL is empty
+	// when nothing was masked. Backends show the masked SQL and offer a Reveal action that shows Revealed.
+	RevealSQL   string
+	RevealBinds []string
+}
+
+// maskedNote is shown with a review whose SQL has masked values.
+const maskedNote = "Secrets and personal data are masked as ***; the SQL runs unchanged."
+
+// Masked reports whether the review has values masked that Reveal can show.
+func (req *ConfirmRequest) Masked() bool {
+	return req.RevealSQL != ""
+}
+
+// Revealed returns a copy of req that shows the SQL and binds as they will run.
+func (req *ConfirmRequest) Revealed() *ConfirmRequest {
+	if !req.Masked() {
+		return req
+	}
+	out := *req
+	out.SQL, out.Binds = req.RevealSQL, req.RevealBinds
+	out.RevealSQL, out.RevealBinds = "", nil
+	return &out
+}
+
+func buildConfirmHeader(req *ConfirmRequest) string {
+	var line1 []string
+	if req.Connection != "" {
+		line1 = append(line1, "Database: "+req.Connection)
+	}
+	if len(req.MatchedActions) > 0 {
+		line1 = append(line1, "Action: "+strings.Join(req.MatchedActions, ", "))
+	} else if req.StatementType != "" {
+		line1 = append(line1, "Action: "+req.StatementType)
+	}
+	if len(req.MatchedKeywords) > 0 {
+		line1 = append(line1, "Keywords: "+strings.Join(req.MatchedKeywords, ", "))
+	}
+	if req.Rule != "" {
+		line1 = append(line1, "Rule: "+req.Rule)
+	}
+	if req.IsDDL {
+		line1 = append(line1, "DDL (auto-committed)")
+	}
+	if req.Transaction != "" {
+		line1 = append(line1, "Transaction: "+req.Transaction)
+	}
+	if req.AffectedRows != "" {
+		line1 = append(line1, "Affected rows: "+req.AffectedRows)
+	}
+	var out string
+	if req.Warning != "" {
+		out = "WARNING: " + req.Warning
+	}
+	if len(line1) > 0 {
+		if out != "" {
+			out += "\n"
+		}
+		out += strings.Join(line1, "    |    ")
+	}
+	if req.SourceLabel != "" {
+		if out != "" {
+			out += "\n"
+		}
+		out += req.SourceLabel // "File: path" on its own second line
+	}
+	if len(req.Binds) > 0 {
+		if out != "" {
+			out += "\n"
+		}
+		out += "Binds: " + strings.Join(req.Binds, ", ")
+	}
+	if out == "" {
+		return "Confirm SQL execution"
+	}
+	return out
+}
+
+// connectionLabel returns the connection alias for titles, or "default".
+func connectionLabel(req *ConfirmRequest) string {
+	if req.Connection == "" {
+		return "default"
+	}
+	return req.Connection
+}
+
+// Same palette as Java Confirmer.HEADER_COLORS (connection index mod length).
+var headerBarColors = []string{
+	"A5D6A7", "90CAF9", "FFCC80", "CE93D8", "F48FB1",
+	"80DEEA", "EF9A9A", "80CBC4", "FFF59D", "BCAAA4",
+}
+
+func headerBarColor(connectionIndex int) string {
+	if connectionIndex < 0 {
+		connectionIndex = 0
+	}
+	return headerBarColors[connectionIndex%len(headerBarColors)]
+}
+
+// highlightTermsForReview merges keyword and action strings for red markup (aligned with Java Confirmer).
+func highlightTermsForReview(req *ConfirmRequest) []string {
+	seen := make(map[string]struct{})
+	var out []string
+	add := func(s string) {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return
+		}
+		key := strings.ToLower(s)
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		out = append(out, s)
+	}
+	kwSrc := req.MatchedKeywords
+	if len(req.MatchedKeywordsForHighlight) > 0 {
+		kwSrc = req.MatchedKeywordsForHighlight
+	}
+	for _, k := range kwSrc {
+		add(k)
+	}
+	for _, a := range req.MatchedActions {
+		add(a)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	sort.Slice(out, func(i, j int) bool { return len(out[i]) > len(out[j]) })
+	return out
+}
+
+// ReviewMessage returns the plain-text review shown by text-only backends (macOS dialog, MCP elicitation): full SQL, no truncation.
+func ReviewMessage(req *ConfirmRequest) string {
+	var sb strings.Builder
+
+	if req.Warning != "" {
+		sb.WriteString("WARNING: ")
+		sb.WriteString(req.Warning)
+		sb.WriteString("\n\n")
+	}
+
+	if req.Connection != "" {
+		sb.WriteString("Database: ")
+		sb.WriteString(req.Connection)
+		sb.WriteString("\n\n")
+	}
+
+	// Keywords section
+	if len(req.MatchedKeywords) > 0 {
+		sb.WriteString("Matched Keywords: ")
+		sb.WriteString(strings.ToUpper(strings.Join(req.MatchedKeywords, ", ")))
+		sb.WriteString("\n\n")
+	}
+
+	if req.Rule != "" {
+		sb.WriteString("Policy Rule: ")
+		sb.WriteString(req.Rule)
+		sb.WriteString("\n\n")
+	}
+
+	// Statement type
+	sb.WriteString("Statement Type: ")
+	sb.WriteString(req.StatementType)
+	sb.WriteString("\n\n")
+
+	if req.Transaction != "" {
+		sb.WriteString("Transaction: ")
+		sb.WriteString(req.Transaction)
+		sb.WriteString("\n\n")
+	}
+
+	if req.AffectedRows != "" {
+		sb.WriteString("Affected Rows: ")
+		sb.WriteString(req.AffectedRows)
+		sb.WriteString("\n\n")
+	}
+
+	// SQL section: full SQL, no truncation
+	sb.WriteString("SQL:\n")
+	sb.WriteString(req.SQL)
+	sb.WriteString("\n\n")
+	if req.Masked() {
+		sb.WriteString(maskedNote)
+		sb.WriteString("\n\n")
+	}
+
+	if len(req.Binds) > 0 {
+		sb.WriteString("Binds:\n")
+		for _, b := range req.Binds {
+			sb.WriteString("  ")
+			sb.WriteString(b)
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	// Warning for DDL
+	if req.IsDDL {
+		sb.WriteString("WARNING: Oracle DDL is auto-committed and cannot be rolled back!\n\n")
+	}
+
+	if req.SourceLabel != "" {
+		sb.WriteString(req.SourceLabel)
+		sb.WriteString("\n\n")
+	}
+
+	sb.WriteString("Do you want to continue?")
+
+	return sb.String()
+}
+
+// FormatConfirmationMessage formats the confirmation message for logging.
+func FormatConfirmationMessage(req *ConfirmRequest) string {
+	conn := req.Connection
+	if conn == "" {
+		conn = "default"
+	}
+	return fmt.Sprintf(
+		"Connection=[%s] SQL=[%s] Keywords=[%s] Type=[%s] IsDDL=[%v]",
+		conn,
+		truncateSQL(req.SQL, 100),
+		strings.Join(req.MatchedKeywords, ","),
+		req.StatementType,
+		req.IsDDL,
+	)
+}
+
+func truncateSQL(sql string, maxLen int) string {
+	sql = strings.ReplaceAll(sql, "\n", " ")
+	sql = strings.ReplaceAll(sql, "\r", "")
+	if len(sql) > maxLen {
+		return sql[:maxLen] + "..."
+	}
+	return sql
+}
@@ -4,48 +4,45 @@
 package confirm
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"strings"
 )
 
-// ConfirmRequest contains the data for a confirmation dialog.
-type ConfirmRequest struct {
-	SQL                         string
-	MatchedKeywords             []string
-	MatchedKeywordsForHighlight []string
-	MatchedActions              []string
-	StatementType               string
-	IsDDL                       bool
-	Connection                  string
-	ConnectionIndex             int
-	SourceLabel                 string
-}
-
-// Confirmer handles user confirmation dialogs on macOS.
-type Confirmer struct{}
+// DialogConfirmer handles user confirmation dialogs on macOS.
+type DialogConfirmer struct{}
 
-// NewConfirmer creates a new Confirmer instance.
-func NewConfirmer() *Confirmer {
-	return &Confirmer{}
+// NewDialogConfirmer creates a new DialogConfirmer instance.
+func NewDialogConfirmer() *DialogConfirmer {
+	return &DialogConfirmer{}
 }
 
 // Confirm shows a confirmation dialog using osascript and returns true if the user approves.
-func (c *Confirmer) Confirm(req *ConfirmRequest) (bool, error) {
+// When values are masked, a Reveal button shows the dialog again with the SQL as it runs.
+func (c *DialogConfirmer) Confirm(ctx context.Context, req *ConfirmRequest) (bool, error) {
 	title := "Dangerous SQL Detected"
 	if req.Connection != "" {
 		title = "Confirm SQL — " + req.Connection
 	}
-	message := buildConfirmMessage(req)
+	message := ReviewMessage(req)
+	buttons := `{"Cancel", "Execute"}`
+	if req.Masked() {
+		buttons = `{"Cancel", "Reveal", "Execute"}`
+	}
 
 	// Use osascript to display a dialog
 	script := fmt.Sprintf(`
-		display dialog %q with title %q buttons {"Cancel", "Execute"} default button "Cancel" with icon caution
-	`, message, title)
+		display dialog %q with title %q buttons %s default button "Cancel" with icon caution
+	`, message, title, buttons)
 
-	cmd := exec.Command("osascript", "-e", script)
+	// The dialog is killed when ctx ends
+	cmd := exec.CommandContext(ctx, "osascript", "-e", script)
 	output, err := cmd.Output()
 
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
 	if err != nil {
 		// osascript returns error when user clicks Cancel
 		if exitErr, ok := err.(*exec.ExitError); ok {
@@ -56,54 +53,15 @@ func (c *Confirmer) Confirm(req *ConfirmRequest) (bool, error) {
 		return false, fmt.Errorf("dialog error: %w", err)
 	}
 
+	if strings.Contains(string(output), "Reveal") {
+		return c.Confirm(ctx, req.Revealed())
+	}
 	// Check if user clicked Execute
 	return strings.Contains(string(output), "Execute"), nil
 }
 
-// buildConfirmMessage constructs the message to display in the dialog (full SQL, no truncation).
-func buildConfirmMessage(req *ConfirmRequest) string {
-	var sb strings.Builder
-
-	if req.Connection != "" {
-		sb.WriteString("Database: ")
-		sb.WriteString(req.Connection)
-		sb.WriteString("\n\n")
-	}
-
-	// Keywords section
-	if len(req.MatchedKeywords) > 0 {
-		sb.WriteString("Matched Keywords: ")
-		sb.WriteString(strings.ToUpper(strings.Join(req.MatchedKeywords, ", ")))
-		sb.WriteString("\n\n")
-	}
-
-	// Statement type
-	sb.WriteString("Statement Type: ")
-	sb.WriteString(req.StatementType)
-	sb.WriteString("\n\n")
-
-	// SQL section: full SQL, no truncation
-	sb.WriteString("SQL:\n")
-	sb.WriteString(req.SQL)
-	sb.WriteString("\n\n")
-
-	// Warning for DDL
-	if req.IsDDL {
-		sb.WriteString("WARNING: Oracle DDL is auto-committed and cannot be rolled back!\n\n")
-	}
-
-	if req.SourceLabel != "" {
-		sb.WriteString(req.SourceLabel)
-		sb.WriteString("\n\n")
-	}
-
-	sb.WriteString("Do you want to continue?")
-
-	return sb.String()
-}
-
 // ShowError displays an error message dialog on macOS.
-func (c *Confirmer) ShowError(title, message string) {
+func (c *DialogConfirmer) ShowError(title, message string) {
 	script := fmt.Sprintf(`
 		display dialog %q with title %q buttons {"OK"} default button "OK" with icon stop
 	`, message, title)
@@ -111,7 +69,7 @@ func (c *Confirmer) ShowError(title, message string) {
 }
 
 // ShowInfo displays an informational message dialog on macOS.
-func (c *Confirmer) ShowInfo(title, message string) {
+func (c *DialogConfirmer) ShowInfo(title, message string) {
 	script := fmt.Sprintf(`
 		display dialog %q with title %q buttons {"OK"} default button "OK" with icon note
 	`, message, title)
@@ -119,36 +77,11 @@ func (c *Confirmer) ShowInfo(title, message string) {
 }
 
 // Available returns true on macOS.
-func (c *Confirmer) Available() bool {
+func (c *DialogConfirmer) Available() bool {
 	return true
 }
 
 // PlatformName returns the platform name.
-func (c *Confirmer) PlatformName() string {
+func (c *DialogConfirmer) PlatformName() string {
 	return "darwin"
 }
-
-// FormatConfirmationMessage formats the confirmation message for logging.
-func FormatConfirmationMessage(req *ConfirmRequest) string {
-	conn := req.Connection
-	if conn == "" {
-		conn = "default"
-	}
-	return fmt.Sprintf(
-		"Connection=[%s] SQL=[%s] Keywords=[%s] Type=[%s] IsDDL=[%v]",
-		conn,
-		truncateSQL(req.SQL, 100),
-		strings.Join(req.MatchedKeywords, ","),
-		req.StatementType,
-		req.IsDDL,
-	)
-}
-
-func truncateSQL(sql string, maxLen int) string {
-	sql = strings.ReplaceAll(sql, "\n", " ")
-	sql = strings.ReplaceAll(sql, "\r", "")
-	if len(sql) > maxLen {
-		return sql[:maxLen] + "..."
-	}
-	return sql
-}
@@ -0,0 +1,324 @@
+//go:build linux
+
+// Package confirm provides Human-in-the-loop confirmation dialogs for Linux.
+package confirm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ttyPath is the controlling terminal used for the text fallback. stdin/stdout carry JSON-RPC, so the prompt never uses them.
+const ttyPath = "/dev/tty"
+
+// DialogConfirmer handles user confirmation dialogs on Linux.
+// With a graphical session (DISPLAY or WAYLAND_DISPLAY) it uses zenity, kdialog or yad, in that order;
+// otherwise, or when none of them is installed, it prompts on the controlling terminal.
+type DialogConfirmer struct{}
+
+// NewDialogConfirmer creates a new DialogConfirmer instance.
+func NewDialogConfirmer() *DialogConfirmer {
+	return &DialogConfirmer{}
+}
+
+// Confirm shows a confirmation dialog with the full SQL and returns true if the user approves.
+// The dialog is closed when ctx ends.
+func (c *DialogConfirmer) Confirm(ctx context.Context, req *ConfirmRequest) (bool, error) {
+	if hasDisplay() {
+		for _, tool := range []string{"zenity", "kdialog", "yad"} {
+			if _, err := exec.LookPath(tool); err != nil {
+				continue
+			}
+			approved, err := runDialogTool(ctx, tool, req)
+			if err == nil || ctx.Err() != nil {
+				return approved, err
+			}
+			// Tool present but could not show a window (e.g. stale DISPLAY); try the next one.
+			fmt.Fprintf(os.Stderr, "oracle-mcp confirm %s: %v\n", tool, err)
+		}
+	}
+	return confirmOnTTY(ctx, req)
+}
+
+// hasDisplay reports whether a graphical session is available to dialog tools.
+func hasDisplay() bool {
+	return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+// runDialogTool shows the review dialog with the given tool. Cancel or closing the window returns (false, nil);
+// any other failure returns an error so the caller can fall back. When values are masked the dialog has a Reveal
+// button, which shows it again with the SQL as it runs.
+func runDialogTool(ctx context.Context, tool string, req *ConfirmRequest) (bool, error) {
+	title := "Confirm SQL — " + connectionLabel(req)
+	var args []string
+	switch tool {
+	case "zenity":
+		args = []string{"--question", "--title", title, "--text", buildPangoMessage(req),
+			"--ok-label", "Execute", "--cancel-label", "Cancel", "--default-cancel",
+			"--icon-name", "dialog-warning", "--width", "1000", "--height", "700"}
+		if req.Masked() {
+			args = append(args, "--extra-button", "Reveal")
+		}
+	case "kdialog":
+		if req.Masked() {
+			args = []string{"--title", title, "--yes-label", "Execute", "--no-label", "Reveal", "--cancel-label", "Cancel",
+				"--warningyesnocancel", buildRichTextMessage(req)}
+		} else {
+			args = []string{"--title", title, "--continue-label", "Execute", "--cancel-label", "Cancel",
+				"--warningcontinuecancel", buildRichTextMessage(req)}
+		}
+	case "yad":
+		args = []string{"--text-info", "--formatted", "--wrap", "--title", title, "--text", buildPangoHeader(req),
+			"--button", "Cancel:1", "--button", "Execute:0", "--width", "1000", "--height", "700", "--center"}
+		if req.Masked() {
+			args = append(args, "--button", "Reveal:3")
+		}
+	default:
+		return false, fmt.Errorf("unsupported dialog tool %q", tool)
+	}
+
+	cmd := exec.CommandContext(ctx, tool, args...)
+	if tool == "yad" {
+		cmd.Stdin = strings.NewReader(buildPangoSQL(req))
+	}
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	if err == nil {
+		return true, nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		code := exitErr.ExitCode()
+		reveal := tool == "zenity" && code == 1 && strings.TrimSpace(stdout.String()) == "Reveal"
+		// zenity and yad also exit with 1 when no window could be opened (e.g. "cannot open display")
+		if code == 1 && !reveal && dialogFailed(stderr.String()) {
+			return false, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		// zenity prints the extra button's label; kdialog's No and yad's button 3 are Reveal
+		if req.Masked() && (reveal || (tool == "kdialog" && code == 1) || (tool == "yad" && code == 3)) {
+			return runDialogTool(ctx, tool, req.Revealed())
+		}
+		switch code {
+		case 1, 2, 252: // Cancel / window closed (zenity, yad use 1 and 252; kdialog uses 2)
+			return false, nil
+		}
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return false, fmt.Errorf("%w: %s", err, msg)
+	}
+	return false, err
+}
+
+// dialogFailed reports whether a dialog tool's stderr says it failed rather than that the user cancelled: any line
+// besides GTK's routine notes ("Gtk-Message: ...", "... mapped without a transient parent ...").
+func dialogFailed(stderr string) bool {
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.Contains(line, "Gtk-Message") && !strings.Contains(line, "transient parent") {
+			return true
+		}
+	}
+	return false
+}
+
+// confirmOnTTY prints the review on the controlling terminal and waits for an explicit "yes". When ctx ends the
+// terminal is closed, which ends the wait.
+func confirmOnTTY(ctx context.Context, req *ConfirmRequest) (bool, error) {
+	tty, err := os.OpenFile(ttyPath, os.O_RDWR, 0)
+	if err != nil {
+		return false, fmt.Errorf("no dialog tool (zenity, kdialog, yad) with a display and no terminal available: %w", err)
+	}
+	defer tty.Close()
+	stop := context.AfterFunc(ctx, func() { tty.Close() })
+	defer stop()
+
+	fmt.Fprint(tty, buildTTYMessage(req))
+	if req.Masked() {
+		fmt.Fprint(tty, "Type 'yes' to execute, 'reveal' to show the masked values, anything else cancels: ")
+	} else {
+		fmt.Fprint(tty, "Type 'yes' to execute, anything else cancels: ")
+	}
+	answer, err := bufio.NewReader(tty).ReadString('\n')
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	if err != nil && answer == "" {
+		return false, fmt.Errorf("read answer from %s: %w", ttyPath, err)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "reveal" && req.Masked() {
+		tty.Close()
+		return confirmOnTTY(ctx, req.Revealed())
+	}
+	return answer == "yes" || answer == "y", nil
+}
+
+// buildPangoHeader returns the review header (Database | Action | Keywords | DDL, then File) on the connection's header colour.
+func buildPangoHeader(req *ConfirmRequest) string {
+	return fmt.Sprintf(`<span background="#%s" foreground="black" weight="bold"> %s </span>`,
+		headerBarColor(req.ConnectionIndex), html.EscapeString(buildConfirmHeader(req)))
+}
+
+// buildPangoSQL returns the full SQL as Pango markup with matched keywords in red bold.
+func buildPangoSQL(req *ConfirmRequest) string {
+	return `<tt>` + markKeywords(req.SQL, highlightTermsForReview(req), html.EscapeString,
+		`<span foreground="red" weight="bold">`, `</span>`) + `</tt>`
+}
+
+// buildPangoMessage combines header, SQL and warning for zenity's Pango-enabled --text.
+func buildPangoMessage(req *ConfirmRequest) string {
+	var sb strings.Builder
+	sb.WriteString(buildPangoHeader(req))
+	sb.WriteString("\n\n")
+	sb.WriteString(buildPangoSQL(req))
+	sb.WriteString("\n\n")
+	if req.IsDDL {
+		sb.WriteString("<b>WARNING: Oracle DDL is auto-committed and cannot be rolled back!</b>\n\n")
+	}
+	if req.Masked() {
+		sb.WriteString(html.EscapeString(maskedNote) + "\n\n")
+	}
+	sb.WriteString("Do you want to continue?")
+	return sb.String()
+}
+
+// buildRichTextMessage returns the same review as Qt rich text for kdialog.
+func buildRichTextMessage(req *ConfirmRequest) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<div style="background-color:#%s;color:black;font-weight:bold;padding:6px">%s</div>`,
+		headerBarColor(req.ConnectionIndex), strings.ReplaceAll(html.EscapeString(buildConfirmHeader(req)), "\n", "<br>")))
+	sb.WriteString(`<pre>`)
+	sb.WriteString(markKeywords(req.SQL, highlightTermsForReview(req), html.EscapeString,
+		`<span style="color:red;font-weight:bold">`, `</span>`))
+	sb.WriteString(`</pre>`)
+	if req.IsDDL {
+		sb.WriteString("<p><b>WARNING: Oracle DDL is auto-committed and cannot be rolled back!</b></p>")
+	}
+	if req.Masked() {
+		sb.WriteString("<p>" + html.EscapeString(maskedNote) + "</p>")
+	}
+	sb.WriteString("<p>Do you want to continue?</p>")
+	return sb.String()
+}
+
+// buildTTYMessage returns the review for a terminal: header on a 24-bit background colour, matched keywords in red bold.
+func buildTTYMessage(req *ConfirmRequest) string {
+	const (
+		reset   = "\x1b[0m"
+		redBold = "\x1b[1;31m"
+	)
+	r, g, b := hexToRGB(headerBarColor(req.ConnectionIndex))
+	headerStyle := fmt.Sprintf("\x1b[1;30;48;2;%d;%d;%dm", r, g, b)
+
+	var sb strings.Builder
+	sb.WriteString("\n")
+	for _, line := range strings.Split(buildConfirmHeader(req), "\n") {
+		sb.WriteString(headerStyle + " " + line + " " + reset + "\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(markKeywords(req.SQL, highlightTermsForReview(req), func(s string) string { return s }, redBold, reset))
+	sb.WriteString("\n\n")
+	if req.IsDDL {
+		sb.WriteString("WARNING: Oracle DDL is auto-committed and cannot be rolled back!\n\n")
+	}
+	if req.Masked() {
+		sb.WriteString(maskedNote + "\n\n")
+	}
+	return sb.String()
+}
+
+// markKeywords escapes text and wraps whole-word, case-insensitive matches of terms in start/end markup.
+func markKeywords(text string, terms []string, escape func(string) string, start, end string) string {
+	if len(terms) == 0 {
+		return escape(text)
+	}
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	// terms are sorted longest first, so "alter system" wins over "alter"
+	re := regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+	var sb strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringIndex(text, -1) {
+		sb.WriteString(escape(text[last:loc[0]]))
+		sb.WriteString(start)
+		sb.WriteString(escape(text[loc[0]:loc[1]]))
+		sb.WriteString(end)
+		last = loc[1]
+	}
+	sb.WriteString(escape(text[last:]))
+	return sb.String()
+}
+
+// hexToRGB parses an RRGGBB colour from headerBarColors.
+func hexToRGB(hex string) (r, g, b int) {
+	v, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil {
+		return 165, 214, 167
+	}
+	return int(v >> 16 & 0xFF), int(v >> 8 & 0xFF), int(v & 0xFF)
+}
+
+// ShowError displays an error message dialog on Linux (terminal output when no dialog tool is usable).
+func (c *DialogConfirmer) ShowError(title, message string) {
+	showMessage("error", title, message)
+}
+
+// ShowInfo displays an informational message dialog on Linux (terminal output when no dialog tool is usable).
+func (c *DialogConfirmer) ShowInfo(title, message string) {
+	showMessage("info", title, message)
+}
+
+func showMessage(kind, title, message string) {
+	if hasDisplay() {
+		if _, err := exec.LookPath("zenity"); err == nil {
+			if exec.Command("zenity", "--"+kind, "--title", title, "--text", message, "--no-markup").Run() == nil {
+				return
+			}
+		}
+		if _, err := exec.LookPath("kdialog"); err == nil {
+			flag := "--msgbox"
+			if kind == "error" {
+				flag = "--error"
+			}
+			if exec.Command("kdialog", "--title", title, flag, message).Run() == nil {
+				return
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr, "oracle-mcp %s: %s: %s\n", kind, title, message)
+}
+
+// Available returns true when a dialog tool with a display or a controlling terminal can be used.
+func (c *DialogConfirmer) Available() bool {
+	if hasDisplay() {
+		for _, tool := range []string{"zenity", "kdialog", "yad"} {
+			if _, err := exec.LookPath(tool); err == nil {
+				return true
+			}
+		}
+	}
+	tty, err := os.OpenFile(ttyPath, os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	tty.Close()
+	return true
+}
+
+// PlatformName returns the platform name.
+func (c *DialogConfirmer) PlatformName() string {
+	return "linux"
+}
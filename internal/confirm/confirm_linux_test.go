@@ -0,0 +1,81 @@
+//go:build linux
+
+package confirm
+
+import (
+	"html"
+	"strings"
+	"testing"
+)
+
+func TestMarkKeywords(t *testing.T) {
+	req := &ConfirmRequest{SQL: `ALTER SYSTEM KILL SESSION '1,2'; alter table a<b`, MatchedKeywords: []string{"alter", "alter system"}}
+	got := markKeywords(req.SQL, highlightTermsForReview(req), html.EscapeString, "[", "]")
+	// The longer term wins where both match, and the text between matches is escaped
+	want := `[ALTER SYSTEM] KILL SESSION &#39;1,2&#39;; [alter] table a&lt;b`
+	if got != want {
+		t.Errorf("markKeywords = %q, want %q", got, want)
+	}
+	if got := markKeywords("salter altered", []string{"alter"}, html.EscapeString, "[", "]"); got != "salter altered" {
+		t.Errorf("markKeywords matched inside words: %q", got)
+	}
+	if got := markKeywords("a & b", nil, html.EscapeString, "[", "]"); got != "a &amp; b" {
+		t.Errorf("markKeywords without terms = %q", got)
+	}
+}
+
+func TestDialogMessages_EscapeSQL(t *testing.T) {
+	req := &ConfirmRequest{
+		SQL:             `DELETE FROM t WHERE a < 1 & b = "x" AND c = 'y'`,
+		MatchedKeywords: []string{"delete"},
+		Connection:      "db<1>",
+	}
+	for name, msg := range map[string]string{"pango": buildPangoMessage(req), "rich text": buildRichTextMessage(req)} {
+		for _, raw := range []string{"a < 1", "& b", `"x"`, "'y'", "db<1>"} {
+			if strings.Contains(msg, raw) {
+				t.Errorf("%s message contains unescaped %q:\n%s", name, raw, msg)
+			}
+		}
+		for _, escaped := range []string{"a &lt; 1", "&amp; b", "&#34;x&#34;", "&#39;y&#39;", "db&lt;1&gt;"} {
+			if !strings.Contains(msg, escaped) {
+				t.Errorf("%s message missing %q:\n%s", name, escaped, msg)
+			}
+		}
+		if !strings.Contains(msg, "DELETE</span>") {
+			t.Errorf("%s message does not mark the keyword:\n%s", name, msg)
+		}
+	}
+}
+
+func TestHexToRGB(t *testing.T) {
+	tests := []struct {
+		hex     string
+		r, g, b int
+	}{
+		{"FF8000", 255, 128, 0},
+		{"#0A0B0C", 10, 11, 12},
+		{"not a colour", 165, 214, 167},
+	}
+	for _, tt := range tests {
+		if r, g, b := hexToRGB(tt.hex); r != tt.r || g != tt.g || b != tt.b {
+			t.Errorf("hexToRGB(%q) = %d, %d, %d, want %d, %d, %d", tt.hex, r, g, b, tt.r, tt.g, tt.b)
+		}
+	}
+}
+
+func TestDialogFailed(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   bool
+	}{
+		{"", false},
+		{"Gtk-Message: 10:42:01.123: GtkDialog mapped without a transient parent. This is discouraged.\n", false},
+		{"(zenity:4242): Gtk-WARNING **: 10:42:01.123: cannot open display: :1\n", true},
+		{"Gtk-Message: 10:42:01.123: GtkDialog mapped without a transient parent.\nyad: cannot open display\n", true},
+	}
+	for _, tt := range tests {
+		if got := dialogFailed(tt.stderr); got != tt.want {
+			t.Errorf("dialogFailed(%q) = %v, want %v", tt.stderr, got, tt.want)
+		}
+	}
+}
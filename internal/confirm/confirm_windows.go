@@ -5,17 +5,14 @@ package confirm
 
 import (
 	"bytes"
+	"context"
 	"fmt"
-	"html"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
-	"sort"
 	"strings"
 	"syscall"
 	"time"
-	"unicode"
 	"unsafe"
 )
 
@@ -40,32 +37,17 @@ const (
 	IDNO     = 7
 )
 
-// ConfirmRequest contains the data for a confirmation dialog.
-type ConfirmRequest struct {
-	SQL             string
-	MatchedKeywords []string
-	// MatchedKeywordsForHighlight, if non-empty, limits red keyword markup to these terms (Java: hits on formatted text only).
-	MatchedKeywordsForHighlight []string
-	MatchedActions              []string // command_match statement types (Java parity); merged into red highlight when set
-	StatementType               string
-	IsDDL                       bool
-	Connection                  string // Database alias from config (e.g. "database1", "database2") for title/display
-	// ConnectionIndex is the 0-based index in the configured connections list; selects header bar color (same palette as Java).
-	ConnectionIndex int
-	SourceLabel     string // Optional, e.g. "File: path/to/file.sql" for execute_sql_file
-}
-
-// Confirmer handles user confirmation dialogs.
-type Confirmer struct{}
+// DialogConfirmer handles user confirmation dialogs.
+type DialogConfirmer struct{}
 
-// NewConfirmer creates a new Confirmer instance.
-func NewConfirmer() *Confirmer {
-	return &Confirmer{}
+// NewDialogConfirmer creates a new DialogConfirmer instance.
+func NewDialogConfirmer() *DialogConfirmer {
+	return &DialogConfirmer{}
 }
 
 // Confirm shows a confirmation dialog with full SQL in a large scrollable window and returns true if the user approves.
 // Uses PowerShell WinForms (never MessageBox) so SQL is never truncated and scrollbars are shown.
-func (c *Confirmer) Confirm(req *ConfirmRequest) (bool, error) {
+func (c *DialogConfirmer) Confirm(ctx context.Context, req *ConfirmRequest) (bool, error) {
 	sqlDir := os.TempDir()
 	htmlPath := filepath.Join(sqlDir, "oracle-mcp-confirm-sql.html")
 	resultPath := filepath.Join(sqlDir, "oracle-mcp-confirm-result.txt")
@@ -96,14 +78,35 @@ func (c *Confirmer) Confirm(req *ConfirmRequest) (bool, error) {
 	}
 
 	headerColor := headerBarColor(req.ConnectionIndex)
+	args := []string{"-NoProfile", "-STA", "-ExecutionPolicy", "Bypass", "-File", scriptPath,
+		"-HtmlPath", htmlPath, "-ResultPath", resultPath, "-HeaderPath", headerPath, "-Connection", connectionArg, "-HeaderColor", headerColor}
+
+	// With masked values, the Reveal button switches to the SQL and header as they run
+	if req.Masked() {
+		revealed := req.Revealed()
+		revealHTMLPath := filepath.Join(sqlDir, "oracle-mcp-confirm-sql-revealed.html")
+		revealHeaderPath := filepath.Join(sqlDir, "oracle-mcp-confirm-header-revealed.txt")
+		revealHTML := highlightMatchedKeywordsInHTML(sqlHighlightHTML(revealed.SQL), highlightTermsForReview(revealed))
+		if err := os.WriteFile(revealHTMLPath, []byte(revealHTML), 0600); err != nil {
+			return false, fmt.Errorf("confirm: cannot write HTML temp file: %w", err)
+		}
+		defer os.Remove(revealHTMLPath)
+		if err := os.WriteFile(revealHeaderPath, []byte(buildConfirmHeader(revealed)), 0600); err != nil {
+			return false, fmt.Errorf("confirm: cannot write header temp file: %w", err)
+		}
+		defer os.Remove(revealHeaderPath)
+		args = append(args, "-RevealHtmlPath", revealHTMLPath, "-RevealHeaderPath", revealHeaderPath)
+	}
 
-	// -STA required for Windows Forms to display correctly
-	cmd := exec.Command("powershell.exe", "-NoProfile", "-STA", "-ExecutionPolicy", "Bypass", "-File", scriptPath,
-		"-HtmlPath", htmlPath, "-ResultPath", resultPath, "-HeaderPath", headerPath, "-Connection", connectionArg, "-HeaderColor", headerColor)
+	// -STA required for Windows Forms to display correctly; the window is killed when ctx ends
+	cmd := exec.CommandContext(ctx, "powershell.exe", args...)
 	cmd.Stdin = nil
 	var stderr strings.Builder
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
 		if stderr.Len() > 0 {
 			fmt.Fprintf(os.Stderr, "oracle-mcp confirm PowerShell stderr: %s\n", stderr.String())
 		}
@@ -131,268 +134,6 @@ func (c *Confirmer) Confirm(req *ConfirmRequest) (bool, error) {
 	return s == "1", nil
 }
 
-func buildConfirmHeader(req *ConfirmRequest) string {
-	var line1 []string
-	if req.Connection != "" {
-		line1 = append(line1, "Database: "+req.Connection)
-	}
-	if len(req.MatchedActions) > 0 {
-		line1 = append(line1, "Action: "+strings.Join(req.MatchedActions, ", "))
-	} else if req.StatementType != "" {
-		line1 = append(line1, "Action: "+req.StatementType)
-	}
-	if len(req.MatchedKeywords) > 0 {
-		line1 = append(line1, "Keywords: "+strings.Join(req.MatchedKeywords, ", "))
-	}
-	if req.IsDDL {
-		line1 = append(line1, "DDL (auto-committed)")
-	}
-	var out string
-	if len(line1) > 0 {
-		out = strings.Join(line1, "    |    ")
-	}
-	if req.SourceLabel != "" {
-		if out != "" {
-			out += "\n"
-		}
-		out += req.SourceLabel // "File: path" on its own second line
-	}
-	if out == "" {
-		return "Confirm SQL execution"
-	}
-	return out
-}
-
-// Same palette as Java Confirmer.HEADER_COLORS (connection index mod length).
-var headerBarColors = []string{
-	"A5D6A7", "90CAF9", "FFCC80", "CE93D8", "F48FB1",
-	"80DEEA", "EF9A9A", "80CBC4", "FFF59D", "BCAAA4",
-}
-
-func headerBarColor(connectionIndex int) string {
-	if connectionIndex < 0 {
-		connectionIndex = 0
-	}
-	return headerBarColors[connectionIndex%len(headerBarColors)]
-}
-
-var tagOrTextRE = regexp.MustCompile(`(<[^>]+>)|([^<]+)`)
-
-const highlightSpanStart = `<span style="color:red;font-weight:bold">`
-const highlightSpanEnd = `</span>`
-
-// highlightTermsForReview merges keyword and action strings for red markup (aligned with Java Confirmer).
-func highlightTermsForReview(req *ConfirmRequest) []string {
-	seen := make(map[string]struct{})
-	var out []string
-	add := func(s string) {
-		s = strings.TrimSpace(s)
-		if s == "" {
-			return
-		}
-		key := strings.ToLower(s)
-		if _, ok := seen[key]; ok {
-			return
-		}
-		seen[key] = struct{}{}
-		out = append(out, s)
-	}
-	kwSrc := req.MatchedKeywords
-	if len(req.MatchedKeywordsForHighlight) > 0 {
-		kwSrc = req.MatchedKeywordsForHighlight
-	}
-	for _, k := range kwSrc {
-		add(k)
-	}
-	for _, a := range req.MatchedActions {
-		add(a)
-	}
-	if len(out) == 0 {
-		return nil
-	}
-	sort.Slice(out, func(i, j int) bool { return len(out[i]) > len(out[j]) })
-	return out
-}
-
-// highlightMatchedKeywordsInHTML wraps whole-word (case-insensitive) matches in text nodes only, like Java Confirmer.
-func highlightMatchedKeywordsInHTML(htmlDoc string, terms []string) string {
-	if htmlDoc == "" || len(terms) == 0 {
-		return htmlDoc
-	}
-	var patterns []*regexp.Regexp
-	for _, term := range terms {
-		patterns = append(patterns, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(term)+`\b`))
-	}
-	var sb strings.Builder
-	subs := tagOrTextRE.FindAllStringSubmatchIndex(htmlDoc, -1)
-	for _, loc := range subs {
-		if loc[2] >= 0 && loc[3] >= 0 {
-			sb.WriteString(htmlDoc[loc[2]:loc[3]])
-			continue
-		}
-		if loc[4] >= 0 && loc[5] >= 0 {
-			text := htmlDoc[loc[4]:loc[5]]
-			for _, re := range patterns {
-				text = re.ReplaceAllStringFunc(text, func(m string) string {
-					return highlightSpanStart + m + highlightSpanEnd
-				})
-			}
-			sb.WriteString(text)
-		}
-	}
-	return sb.String()
-}
-
-// sqlKeywords for Oracle/PL-SQL syntax highlighting (lowercase for matching).
-var sqlKeywords = []string{
-	"create", "or", "replace", "procedure", "function", "package", "body", "begin", "end", "declare",
-	"varchar2", "number", "date", "clob", "blob", "in", "out", "inout", "return", "is", "as",
-	"if", "then", "elsif", "else", "loop", "for", "while", "exit", "when", "execute", "immediate",
-	"select", "insert", "update", "delete", "drop", "alter", "truncate", "grant", "revoke",
-	"table", "view", "index", "sequence", "trigger", "type", "constraint",
-	"null", "true", "false", "and", "not", "between", "like", "into", "values", "from", "where",
-	"order", "by", "group", "having", "join", "left", "right", "inner", "outer", "on", "using",
-	"commit", "rollback", "savepoint", "connect", "level", "dual", "sysdate",
-	"exception", "raise", "cursor", "open", "fetch", "close", "record", "type", "rowtype",
-	"abs", "set", "using", "default", "over", "partition", "with",
-}
-
-// sqlHighlightHTML returns a full HTML document with SQL syntax highlighting (keywords, strings, comments, numbers).
-func sqlHighlightHTML(sql string) string {
-	const (
-		classKeyword = "kw"
-		classString  = "str"
-		classComment = "cm"
-		classNumber  = "num"
-		classIdent   = "id"
-	)
-	// Build keyword regex: \b(word1|word2|...)\b
-	kwPattern := `\b(` + strings.Join(sqlKeywords, "|") + `)\b`
-	kwRe := regexp.MustCompile("(?i)" + kwPattern)
-
-	// escapeForDisplay escapes HTML, newlines -> <br>, spaces -> &nbsp; for review only; executed SQL is unchanged.
-	escapeForDisplay := func(s string) string {
-		s = html.EscapeString(s)
-		s = strings.ReplaceAll(s, "\n", "<br>")
-		s = strings.ReplaceAll(s, " ", "&nbsp;")
-		return s
-	}
-
-	var out strings.Builder
-	out.WriteString(`<!DOCTYPE html><html><head><meta charset="UTF-8"><style>
-.sql-wrap { font-family: Consolas, monospace; font-size: 11pt; background: #ffffff; color: #24292e; padding: 12px; white-space: pre-wrap; word-break: break-word; overflow: visible; margin: 0; }
-.sql-wrap .kw { color: #0550ae; }
-.sql-wrap .str { color: #cf2222; }
-.sql-wrap .cm { color: #57606a; }
-.sql-wrap .num { color: #116329; }
-.sql-wrap .id { color: #953800; }
-</style></head><body class="sql-wrap"><code>`)
-
-	i := 0
-	for i < len(sql) {
-		// Double-quoted identifier (e.g. Oracle); do not treat as keyword (Java BaseFormatter).
-		if sql[i] == '"' {
-			start := i
-			i++
-			for i < len(sql) {
-				if sql[i] == '"' {
-					i++
-					if i < len(sql) && sql[i] == '"' {
-						i++
-						continue
-					}
-					break
-				}
-				i++
-			}
-			out.WriteString(`<span class="` + classIdent + `">`)
-			out.WriteString(escapeForDisplay(sql[start:i]))
-			out.WriteString("</span>")
-			continue
-		}
-		// String literal (single-quoted, allow '' inside)
-		if sql[i] == '\'' {
-			start := i
-			i++
-			for i < len(sql) {
-				if sql[i] == '\'' {
-					i++
-					if i < len(sql) && sql[i] == '\'' {
-						i++
-						continue
-					}
-					break
-				}
-				i++
-			}
-			out.WriteString(`<span class="` + classString + `">`)
-			out.WriteString(escapeForDisplay(sql[start:i]))
-			out.WriteString("</span>")
-			continue
-		}
-		// Line comment
-		if i+1 < len(sql) && sql[i] == '-' && sql[i+1] == '-' {
-			start := i
-			for i < len(sql) && sql[i] != '\n' {
-				i++
-			}
-			out.WriteString(`<span class="` + classComment + `">`)
-			out.WriteString(escapeForDisplay(sql[start:i]))
-			out.WriteString("</span>")
-			continue
-		}
-		// Block comment
-		if i+1 < len(sql) && sql[i] == '/' && sql[i+1] == '*' {
-			start := i
-			i += 2
-			for i+1 < len(sql) && (sql[i] != '*' || sql[i+1] != '/') {
-				i++
-			}
-			if i+1 < len(sql) {
-				i += 2
-			}
-			out.WriteString(`<span class="` + classComment + `">`)
-			out.WriteString(escapeForDisplay(sql[start:i]))
-			out.WriteString("</span>")
-			continue
-		}
-		// Word (for keywords and numbers)
-		if unicode.IsLetter(rune(sql[i])) || sql[i] == '_' || unicode.IsNumber(rune(sql[i])) {
-			start := i
-			for i < len(sql) && (unicode.IsLetter(rune(sql[i])) || sql[i] == '_' || unicode.IsNumber(rune(sql[i]))) {
-				i++
-			}
-			seg := sql[start:i]
-			escaped := escapeForDisplay(seg)
-			allDigits := len(seg) > 0
-			for _, r := range seg {
-				if !unicode.IsDigit(r) {
-					allDigits = false
-					break
-				}
-			}
-			if allDigits {
-				out.WriteString(`<span class="` + classNumber + `">`)
-				out.WriteString(escaped)
-				out.WriteString("</span>")
-			} else if kwRe.MatchString(seg) {
-				out.WriteString(`<span class="` + classKeyword + `">`)
-				out.WriteString(escaped)
-				out.WriteString("</span>")
-			} else {
-				out.WriteString(escaped)
-			}
-			continue
-		}
-		// Single char (escape for HTML, newline -> <br>)
-		out.WriteString(escapeForDisplay(string(sql[i])))
-		i++
-	}
-
-	out.WriteString("</code></body></html>")
-	return out.String()
-}
-
 // messageBox calls the Windows MessageBoxW API.
 func messageBox(hwnd uintptr, text, caption string, flags uint32) int {
 	textPtr, _ := syscall.UTF16PtrFromString(text)
@@ -407,53 +148,28 @@ func messageBox(hwnd uintptr, text, caption string, flags uint32) int {
 }
 
 // ShowError displays an error message dialog.
-func (c *Confirmer) ShowError(title, message string) {
+func (c *DialogConfirmer) ShowError(title, message string) {
 	messageBox(0, message, title, MB_OK|MB_ICONERROR)
 }
 
 // ShowInfo displays an informational message dialog.
-func (c *Confirmer) ShowInfo(title, message string) {
+func (c *DialogConfirmer) ShowInfo(title, message string) {
 	messageBox(0, message, title, MB_OK|MB_ICONINFORMATION)
 }
 
 // Available returns true on Windows.
-func (c *Confirmer) Available() bool {
+func (c *DialogConfirmer) Available() bool {
 	return true
 }
 
 // PlatformName returns the platform name.
-func (c *Confirmer) PlatformName() string {
+func (c *DialogConfirmer) PlatformName() string {
 	return "windows"
 }
 
-// FormatConfirmationMessage formats the confirmation message for logging.
-func FormatConfirmationMessage(req *ConfirmRequest) string {
-	conn := req.Connection
-	if conn == "" {
-		conn = "default"
-	}
-	return fmt.Sprintf(
-		"Connection=[%s] SQL=[%s] Keywords=[%s] Type=[%s] IsDDL=[%v]",
-		conn,
-		truncateSQL(req.SQL, 100),
-		strings.Join(req.MatchedKeywords, ","),
-		req.StatementType,
-		req.IsDDL,
-	)
-}
-
-func truncateSQL(sql string, maxLen int) string {
-	sql = strings.ReplaceAll(sql, "\n", " ")
-	sql = strings.ReplaceAll(sql, "\r", "")
-	if len(sql) > maxLen {
-		return sql[:maxLen] + "..."
-	}
-	return sql
-}
-
 // ps1Script is the PowerShell script for the confirmation form (WebBrowser with HTML syntax-highlighted SQL).
 const ps1Script = `
-param([string]$HtmlPath, [string]$ResultPath, [string]$HeaderPath, [string]$Connection = "default", [string]$HeaderColor = "A5D6A7")
+param([string]$HtmlPath, [string]$ResultPath, [string]$HeaderPath, [string]$Connection = "default", [string]$HeaderColor = "A5D6A7", [string]$RevealHtmlPath = "", [string]$RevealHeaderPath = "")
 $Header = if (Test-Path $HeaderPath) { [System.IO.File]::ReadAllText($HeaderPath, [System.Text.Encoding]::UTF8) } else { "Confirm SQL execution" }
 Add-Type -AssemblyName System.Windows.Forms
 Add-Type -AssemblyName System.Drawing
@@ -509,6 +225,21 @@ $btnCancel.Anchor = [System.Windows.Forms.AnchorStyles]::Bottom -bor [System.Win
 $btnCancel.DialogResult = [System.Windows.Forms.DialogResult]::Cancel
 $form.Controls.Add($btnCancel)
 
+if ($RevealHtmlPath) {
+	$btnReveal = New-Object System.Windows.Forms.Button
+	$btnReveal.Text = "Reveal"
+	$btnReveal.Location = New-Object System.Drawing.Point(10, 670)
+	$btnReveal.Size = New-Object System.Drawing.Size(90, 28)
+	$btnReveal.Anchor = [System.Windows.Forms.AnchorStyles]::Bottom -bor [System.Windows.Forms.AnchorStyles]::Left
+	$btnReveal.Add_Click({
+		$revealUri = [Uri]::new("file:///" + $RevealHtmlPath.Replace('\', '/').Replace(' ', '%20'))
+		$browser.Navigate($revealUri.AbsoluteUri)
+		if (Test-Path $RevealHeaderPath) { $lbl.Text = [System.IO.File]::ReadAllText($RevealHeaderPath, [System.Text.Encoding]::UTF8).Trim() }
+		$btnReveal.Enabled = $false
+	})
+	$form.Controls.Add($btnReveal)
+}
+
 $form.Controls.Add($browser)
 $form.Controls.SetChildIndex($browser, 1)
 # Put focus on the SQL content (browser), not on Execute/Cancel, so user typing does not trigger a button
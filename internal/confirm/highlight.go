@@ -0,0 +1,194 @@
+package confirm
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// HTML review rendering shared by the Windows WebBrowser window and the browser review page.
+
+var tagOrTextRE = regexp.MustCompile(`(<[^>]+>)|([^<]+)`)
+
+const highlightSpanStart = `<span style="color:red;font-weight:bold">`
+const highlightSpanEnd = `</span>`
+
+// highlightMatchedKeywordsInHTML wraps whole-word (case-insensitive) matches in text nodes only, like Java Confirmer.
+func highlightMatchedKeywordsInHTML(htmlDoc string, terms []string) string {
+	if htmlDoc == "" || len(terms) == 0 {
+		return htmlDoc
+	}
+	var patterns []*regexp.Regexp
+	for _, term := range terms {
+		patterns = append(patterns, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(term)+`\b`))
+	}
+	var sb strings.Builder
+	subs := tagOrTextRE.FindAllStringSubmatchIndex(htmlDoc, -1)
+	for _, loc := range subs {
+		if loc[2] >= 0 && loc[3] >= 0 {
+			sb.WriteString(htmlDoc[loc[2]:loc[3]])
+			continue
+		}
+		if loc[4] >= 0 && loc[5] >= 0 {
+			text := htmlDoc[loc[4]:loc[5]]
+			for _, re := range patterns {
+				text = re.ReplaceAllStringFunc(text, func(m string) string {
+					return highlightSpanStart + m + highlightSpanEnd
+				})
+			}
+			sb.WriteString(text)
+		}
+	}
+	return sb.String()
+}
+
+// sqlKeywords for Oracle/PL-SQL syntax highlighting (lowercase for matching).
+var sqlKeywords = []string{
+	"create", "or", "replace", "procedure", "function", "package", "body", "begin", "end", "declare",
+	"varchar2", "number", "date", "clob", "blob", "in", "out", "inout", "return", "is", "as",
+	"if", "then", "elsif", "else", "loop", "for", "while", "exit", "when", "execute", "immediate",
+	"select", "insert", "update", "delete", "drop", "alter", "truncate", "grant", "revoke",
+	"table", "view", "index", "sequence", "trigger", "type", "constraint",
+	"null", "true", "false", "and", "not", "between", "like", "into", "values", "from", "where",
+	"order", "by", "group", "having", "join", "left", "right", "inner", "outer", "on", "using",
+	"commit", "rollback", "savepoint", "connect", "level", "dual", "sysdate",
+	"exception", "raise", "cursor", "open", "fetch", "close", "record", "type", "rowtype",
+	"abs", "set", "using", "default", "over", "partition", "with",
+}
+
+// sqlHighlightHTML returns a full HTML document with SQL syntax highlighting (keywords, strings, comments, numbers).
+func sqlHighlightHTML(sql string) string {
+	const (
+		classKeyword = "kw"
+		classString  = "str"
+		classComment = "cm"
+		classNumber  = "num"
+		classIdent   = "id"
+	)
+	// Build keyword regex: \b(word1|word2|...)\b
+	kwPattern := `\b(` + strings.Join(sqlKeywords, "|") + `)\b`
+	kwRe := regexp.MustCompile("(?i)" + kwPattern)
+
+	// escapeForDisplay escapes HTML, newlines -> <br>, spaces -> &nbsp; for review only; executed SQL is unchanged.
+	escapeForDisplay := func(s string) string {
+		s = html.EscapeString(s)
+		s = strings.ReplaceAll(s, "\n", "<br>")
+		s = strings.ReplaceAll(s, " ", "&nbsp;")
+		return s
+	}
+
+	var out strings.Builder
+	out.WriteString(`<!DOCTYPE html><html><head><meta charset="UTF-8"><style>
+.sql-wrap { font-family: Consolas, monospace; font-size: 11pt; background: #ffffff; color: #24292e; padding: 12px; white-space: pre-wrap; word-break: break-word; overflow: visible; margin: 0; }
+.sql-wrap .kw { color: #0550ae; }
+.sql-wrap .str { color: #cf2222; }
+.sql-wrap .cm { color: #57606a; }
+.sql-wrap .num { color: #116329; }
+.sql-wrap .id { color: #953800; }
+</style></head><body class="sql-wrap"><code>`)
+
+	i := 0
+	for i < len(sql) {
+		// Double-quoted identifier (e.g. Oracle); do not treat as keyword (Java BaseFormatter).
+		if sql[i] == '"' {
+			start := i
+			i++
+			for i < len(sql) {
+				if sql[i] == '"' {
+					i++
+					if i < len(sql) && sql[i] == '"' {
+						i++
+						continue
+					}
+					break
+				}
+				i++
+			}
+			out.WriteString(`<span class="` + classIdent + `">`)
+			out.WriteString(escapeForDisplay(sql[start:i]))
+			out.WriteString("</span>")
+			continue
+		}
+		// String literal (single-quoted, allow '' inside)
+		if sql[i] == '\'' {
+			start := i
+			i++
+			for i < len(sql) {
+				if sql[i] == '\'' {
+					i++
+					if i < len(sql) && sql[i] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+				i++
+			}
+			out.WriteString(`<span class="` + classString + `">`)
+			out.WriteString(escapeForDisplay(sql[start:i]))
+			out.WriteString("</span>")
+			continue
+		}
+		// Line comment
+		if i+1 < len(sql) && sql[i] == '-' && sql[i+1] == '-' {
+			start := i
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			out.WriteString(`<span class="` + classComment + `">`)
+			out.WriteString(escapeForDisplay(sql[start:i]))
+			out.WriteString("</span>")
+			continue
+		}
+		// Block comment
+		if i+1 < len(sql) && sql[i] == '/' && sql[i+1] == '*' {
+			start := i
+			i += 2
+			for i+1 < len(sql) && (sql[i] != '*' || sql[i+1] != '/') {
+				i++
+			}
+			if i+1 < len(sql) {
+				i += 2
+			}
+			out.WriteString(`<span class="` + classComment + `">`)
+			out.WriteString(escapeForDisplay(sql[start:i]))
+			out.WriteString("</span>")
+			continue
+		}
+		// Word (for keywords and numbers)
+		if unicode.IsLetter(rune(sql[i])) || sql[i] == '_' || unicode.IsNumber(rune(sql[i])) {
+			start := i
+			for i < len(sql) && (unicode.IsLetter(rune(sql[i])) || sql[i] == '_' || unicode.IsNumber(rune(sql[i]))) {
+				i++
+			}
+			seg := sql[start:i]
+			escaped := escapeForDisplay(seg)
+			allDigits := len(seg) > 0
+			for _, r := range seg {
+				if !unicode.IsDigit(r) {
+					allDigits = false
+					break
+				}
+			}
+			if allDigits {
+				out.WriteString(`<span class="` + classNumber + `">`)
+				out.WriteString(escaped)
+				out.WriteString("</span>")
+			} else if kwRe.MatchString(seg) {
+				out.WriteString(`<span class="` + classKeyword + `">`)
+				out.WriteString(escaped)
+				out.WriteString("</span>")
+			} else {
+				out.WriteString(escaped)
+			}
+			continue
+		}
+		// Single char (escape for HTML, newline -> <br>)
+		out.WriteString(escapeForDisplay(string(sql[i])))
+		i++
+	}
+
+	out.WriteString("</code></body></html>")
+	return out.String()
+}
@@ -0,0 +1,76 @@
+package confirm
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNoScriptedAnswer is returned by Scripted when all recorded answers have been used.
+var ErrNoScriptedAnswer = errors.New("confirm: no scripted answer left")
+
+// Answer is one scripted reply: Err, when set, is returned instead of Approved.
+type Answer struct {
+	Approved bool
+	Err      error
+}
+
+// Scripted is an in-memory Confirmer for tests. It replays recorded answers in order and then
+// falls back to its default answer (if any). Every request it receives is kept for inspection.
+type Scripted struct {
+	mu       sync.Mutex
+	answers  []Answer
+	fallback *Answer
+	requests []*ConfirmRequest
+}
+
+// NewScripted returns a Scripted confirmer that replays answers in order; once they are used up,
+// Confirm returns ErrNoScriptedAnswer.
+func NewScripted(answers ...Answer) *Scripted {
+	return &Scripted{answers: answers}
+}
+
+// AlwaysApprove returns a Scripted confirmer that approves every request.
+func AlwaysApprove() *Scripted {
+	return &Scripted{fallback: &Answer{Approved: true}}
+}
+
+// AlwaysReject returns a Scripted confirmer that rejects every request.
+func AlwaysReject() *Scripted {
+	return &Scripted{fallback: &Answer{Approved: false}}
+}
+
+// AlwaysError returns a Scripted confirmer that fails every request with err (as when no dialog can be shown).
+func AlwaysError(err error) *Scripted {
+	return &Scripted{fallback: &Answer{Err: err}}
+}
+
+// Confirm records the request and returns the next scripted answer.
+func (s *Scripted) Confirm(ctx context.Context, req *ConfirmRequest) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	var a Answer
+	switch {
+	case len(s.answers) > 0:
+		a = s.answers[0]
+		s.answers = s.answers[1:]
+	case s.fallback != nil:
+		a = *s.fallback
+	default:
+		return false, ErrNoScriptedAnswer
+	}
+	if a.Err != nil {
+		return false, a.Err
+	}
+	return a.Approved, nil
+}
+
+// Requests returns the confirmation requests received so far, in order.
+func (s *Scripted) Requests() []*ConfirmRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*ConfirmRequest, len(s.requests))
+	copy(out, s.requests)
+	return out
+}
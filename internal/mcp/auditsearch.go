@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alvin/oracle-mcp-server/internal/audit"
+)
+
+// Page sizes of search_audit.
+const (
+	defaultAuditSearchLimit = 50
+	maxAuditSearchLimit     = 500
+)
+
+// auditTimeLayouts are the accepted forms of search_audit's since and until; those without a zone are local time.
+var auditTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+func searchAuditTool() tool {
+	return tool{
+		Name: "search_audit",
+		Description: "Search this server's audit log (all rotated files, text or JSONL format) for the SQL that was run, rejected or refused, newest first. " +
+			"Filters combine with AND. Results are paginated: pass next_offset as offset for the next page.",
+		InputSchema: inputSchema{
+			Type: "object",
+			Properties: map[string]property{
+				"since":        {Type: "string", Description: "Entries at or after this time: RFC 3339, 2006-01-02 15:04:05 or 2006-01-02 (server local time when no zone is given)."},
+				"until":        {Type: "string", Description: "Entries before this time, same forms as since (until 2024-05-02 excludes that day)."},
+				"connection":   {Type: "string", Description: "Connection name, as in list_connections."},
+				"action":       {Type: "string", Description: "Action, e.g. SUCCESS, USER_REJECTED, EXECUTION_ERROR, POLICY_DENIED, READ_ONLY_REJECTED; matches the action before its ': detail'."},
+				"keyword":      {Type: "string", Description: "A danger keyword the SQL matched, e.g. drop."},
+				"sql_contains": {Type: "string", Description: "Case-insensitive substring of the SQL."},
+				"limit":        {Type: "integer", Description: fmt.Sprintf("Entries per page (default %d, at most %d).", defaultAuditSearchLimit, maxAuditSearchLimit)},
+				"offset":       {Type: "integer", Description: "Matching entries to skip (default 0)."},
+			},
+			Required: []string{},
+		},
+	}
+}
+
+// handleSearchAudit handles the search_audit tool.
+func (s *Server) handleSearchAudit(req *jsonRPCRequest, args map[string]interface{}) {
+	if s.auditor == nil {
+		s.sendToolError(req.ID, "search_audit: audit logging is disabled (logging.audit_log)")
+		return
+	}
+	q := audit.Query{
+		Connection:  stringArg(args, "connection"),
+		Action:      stringArg(args, "action"),
+		Keyword:     stringArg(args, "keyword"),
+		SQLContains: stringArg(args, "sql_contains"),
+		Limit:       defaultAuditSearchLimit,
+	}
+	var err error
+	if q.Since, err = auditTimeArg(args, "since"); err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
+	if q.Until, err = auditTimeArg(args, "until"); err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
+	limit, err := limitArg(args)
+	if err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
+	if limit > 0 {
+		q.Limit = min(limit, maxAuditSearchLimit)
+	}
+	if v, ok := args["offset"]; ok && v != nil {
+		n, ok := v.(float64)
+		if !ok || n < 0 || n != float64(int(n)) {
+			s.sendToolError(req.ID, "Parameter 'offset' must be a non-negative integer")
+			return
+		}
+		q.Offset = int(n)
+	}
+
+	res, err := s.auditor.Search(q)
+	if err != nil {
+		s.sendToolError(req.ID, "search_audit failed: "+err.Error())
+		return
+	}
+	out := map[string]interface{}{
+		"total":   res.Total,
+		"offset":  q.Offset,
+		"entries": res.Entries,
+	}
+	if next := q.Offset + len(res.Entries); next < res.Total {
+		out["next_offset"] = next
+	}
+	resultJSON, _ := json.MarshalIndent(out, "", "  ")
+	s.sendToolResult(req.ID, string(resultJSON))
+}
+
+// auditTimeArg returns the optional time argument key of search_audit (zero when omitted).
+func auditTimeArg(args map[string]interface{}, key string) (time.Time, error) {
+	v := stringArg(args, key)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range auditTimeLayouts {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Parameter '%s' must be a time: RFC 3339, 2006-01-02 15:04:05 or 2006-01-02", key)
+}
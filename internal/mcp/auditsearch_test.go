@@ -0,0 +1,43 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/alvin/oracle-mcp-server/internal/confirm"
+)
+
+func TestSearchAudit(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysReject())
+	ts.send(t,
+		toolCall(1, "execute_sql", map[string]interface{}{"sql": "DROP TABLE a"}),
+		toolCall(2, "execute_sql", map[string]interface{}{"sql": "DROP TABLE b"}),
+		toolCall(3, "execute_sql", map[string]interface{}{"sql": "TRUNCATE TABLE c"}),
+	)
+
+	resps := ts.send(t,
+		toolCall(4, "search_audit", map[string]interface{}{"action": "USER_REJECTED", "sql_contains": "drop table", "limit": 1}),
+		toolCall(5, "search_audit", map[string]interface{}{"since": "yesterday"}),
+	)
+	var page struct {
+		Total      int `json:"total"`
+		NextOffset int `json:"next_offset"`
+		Entries    []struct {
+			SQL        string `json:"sql"`
+			Connection string `json:"connection"`
+		} `json:"entries"`
+	}
+	text, isError := toolText(t, resps[0])
+	if isError {
+		t.Fatalf("search_audit failed: %s", text)
+	}
+	if err := json.Unmarshal([]byte(text), &page); err != nil {
+		t.Fatalf("result: %v", err)
+	}
+	if page.Total != 2 || page.NextOffset != 1 || len(page.Entries) != 1 || page.Entries[0].SQL != "DROP TABLE b" || page.Entries[0].Connection != "db1" {
+		t.Errorf("page = %+v", page)
+	}
+	if _, isError := toolText(t, resps[1]); !isError {
+		t.Errorf("invalid since: %v", resps[1])
+	}
+}
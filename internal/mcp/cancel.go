@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alvin/oracle-mcp-server/internal/oracle"
+)
+
+// timeoutDescription documents the optional "timeout_seconds" argument of the tools that run SQL.
+const timeoutDescription = "Optional timeout in seconds; the Oracle call is cancelled when it runs longer. Defaults to config oracle.query_timeout_seconds (or the connection's entry in oracle.query_timeouts) and cannot exceed it."
+
+// cancelledParams are the params of notifications/cancelled.
+type cancelledParams struct {
+	RequestID interface{} `json:"requestId"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
+// beginRequest returns the context for an incoming tools/call; notifications/cancelled for req.ID cancels it.
+// Call the returned function when the request is done.
+func (s *Server) beginRequest(req *jsonRPCRequest) (context.Context, func()) {
+	call := &auditCall{start: time.Now()}
+	if req.ID != nil {
+		call.requestID = fmt.Sprint(req.ID)
+	}
+	ctx, cancel := context.WithCancelCause(context.WithValue(context.Background(), auditCallKey{}, call))
+	if req.ID == nil {
+		return ctx, func() { cancel(nil) }
+	}
+	key := fmt.Sprint(req.ID)
+	s.inflightMu.Lock()
+	s.inflight[key] = cancel
+	s.inflightMu.Unlock()
+	return ctx, func() {
+		s.inflightMu.Lock()
+		delete(s.inflight, key)
+		s.inflightMu.Unlock()
+		cancel(nil)
+	}
+}
+
+// handleCancelled handles notifications/cancelled: the in-flight request's context is cancelled, so godror
+// breaks the running Oracle call. Unknown or finished requests are ignored, as the protocol allows.
+func (s *Server) handleCancelled(req *jsonRPCRequest) {
+	var params cancelledParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.RequestID == nil {
+		return
+	}
+	reason := params.Reason
+	if reason == "" {
+		reason = "no reason given"
+	}
+	s.cancelRequest(fmt.Sprint(params.RequestID), errors.New(reason))
+}
+
+// cancelRequest cancels the in-flight request with the given id, if any, with cause as the reason.
+func (s *Server) cancelRequest(key string, cause error) {
+	s.inflightMu.Lock()
+	cancel, ok := s.inflight[key]
+	s.inflightMu.Unlock()
+	if !ok {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Cancelling request %s: %v\n", key, cause)
+	cancel(cause)
+}
+
+// timeoutArg returns the optional "timeout_seconds" argument (0 when omitted: the connection's configured
+// timeout applies). A timeout longer than the named connection's limit is rejected here, before any review dialog.
+func (s *Server) timeoutArg(args map[string]interface{}, connectionName string) (time.Duration, error) {
+	v, ok := args["timeout_seconds"]
+	if !ok || v == nil {
+		return 0, nil
+	}
+	n, ok := v.(float64)
+	if !ok || n <= 0 || n != float64(int(n)) {
+		return 0, fmt.Errorf("Parameter 'timeout_seconds' must be a positive integer")
+	}
+	d := time.Duration(n) * time.Second
+	if _, err := s.executorPool.QueryTimeout(connectionName, d); err != nil {
+		return 0, err
+	}
+	return d, nil
+}
+
+// reportInterrupted answers a call that the client cancelled (ctx is done) or whose query timed out (err is an
+// *oracle.TimeoutError) and records it in the audit log. approved is false while the call's review is still open,
+// true once it passed review or when it needed none. It returns false for any other outcome, which the caller
+// reports itself.
+func (s *Server) reportInterrupted(ctx context.Context, id interface{}, err error, approved bool, sql string, keywords []string, binds []string, connection string) bool {
+	if ctx.Err() != nil {
+		reason := context.Cause(ctx).Error()
+		s.logAudit(ctx, sql, keywords, binds, approved, "CANCELLED: "+reason, connection)
+		s.sendError(id, ErrCodeRequestCancelled, "Request cancelled by client", map[string]interface{}{
+			"code":   "REQUEST_CANCELLED",
+			"reason": reason,
+		})
+		return true
+	}
+	var timeoutErr *oracle.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		seconds := int(timeoutErr.Timeout / time.Second)
+		if connection == "" {
+			connection = timeoutErr.Connection
+		}
+		s.logAudit(ctx, sql, keywords, binds, approved, "TIMEOUT: "+err.Error(), connection)
+		s.sendError(id, ErrCodeQueryTimeout, fmt.Sprintf("Query exceeded the %d second timeout and was cancelled", seconds), map[string]interface{}{
+			"code":            "QUERY_TIMEOUT",
+			"timeout_seconds": seconds,
+			"connection":      timeoutErr.Connection,
+		})
+		return true
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvin/oracle-mcp-server/internal/config"
+	"github.com/alvin/oracle-mcp-server/internal/confirm"
+	"github.com/alvin/oracle-mcp-server/internal/oracle"
+)
+
+func TestTimeoutArg_Validation(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysApprove(), func(cfg *config.Config) {
+		cfg.Oracle.QueryTimeoutSeconds = 60
+		cfg.Oracle.QueryTimeouts = map[string]int{"db1": 30}
+	})
+
+	tests := []struct {
+		name    string
+		timeout interface{}
+		want    string
+	}{
+		{"zero", float64(0), "Parameter 'timeout_seconds' must be a positive integer"},
+		{"fractional", 1.5, "Parameter 'timeout_seconds' must be a positive integer"},
+		{"string", "10", "Parameter 'timeout_seconds' must be a positive integer"},
+		{"over connection limit", float64(45), `timeout_seconds 45 exceeds the 30 second query timeout configured for "db1"`},
+		{"within limit", float64(10), `SQL execution failed: connection "db1" is currently unavailable`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resps := ts.send(t, toolCall(1, "execute_sql", map[string]interface{}{"sql": "SELECT 1 FROM dual", "timeout_seconds": tt.timeout}))
+			text, isError := toolText(t, resps[0])
+			if !isError || !strings.HasPrefix(text, tt.want) {
+				t.Errorf("result = %q (isError=%v), want prefix %q", text, isError, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotificationsCancelled(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysApprove())
+
+	ctx, done := ts.beginRequest(&jsonRPCRequest{ID: float64(7)})
+	for _, params := range []string{`{"requestId":8,"reason":"other request"}`, `{"requestId":7,"reason":"user pressed stop"}`} {
+		ts.handleRequest(&jsonRPCRequest{JSONRPC: "2.0", Method: "notifications/cancelled", Params: json.RawMessage(params)})
+	}
+	if ts.out.Len() != 0 {
+		t.Errorf("notifications must not be answered: %s", ts.out.String())
+	}
+	if ctx.Err() == nil {
+		t.Fatal("request 7 was not cancelled")
+	}
+	if cause := context.Cause(ctx); cause == nil || cause.Error() != "user pressed stop" {
+		t.Errorf("cause = %v, want the client's reason", cause)
+	}
+
+	done()
+	if len(ts.inflight) != 0 {
+		t.Errorf("inflight = %v, want empty after the request is done", ts.inflight)
+	}
+}
+
+func TestReportInterrupted(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysApprove())
+
+	lastResponse := func() map[string]interface{} {
+		t.Helper()
+		var m map[string]interface{}
+		if err := json.Unmarshal(ts.out.Bytes(), &m); err != nil {
+			t.Fatalf("invalid JSON response %q: %v", ts.out.String(), err)
+		}
+		ts.out.Reset()
+		return m["error"].(map[string]interface{})
+	}
+
+	if ts.reportInterrupted(context.Background(), 1, errors.New("ORA-00942: table or view does not exist"), true, "SELECT * FROM t", nil, nil, "db1") {
+		t.Error("an ordinary error must be left to the caller")
+	}
+
+	timeoutErr := &oracle.TimeoutError{Connection: "db1", Timeout: 5 * time.Second, Err: errors.New("ORA-01013: user requested cancel of current operation")}
+	if !ts.reportInterrupted(context.Background(), 2, timeoutErr, true, "SELECT * FROM big", nil, nil, "") {
+		t.Fatal("timeout not reported")
+	}
+	e := lastResponse()
+	if e["code"] != float64(ErrCodeQueryTimeout) || e["data"].(map[string]interface{})["timeout_seconds"] != float64(5) {
+		t.Errorf("timeout error = %v", e)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("user pressed stop"))
+	if !ts.reportInterrupted(ctx, 3, context.Canceled, true, "SELECT * FROM big", nil, nil, "db1") {
+		t.Fatal("cancellation not reported")
+	}
+	e = lastResponse()
+	if e["code"] != float64(ErrCodeRequestCancelled) || e["data"].(map[string]interface{})["reason"] != "user pressed stop" {
+		t.Errorf("cancel error = %v", e)
+	}
+
+	entries := ts.auditEntries(t)
+	if len(entries) != 2 {
+		t.Fatalf("audit entries = %v, want 2", entries)
+	}
+	if !strings.HasPrefix(entries[0]["AUDIT_ACTION"], "TIMEOUT: ") || entries[0]["AUDIT_CONNECTION"] != "db1" {
+		t.Errorf("timeout audit = %v", entries[0])
+	}
+	if entries[1]["AUDIT_ACTION"] != "CANCELLED: user pressed stop" {
+		t.Errorf("cancel audit = %v", entries[1])
+	}
+}
@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alvin/oracle-mcp-server/internal/oracle"
+)
+
+// getDDLTool is the get_ddl tool listed by tools/list.
+func getDDLTool() tool {
+	return tool{
+		Name: "get_ddl",
+		Description: "Return the DDL of a database object from DBMS_METADATA, without storage and segment clauses and with statement terminators, " +
+			"optionally followed by its grants, indexes, triggers and comments. Omit name to export every object of a type (or of all common types) " +
+			"in a schema; such exports must be written to file_path.",
+		InputSchema: inputSchema{
+			Type: "object",
+			Properties: map[string]property{
+				"object_type": {
+					Type:        "string",
+					Description: "DBMS_METADATA object type: " + strings.Join(oracle.DDLObjectTypes, ", ") + ". PACKAGE returns spec and body. Required with name.",
+				},
+				"name":             {Type: "string", Description: "Object name. Omit to export the whole schema (requires file_path)."},
+				"owner":            {Type: "string", Description: "Schema (default: the current schema)."},
+				"include_grants":   {Type: "boolean", Description: "Append the object grants (default false)."},
+				"include_indexes":  {Type: "boolean", Description: "Tables and materialized views: append the indexes (default false)."},
+				"include_triggers": {Type: "boolean", Description: "Tables and views: append the triggers (default false; whole-schema exports list triggers as objects)."},
+				"include_comments": {Type: "boolean", Description: "Tables, views and materialized views: append the table and column comments (default false)."},
+				"file_path":        {Type: "string", Description: "Absolute path: write the DDL to this file instead of returning it."},
+				"connection":       connectionProperty,
+				"timeout_seconds":  {Type: "integer", Description: timeoutDescription},
+			},
+			Required: []string{},
+		},
+	}
+}
+
+// handleGetDDL handles the get_ddl tool. The DDL is returned as plain text, or written to file_path.
+func (s *Server) handleGetDDL(ctx context.Context, req *jsonRPCRequest, args map[string]interface{}) {
+	ddlReq := oracle.DDLRequest{
+		ObjectType: stringArg(args, "object_type"),
+		Owner:      stringArg(args, "owner"),
+		Name:       stringArg(args, "name"),
+	}
+	ddlReq.IncludeGrants, _ = args["include_grants"].(bool)
+	ddlReq.IncludeIndexes, _ = args["include_indexes"].(bool)
+	ddlReq.IncludeTriggers, _ = args["include_triggers"].(bool)
+	ddlReq.IncludeComments, _ = args["include_comments"].(bool)
+	filePath := stringArg(args, "file_path")
+	if filePath != "" && !filepath.IsAbs(filePath) {
+		s.sendToolError(req.ID, "file_path must be an absolute path")
+		return
+	}
+	if ddlReq.Name == "" && filePath == "" {
+		s.sendToolError(req.ID, "Missing required parameter: name (or pass file_path to export the whole schema)")
+		return
+	}
+	connectionName, displayConnection := s.connectionArg(args)
+	timeout, err := s.timeoutArg(args, connectionName)
+	if err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
+
+	result, err := s.executorPool.GetDDL(ctx, connectionName, ddlReq, oracle.ExecOptions{Timeout: timeout, Session: s.session})
+	if err != nil {
+		if s.reportInterrupted(ctx, req.ID, err, true, ddlAuditText(ddlReq, filePath), nil, nil, displayConnection) {
+			return
+		}
+		s.sendToolError(req.ID, "get_ddl failed: "+err.Error())
+		return
+	}
+	if filePath == "" {
+		s.sendToolResult(req.ID, result.DDL)
+		return
+	}
+
+	if err := os.WriteFile(filePath, []byte(result.DDL), 0o644); err != nil {
+		s.logAudit(ctx, ddlAuditText(ddlReq, filePath), nil, nil, false, "DDL_TO_FILE_ERROR: "+err.Error(), displayConnection)
+		s.sendToolError(req.ID, "get_ddl failed: write file: "+err.Error())
+		return
+	}
+	s.logAudit(ctx, ddlAuditText(ddlReq, filePath), nil, nil, true, "DDL_TO_FILE", displayConnection)
+	out := map[string]interface{}{
+		"file_path": filePath,
+		"objects":   len(result.Objects),
+		"message":   fmt.Sprintf("DDL of %d object(s) written to %s", len(result.Objects), filePath),
+	}
+	if len(result.Errors) > 0 {
+		out["errors"] = result.Errors
+	}
+	resultJSON, _ := json.MarshalIndent(out, "", "  ")
+	s.sendToolResult(req.ID, string(resultJSON))
+}
+
+// ddlAuditText describes a get_ddl call for the audit log.
+func ddlAuditText(req oracle.DDLRequest, filePath string) string {
+	what := strings.Join(strings.Fields("SCHEMA "+req.Owner+" "+strings.ToUpper(req.ObjectType)), " ")
+	if req.Name != "" {
+		what = strings.ToUpper(req.ObjectType) + " " + strings.TrimPrefix(req.Owner+"."+req.Name, ".")
+	}
+	if filePath != "" {
+		return "GET_DDL " + what + " TO " + filePath
+	}
+	return "GET_DDL " + what
+}
@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+	"slices"
+
+	"github.com/alvin/oracle-mcp-server/internal/confirm"
+)
+
+// maxConcurrentCalls bounds the tools/call requests that run at once; further calls wait for a worker.
+// A call waiting for review does not hold a worker (see confirmSQL).
+const maxConcurrentCalls = 8
+
+// startToolsCall handles a tools/call on its own goroutine, so a long query or an open review does not hold up
+// the read loop: ping, cancellations, elicitation answers and other calls are read meanwhile. Responses are
+// written under s.mu and may arrive in any order.
+func (s *Server) startToolsCall(req *jsonRPCRequest) {
+	// Registered before the goroutine starts, so a cancellation read right after the request finds it
+	ctx, done := s.beginRequest(req)
+	w := &callWorker{}
+	ctx = context.WithValue(ctx, callWorkerKey{}, w)
+	s.calls.Add(1)
+	go func() {
+		defer s.calls.Done()
+		defer done()
+		select {
+		case s.workers <- struct{}{}:
+			w.held = true
+		case <-ctx.Done():
+			s.sendError(req.ID, ErrCodeRequestCancelled, "Request cancelled by client", map[string]interface{}{
+				"code":   "REQUEST_CANCELLED",
+				"reason": context.Cause(ctx).Error(),
+			})
+			return
+		}
+		defer func() {
+			if w.held {
+				<-s.workers
+			}
+		}()
+		s.handleToolsCall(ctx, req)
+	}()
+}
+
+// callWorker records whether a tools/call holds a worker token; only the call's own goroutine uses it.
+type callWorker struct {
+	held bool
+}
+
+// callWorkerKey is the context key of the call's *callWorker.
+type callWorkerKey struct{}
+
+// confirmSQL asks the confirmer to approve req, with secrets masked (see maskReview). The calling tools/call gives
+// up its worker while the review is open, so pending reviews do not block other calls; reviews are shown one at a
+// time. Waiting for the review, the review itself and taking a worker back all end when ctx does; the call then
+// goes on without a worker and only reports the cancellation.
+func (s *Server) confirmSQL(ctx context.Context, req *confirm.ConfirmRequest) (bool, error) {
+	review := s.maskReview(req)
+	if w, _ := ctx.Value(callWorkerKey{}).(*callWorker); w != nil && w.held {
+		<-s.workers
+		w.held = false
+		defer func() {
+			select {
+			case s.workers <- struct{}{}:
+				w.held = true
+			case <-ctx.Done():
+			}
+		}()
+	}
+	select {
+	case s.reviewing <- struct{}{}:
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+	defer func() { <-s.reviewing }()
+	return s.confirmer.Confirm(ctx, review)
+}
+
+// maskReview returns req as shown for review: when redaction masks anything in its SQL or binds, a copy with the
+// masked SQL and binds and the originals in RevealSQL and RevealBinds; otherwise req itself.
+func (s *Server) maskReview(req *confirm.ConfirmRequest) *confirm.ConfirmRequest {
+	sql := s.redactor.Redact(req.SQL)
+	binds := s.redactor.RedactAll(req.Binds)
+	if sql == req.SQL && slices.Equal(binds, req.Binds) {
+		return req
+	}
+	review := *req
+	review.SQL, review.Binds = sql, binds
+	review.RevealSQL, review.RevealBinds = req.SQL, req.Binds
+	return &review
+}
+
+// closeInput releases calls that wait for a client response once no more input will be read.
+func (s *Server) closeInput() {
+	s.closeInputOnce.Do(func() { close(s.inputClosed) })
+}
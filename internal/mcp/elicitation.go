@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/alvin/oracle-mcp-server/internal/confirm"
+)
+
+// elicitationConfirmer asks for approval inside the MCP client via elicitation/create
+// (security.confirm_mode: "elicitation"). When the client did not advertise the elicitation
+// capability in initialize, the request goes to fallback (the OS dialog) instead.
+type elicitationConfirmer struct {
+	server   *Server
+	fallback confirm.Confirmer
+}
+
+// elicitationParams is the params for elicitation/create.
+type elicitationParams struct {
+	Message         string                 `json:"message"`
+	RequestedSchema map[string]interface{} `json:"requestedSchema"`
+}
+
+// elicitationResult is the client's answer: action is "accept", "decline" or "cancel";
+// content holds the form values when accepted.
+type elicitationResult struct {
+	Action  string                 `json:"action"`
+	Content map[string]interface{} `json:"content"`
+}
+
+// elicitationApproveField is the boolean the user must set to true (and submit) to run the SQL.
+const elicitationApproveField = "execute"
+
+// elicitationRevealField is offered when values are masked: submitted true with execute false, the review is
+// asked again with the SQL as it runs.
+const elicitationRevealField = "reveal"
+
+// Confirm sends the review as an elicitation and maps the answer: accept with execute=true approves;
+// accept with reveal=true asks again unmasked; accept with execute=false, decline and cancel reject. Transport or
+// protocol errors are returned as errors, as is ctx's when the call ends before the client answers.
+func (c *elicitationConfirmer) Confirm(ctx context.Context, req *confirm.ConfirmRequest) (bool, error) {
+	if _, elicitation := c.server.clientInfo(); !elicitation {
+		return c.fallback.Confirm(ctx, req)
+	}
+
+	properties := map[string]interface{}{
+		elicitationApproveField: map[string]interface{}{
+			"type":        "boolean",
+			"title":       "Execute this SQL",
+			"description": fmt.Sprintf("Run the %s statement above on %s", req.StatementType, connectionOrDefault(req.Connection)),
+			"default":     false,
+		},
+	}
+	if req.Masked() {
+		properties[elicitationRevealField] = map[string]interface{}{
+			"type":        "boolean",
+			"title":       "Reveal masked values",
+			"description": "Show this review again with the SQL as it runs (leave Execute unchecked)",
+			"default":     false,
+		}
+	}
+	params := elicitationParams{
+		Message: confirm.ReviewMessage(req),
+		RequestedSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			"required":   []string{elicitationApproveField},
+		},
+	}
+
+	raw, err := c.server.callClient(ctx, "elicitation/create", params)
+	if err != nil {
+		return false, err
+	}
+	var result elicitationResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return false, fmt.Errorf("invalid elicitation/create result: %w", err)
+	}
+
+	switch result.Action {
+	case "accept":
+		approved, _ := result.Content[elicitationApproveField].(bool)
+		if reveal, _ := result.Content[elicitationRevealField].(bool); reveal && !approved && req.Masked() {
+			return c.Confirm(ctx, req.Revealed())
+		}
+		return approved, nil
+	case "decline", "cancel":
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected elicitation action %q", result.Action)
+	}
+}
+
+// connectionOrDefault returns the connection alias for display, or "default".
+func connectionOrDefault(name string) string {
+	if name == "" {
+		return "default"
+	}
+	return name
+}
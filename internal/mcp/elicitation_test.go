@@ -0,0 +1,235 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alvin/oracle-mcp-server/internal/config"
+	"github.com/alvin/oracle-mcp-server/internal/confirm"
+)
+
+func elicitationMode(cfg *config.Config) { cfg.Security.ConfirmMode = "elicitation" }
+
+const initializeWithElicitation = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{"elicitation":{}},"clientInfo":{"name":"test","version":"0"}}}`
+
+func TestElicitation_Answers(t *testing.T) {
+	tests := []struct {
+		name       string
+		answer     string
+		wantAction string
+	}{
+		{"accept with execute", `{"action":"accept","content":{"execute":true}}`, "EXECUTION_ERROR"},
+		{"accept without execute", `{"action":"accept","content":{"execute":false}}`, "USER_REJECTED"},
+		{"decline", `{"action":"decline"}`, "USER_REJECTED"},
+		{"cancel", `{"action":"cancel"}`, "USER_REJECTED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallback := confirm.NewScripted()
+			ts := newTestServer(t, fallback, elicitationMode)
+
+			resps := ts.send(t,
+				initializeWithElicitation,
+				toolCall(2, "execute_sql", map[string]interface{}{"sql": "DROP TABLE t"}),
+				`{"jsonrpc":"2.0","id":"oracle-mcp-1","result":`+tt.answer+`}`,
+			)
+			if len(resps) != 3 {
+				t.Fatalf("got %d messages, want 3: %v", len(resps), resps)
+			}
+			if v := resps[0]["result"].(map[string]interface{})["protocolVersion"]; v != "2025-06-18" {
+				t.Errorf("protocolVersion = %v, want 2025-06-18", v)
+			}
+			elicit := resps[1]
+			if elicit["method"] != "elicitation/create" || elicit["id"] != "oracle-mcp-1" {
+				t.Fatalf("second message = %v, want elicitation/create request", elicit)
+			}
+			msg := elicit["params"].(map[string]interface{})["message"].(string)
+			for _, want := range []string{"Database: db1", "DROP TABLE t", "Statement Type: DROP", "Matched Keywords: DROP"} {
+				if !strings.Contains(msg, want) {
+					t.Errorf("elicitation message missing %q:\n%s", want, msg)
+				}
+			}
+			if len(fallback.Requests()) != 0 {
+				t.Error("fallback confirmer must not be used when the client supports elicitation")
+			}
+			entries := ts.auditEntries(t)
+			if len(entries) != 1 || !strings.HasPrefix(entries[0]["AUDIT_ACTION"], tt.wantAction) {
+				t.Errorf("audit entries = %v, want action %s", entries, tt.wantAction)
+			}
+		})
+	}
+}
+
+func TestElicitation_ClientError(t *testing.T) {
+	ts := newTestServer(t, confirm.NewScripted(), elicitationMode)
+
+	resps := ts.send(t,
+		initializeWithElicitation,
+		toolCall(2, "execute_sql", map[string]interface{}{"sql": "DROP TABLE t"}),
+		`{"jsonrpc":"2.0","id":"oracle-mcp-1","error":{"code":-32601,"message":"Method not found"}}`,
+	)
+	text, isError := toolText(t, resps[2])
+	if !isError || !strings.Contains(text, "elicitation/create failed: Method not found") {
+		t.Errorf("result = %q (isError=%v)", text, isError)
+	}
+	if entries := ts.auditEntries(t); len(entries) != 1 || !strings.HasPrefix(entries[0]["AUDIT_ACTION"], "CONFIRM_ERROR") {
+		t.Errorf("audit entries = %v", entries)
+	}
+}
+
+func TestElicitation_RequestsWhileWaiting(t *testing.T) {
+	ts := newTestServer(t, confirm.NewScripted(), elicitationMode)
+
+	resps := ts.send(t,
+		initializeWithElicitation,
+		toolCall(2, "execute_sql", map[string]interface{}{"sql": "DROP TABLE t"}),
+		`{"jsonrpc":"2.0","id":3,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":"oracle-mcp-1","result":{"action":"decline"}}`,
+	)
+	if len(resps) != 4 {
+		t.Fatalf("got %d messages, want 4: %v", len(resps), resps)
+	}
+	if resps[2]["id"].(float64) != 3 {
+		t.Errorf("ping must be answered while the elicitation is pending, got %v", resps[2])
+	}
+	if resps[3]["error"].(map[string]interface{})["code"].(float64) != ErrCodeUserRejected {
+		t.Errorf("final response = %v, want USER_REJECTED", resps[3])
+	}
+}
+
+func TestElicitation_FallbackWithoutCapability(t *testing.T) {
+	fallback := confirm.AlwaysReject()
+	ts := newTestServer(t, fallback, elicitationMode)
+
+	resps := ts.send(t,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"0"}}}`,
+		toolCall(2, "execute_sql", map[string]interface{}{"sql": "DROP TABLE t"}),
+	)
+	if len(resps) != 2 {
+		t.Fatalf("got %d messages, want 2 (no elicitation request): %v", len(resps), resps)
+	}
+	if len(fallback.Requests()) != 1 {
+		t.Errorf("fallback saw %d requests, want 1", len(fallback.Requests()))
+	}
+}
+
+func TestElicitation_PendingReviewDoesNotHoldWorker(t *testing.T) {
+	ts := newTestServer(t, confirm.NewScripted(), elicitationMode)
+	ts.workers = make(chan struct{}, 1)
+
+	resps := ts.send(t,
+		initializeWithElicitation,
+		toolCall(2, "execute_sql", map[string]interface{}{"sql": "DROP TABLE t"}),
+		toolCall(3, "list_connections", nil),
+		`{"jsonrpc":"2.0","id":"oracle-mcp-1","result":{"action":"decline"}}`,
+	)
+	if len(resps) != 4 {
+		t.Fatalf("got %d messages, want 4: %v", len(resps), resps)
+	}
+	if resps[2]["id"].(float64) != 3 {
+		t.Errorf("list_connections must be answered while the review is pending, got %v", resps[2])
+	}
+	if resps[3]["id"].(float64) != 2 || resps[3]["error"].(map[string]interface{})["code"].(float64) != ErrCodeUserRejected {
+		t.Errorf("final response = %v, want USER_REJECTED for id 2", resps[3])
+	}
+}
+
+func TestElicitation_Reveal(t *testing.T) {
+	ts := newTestServer(t, confirm.NewScripted(), elicitationMode)
+
+	resps := ts.send(t,
+		initializeWithElicitation,
+		toolCall(2, "execute_sql", map[string]interface{}{"sql": "CREATE USER app IDENTIFIED BY s3cret"}),
+		`{"jsonrpc":"2.0","id":"oracle-mcp-1","result":{"action":"accept","content":{"execute":false,"reveal":true}}}`,
+		`{"jsonrpc":"2.0","id":"oracle-mcp-2","result":{"action":"decline"}}`,
+	)
+	if len(resps) != 4 {
+		t.Fatalf("got %d messages, want 4: %v", len(resps), resps)
+	}
+	masked := resps[1]["params"].(map[string]interface{})
+	if msg := masked["message"].(string); strings.Contains(msg, "s3cret") || !strings.Contains(msg, "IDENTIFIED BY ***") {
+		t.Errorf("first review is not masked:\n%s", msg)
+	}
+	if props := masked["requestedSchema"].(map[string]interface{})["properties"].(map[string]interface{}); props["reveal"] == nil {
+		t.Error("masked review does not offer reveal")
+	}
+	revealed := resps[2]["params"].(map[string]interface{})
+	if msg := revealed["message"].(string); !strings.Contains(msg, "IDENTIFIED BY s3cret") {
+		t.Errorf("second review does not reveal the SQL:\n%s", msg)
+	}
+	if props := revealed["requestedSchema"].(map[string]interface{})["properties"].(map[string]interface{}); props["reveal"] != nil {
+		t.Error("revealed review still offers reveal")
+	}
+	if entries := ts.auditEntries(t); len(entries) != 1 || entries[0]["AUDIT_ACTION"] != "USER_REJECTED" || strings.Contains(entries[0]["AUDIT_SQL"], "s3cret") {
+		t.Errorf("audit entries = %v", entries)
+	}
+}
+
+func TestElicitation_InitializeAgainWhileCallsRun(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysReject(), elicitationMode)
+	initialize := &jsonRPCRequest{JSONRPC: "2.0", ID: 1, Method: "initialize", Params: json.RawMessage(`{"protocolVersion":"2025-06-18","capabilities":{"elicitation":{}},"clientInfo":{"name":"test","version":"0"}}`)}
+
+	// Run with -race: the audit log reads the client info while initialize replaces it
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			ts.logAudit(context.Background(), "SELECT 1 FROM dual", nil, nil, true, "SUCCESS", "db1")
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		ts.handleInitialize(initialize)
+	}
+	<-done
+}
+
+func TestElicitation_CancelledWhileReviewPending(t *testing.T) {
+	ts := newTestServer(t, confirm.NewScripted(), elicitationMode)
+
+	resps := ts.send(t,
+		initializeWithElicitation,
+		toolCall(2, "execute_sql", map[string]interface{}{"sql": "DROP TABLE t"}),
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":2,"reason":"user pressed stop"}}`,
+		toolCall(3, "execute_sql", map[string]interface{}{"sql": "DROP TABLE u"}),
+		`{"jsonrpc":"2.0","id":"oracle-mcp-2","result":{"action":"decline"}}`,
+	)
+	if len(resps) != 6 {
+		t.Fatalf("got %d messages, want 6: %v", len(resps), resps)
+	}
+	// Call 2 may answer before or after the review of call 3 opens
+	byKey := map[string]map[string]interface{}{}
+	for _, r := range resps {
+		byKey[fmt.Sprintf("%v/%v", r["id"], r["method"])] = r
+	}
+	withdrawn := byKey["<nil>/notifications/cancelled"]
+	if withdrawn == nil || withdrawn["params"].(map[string]interface{})["requestId"] != "oracle-mcp-1" {
+		t.Errorf("messages = %v, want notifications/cancelled for oracle-mcp-1", resps)
+	}
+	if r := byKey["2/<nil>"]; r == nil || r["error"].(map[string]interface{})["code"].(float64) != ErrCodeRequestCancelled {
+		t.Errorf("response to 2 = %v, want REQUEST_CANCELLED", r)
+	}
+	// The next review is shown: the cancelled one no longer holds the review slot
+	if byKey["oracle-mcp-2/elicitation/create"] == nil {
+		t.Errorf("messages = %v, want elicitation/create for call 3", resps)
+	}
+	if r := byKey["3/<nil>"]; r == nil || r["error"].(map[string]interface{})["code"].(float64) != ErrCodeUserRejected {
+		t.Errorf("response to 3 = %v, want USER_REJECTED", r)
+	}
+	ts.pendingMu.Lock()
+	pending := len(ts.pending)
+	ts.pendingMu.Unlock()
+	if pending != 0 {
+		t.Errorf("%d client requests still pending", pending)
+	}
+	entries := ts.auditEntries(t)
+	if len(entries) != 2 || entries[0]["AUDIT_ACTION"] != "CANCELLED: user pressed stop" || entries[1]["AUDIT_ACTION"] != "USER_REJECTED" {
+		t.Fatalf("audit entries = %v", entries)
+	}
+	// Cancelled before anyone approved it
+	if entries[0]["AUDIT_APPROVED"] != "false" {
+		t.Errorf("cancelled review audited as approved: %v", entries[0])
+	}
+}
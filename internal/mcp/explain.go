@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/alvin/oracle-mcp-server/internal/confirm"
+	"github.com/alvin/oracle-mcp-server/internal/oracle"
+	"github.com/alvin/oracle-mcp-server/internal/policy"
+	"github.com/alvin/oracle-mcp-server/internal/sqlanalyzer"
+)
+
+// explainPlanTool is the explain_plan tool listed by tools/list.
+func explainPlanTool() tool {
+	return tool{
+		Name: "explain_plan",
+		Description: "Show the execution plan of one SQL statement: the DBMS_XPLAN text (plan_text) and the operation tree (plan) with cost, estimated rows (cardinality), " +
+			"bytes, access and filter predicates. mode estimate (default) uses EXPLAIN PLAN and never runs the statement. mode actual runs a query to the end " +
+			"(rows are discarded) with row source statistics and adds actual_rows, starts, elapsed_us and buffer_gets per operation; it needs SELECT on V$SESSION and V$SQL_PLAN_STATISTICS_ALL.",
+		InputSchema: inputSchema{
+			Type: "object",
+			Properties: map[string]property{
+				"sql":  {Type: "string", Description: "One statement (SELECT, INSERT, UPDATE, DELETE or MERGE; only queries in actual mode). An EXPLAIN PLAN ... FOR prefix is ignored."},
+				"mode": {Type: "string", Description: "estimate (default): EXPLAIN PLAN, the statement is not executed. actual: run the query and report estimated vs actual rows."},
+				"binds": {
+					Type:        []string{"object", "array"},
+					Description: "actual mode: " + bindsDescription,
+				},
+				"connection":      connectionProperty,
+				"timeout_seconds": {Type: "integer", Description: timeoutDescription},
+			},
+			Required: []string{"sql"},
+		},
+	}
+}
+
+// handleExplainPlan handles the explain_plan tool. Estimates need no review; an actual run of a query that matches
+// danger_keywords is reviewed like execute_sql.
+func (s *Server) handleExplainPlan(ctx context.Context, req *jsonRPCRequest, args map[string]interface{}) {
+	sqlStr := oracle.ExplainTarget(stringArg(args, "sql"))
+	if sqlStr == "" {
+		s.sendToolError(req.ID, "Missing required parameter: sql")
+		return
+	}
+	mode := stringArg(args, "mode")
+	if mode == "" {
+		mode = "estimate"
+	}
+	if mode != "estimate" && mode != "actual" {
+		s.sendToolError(req.ID, "Parameter 'mode' must be estimate or actual")
+		return
+	}
+	actual := mode == "actual"
+	analysis := s.analyzer.Analyze(sqlStr)
+	if analysis.IsMultiStatement || analysis.ContainsPLSQL {
+		s.sendToolError(req.ID, "explain_plan takes a single SQL statement (no PL/SQL blocks)")
+		return
+	}
+	stmtType := sqlanalyzer.GetStatementType(sqlStr)
+	if actual && stmtType != "SELECT" && stmtType != "WITH" {
+		s.sendToolError(req.ID, "mode actual runs the statement, so only queries are allowed; use mode estimate for "+stmtType)
+		return
+	}
+	binds, err := oracle.ParseBinds(args["binds"])
+	if err != nil {
+		s.sendToolError(req.ID, fmt.Sprintf("Invalid binds: %v", err))
+		return
+	}
+	if binds != nil && !actual {
+		s.sendToolError(req.ID, "binds are only used in mode actual; EXPLAIN PLAN takes the bind placeholders as they are")
+		return
+	}
+	bindLines := oracle.FormatBinds(binds)
+	connectionName, displayConnection := s.connectionArg(args)
+	timeout, err := s.timeoutArg(args, connectionName)
+	if err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
+
+	action := "EXPLAIN_PLAN"
+	var decision policy.Decision
+	if actual {
+		action = "EXPLAIN_PLAN_ACTUAL"
+		// The query runs, so it goes through the policy like execute_sql
+		decision = s.policy.Evaluate(sqlStr, displayConnection)
+		if !s.applyPolicy(ctx, req.ID, decision, &confirm.ConfirmRequest{
+			SQL:             sqlStr,
+			MatchedKeywords: analysis.MatchedKeywords,
+			StatementType:   stmtType,
+			Connection:      displayConnection,
+			ConnectionIndex: connectionIndexInPool(s.executorPool, displayConnection),
+			Binds:           bindLines,
+			Transaction:     s.transactionLabel(connectionName, false),
+		}) {
+			return
+		}
+	}
+
+	result, err := s.executorPool.ExplainPlan(ctx, connectionName, sqlStr, actual, oracle.ExecOptions{Binds: binds, Timeout: timeout, Session: s.session})
+	if err != nil {
+		if s.reportInterrupted(ctx, req.ID, err, true, sqlStr, analysis.MatchedKeywords, bindLines, displayConnection) {
+			return
+		}
+		s.logAuditRule(ctx, sqlStr, analysis.MatchedKeywords, bindLines, actual, action+"_ERROR: "+err.Error(), displayConnection, decision.Rule)
+		s.sendToolError(req.ID, "explain_plan failed: "+err.Error())
+		return
+	}
+	s.logAuditRule(ctx, sqlStr, analysis.MatchedKeywords, bindLines, true, action, displayConnection, decision.Rule)
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	s.sendToolResult(req.ID, string(resultJSON))
+}
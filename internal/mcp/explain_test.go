@@ -0,0 +1,40 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alvin/oracle-mcp-server/internal/confirm"
+)
+
+func TestExplainPlan_WithoutDatabase(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysApprove())
+
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		wantText string
+	}{
+		{"estimate", map[string]interface{}{"sql": "EXPLAIN PLAN FOR DELETE FROM emp WHERE id = :id;"}, `explain_plan failed: connection "db1" is currently unavailable`},
+		{"actual", map[string]interface{}{"sql": "SELECT * FROM emp WHERE id = :id", "mode": "actual", "binds": map[string]interface{}{"id": 7}}, `explain_plan failed: connection "db1" is currently unavailable`},
+		{"missing sql", map[string]interface{}{"sql": " ; "}, "Missing required parameter: sql"},
+		{"bad mode", map[string]interface{}{"sql": "SELECT 1 FROM dual", "mode": "fast"}, "Parameter 'mode' must be estimate or actual"},
+		{"multiple statements", map[string]interface{}{"sql": "SELECT 1 FROM dual;\nSELECT 2 FROM dual;"}, "explain_plan takes a single SQL statement"},
+		{"actual dml", map[string]interface{}{"sql": "DELETE FROM emp", "mode": "actual"}, "mode actual runs the statement, so only queries are allowed"},
+		{"estimate binds", map[string]interface{}{"sql": "SELECT * FROM emp WHERE id = :id", "binds": map[string]interface{}{"id": 7}}, "binds are only used in mode actual"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resps := ts.send(t, toolCall(1, "explain_plan", tt.args))
+			text, isError := toolText(t, resps[0])
+			if !isError || !strings.HasPrefix(text, tt.wantText) {
+				t.Errorf("result = %q (isError=%v), want prefix %q", text, isError, tt.wantText)
+			}
+		})
+	}
+	entries := ts.auditEntries(t)
+	if len(entries) != 2 || !strings.HasPrefix(entries[0]["AUDIT_ACTION"], "EXPLAIN_PLAN_ERROR: ") ||
+		!strings.HasPrefix(entries[1]["AUDIT_ACTION"], "EXPLAIN_PLAN_ACTUAL_ERROR: ") || entries[0]["AUDIT_SQL"] != "DELETE FROM emp WHERE id = :id" {
+		t.Errorf("audit = %v, want the two failed runs", entries)
+	}
+}
@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxRowsDescription documents the optional "max_rows" argument of execute_sql and execute_sql_file.
+const maxRowsDescription = "Optional row limit for query results (default: config oracle.max_rows; 0 = no limit). When more rows remain the result has truncated: true and a result_handle for fetch_more."
+
+// handleFetchMore handles the fetch_more tool: the next page of a truncated query result.
+func (s *Server) handleFetchMore(ctx context.Context, req *jsonRPCRequest, args map[string]interface{}) {
+	handle, ok := args["result_handle"].(string)
+	if !ok || strings.TrimSpace(handle) == "" {
+		s.sendToolError(req.ID, "Missing required parameter: result_handle")
+		return
+	}
+	maxRows, err := s.maxRowsArg(args)
+	if err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
+
+	timeout, err := s.timeoutArg(args, "")
+	if err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
+
+	result, err := s.executorPool.FetchMore(ctx, s.session, strings.TrimSpace(handle), maxRows, timeout)
+	if err != nil {
+		if s.reportInterrupted(ctx, req.ID, err, true, "FETCH "+strings.TrimSpace(handle), nil, nil, "") {
+			return
+		}
+		s.sendToolError(req.ID, "fetch_more failed: "+err.Error())
+		return
+	}
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	s.sendToolResult(req.ID, string(resultJSON))
+}
+
+// maxRowsArg returns the optional "max_rows" argument, or the configured oracle.max_rows when it is omitted.
+func (s *Server) maxRowsArg(args map[string]interface{}) (int, error) {
+	v, ok := args["max_rows"]
+	if !ok || v == nil {
+		return s.config.Oracle.MaxRows, nil
+	}
+	n, ok := v.(float64)
+	if !ok || n < 0 || n != float64(int(n)) {
+		return 0, fmt.Errorf("Parameter 'max_rows' must be a non-negative integer")
+	}
+	return int(n), nil
+}
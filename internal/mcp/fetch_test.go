@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alvin/oracle-mcp-server/internal/config"
+	"github.com/alvin/oracle-mcp-server/internal/confirm"
+)
+
+func TestFetchMore_Arguments(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysApprove())
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{"missing handle", map[string]interface{}{}, "Missing required parameter: result_handle"},
+		{"negative max_rows", map[string]interface{}{"result_handle": "rh_x", "max_rows": -1}, "Parameter 'max_rows' must be a non-negative integer"},
+		{"fractional max_rows", map[string]interface{}{"result_handle": "rh_x", "max_rows": 1.5}, "Parameter 'max_rows' must be a non-negative integer"},
+		{"unknown handle", map[string]interface{}{"result_handle": "rh_x"}, `fetch_more failed: unknown or expired result handle "rh_x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resps := ts.send(t, toolCall(1, "fetch_more", tt.args))
+			text, isError := toolText(t, resps[0])
+			if !isError || !strings.HasPrefix(text, tt.want) {
+				t.Errorf("result = %q (isError=%v), want prefix %q", text, isError, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxRowsArg(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysApprove(), func(cfg *config.Config) { cfg.Oracle.MaxRows = 50 })
+
+	for _, tt := range []struct {
+		args map[string]interface{}
+		want int
+	}{
+		{map[string]interface{}{}, 50},
+		{map[string]interface{}{"max_rows": nil}, 50},
+		{map[string]interface{}{"max_rows": float64(10)}, 10},
+		{map[string]interface{}{"max_rows": float64(0)}, 0},
+	} {
+		got, err := ts.maxRowsArg(tt.args)
+		if err != nil || got != tt.want {
+			t.Errorf("maxRowsArg(%v) = %d, %v; want %d", tt.args, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,676 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTP transport endpoints. /mcp is MCP Streamable HTTP (2025-03-26 and later); /sse and /messages are the
+// HTTP+SSE transport of 2024-11-05 for clients that do not speak Streamable HTTP yet.
+const (
+	streamableEndpoint = "/mcp"
+	sseEndpoint        = "/sse"
+	messagesEndpoint   = "/messages"
+	sessionHeader      = "Mcp-Session-Id"
+
+	maxHTTPMessageBytes = 10 << 20
+	sseKeepAlive        = 25 * time.Second
+	// sessionQueueLimit caps server messages held for a session that has no open stream; the oldest are dropped.
+	sessionQueueLimit = 100
+)
+
+// errClientGone cancels a call whose POST ended before its result: results are not kept for a later stream.
+var errClientGone = errors.New("client closed the HTTP request")
+
+// RunHTTP serves MCP over HTTP on server.listen until ctx is cancelled. Every client session gets its own
+// protocol state, pending elicitations and transactions; connections, the audit log and the call workers are shared.
+func (s *Server) RunHTTP(ctx context.Context) error {
+	defer s.Close()
+
+	cfg := s.config.Server
+	h := newHTTPHandler(s)
+	if s.config.Security.ConfirmMode != "elicitation" {
+		fmt.Fprintf(os.Stderr, "Warning: security.confirm_mode is %q; review windows open on the server host, not in the clients. Use \"elicitation\" for a shared server.\n", s.config.Security.ConfirmMode)
+	}
+
+	httpServer := &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           h,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		// Streams never finish on their own: end the sessions before waiting for the handlers
+		h.closeAllSessions()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+	go h.expireSessions(ctx)
+
+	fmt.Fprintf(os.Stderr, "oracle-mcp: serving MCP on %s (Streamable HTTP %s, HTTP+SSE %s)\n", cfg.Listen, streamableEndpoint, sseEndpoint)
+	var err error
+	if cfg.TLSCertFile != "" {
+		err = httpServer.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+	} else {
+		err = httpServer.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	h.closeAllSessions()
+	return err
+}
+
+// httpHandler authenticates HTTP requests and routes them to client sessions.
+type httpHandler struct {
+	server         *Server // owns the shared resources; sessions are created from it
+	tokens         []httpToken
+	allowedOrigins map[string]bool
+	idleTimeout    time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+func newHTTPHandler(s *Server) *httpHandler {
+	h := &httpHandler{
+		server:         s,
+		allowedOrigins: make(map[string]bool),
+		idleTimeout:    time.Duration(s.config.Server.SessionIdleTimeoutSeconds) * time.Second,
+		sessions:       make(map[string]*httpSession),
+	}
+	for _, token := range s.config.Server.AuthTokens {
+		h.tokens = append(h.tokens, httpToken{name: strings.TrimSpace(token.Name), token: []byte(strings.TrimSpace(token.Token))})
+	}
+	for _, origin := range s.config.Server.AllowedOrigins {
+		h.allowedOrigins[strings.TrimRight(origin, "/")] = true
+	}
+	s.executorPool.OnIdleRollback = h.onIdleRollback
+	if s.auditor != nil {
+		s.auditor.SetErrorHandler(h.onAuditError)
+	}
+	return h
+}
+
+// httpToken is a configured bearer token and its name (server.auth_tokens).
+type httpToken struct {
+	name  string
+	token []byte
+}
+
+// ServeHTTP checks the bearer token and Origin, then routes to the transport endpoints.
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.originAllowed(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	token, ok := h.authorized(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="oracle-mcp"`)
+		http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.URL.Path == streamableEndpoint && r.Method == http.MethodPost:
+		h.handlePost(w, r, token)
+	case r.URL.Path == streamableEndpoint && r.Method == http.MethodGet:
+		h.handleStandaloneStream(w, r, token)
+	case r.URL.Path == streamableEndpoint && r.Method == http.MethodDelete:
+		h.handleDelete(w, r, token)
+	case r.URL.Path == sseEndpoint && r.Method == http.MethodGet:
+		h.handleSSE(w, r, token)
+	case r.URL.Path == messagesEndpoint && r.Method == http.MethodPost:
+		h.handleMessages(w, r, token)
+	case r.URL.Path == streamableEndpoint || r.URL.Path == sseEndpoint || r.URL.Path == messagesEndpoint:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// authorized returns the name of the configured bearer token the request carries; ok is false when it has none.
+func (h *httpHandler) authorized(r *http.Request) (name string, ok bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	got := []byte(strings.TrimSpace(token))
+	for _, want := range h.tokens {
+		if subtle.ConstantTimeCompare(got, want.token) == 1 {
+			return want.name, true
+		}
+	}
+	return "", false
+}
+
+// originAllowed rejects browser requests from other sites (DNS rebinding): an Origin header, when present, must
+// match the requested host or be listed in server.allowed_origins.
+func (h *httpHandler) originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if h.allowedOrigins[strings.TrimRight(origin, "/")] {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// handlePost handles a client message on the Streamable HTTP endpoint. Requests are answered on this HTTP
+// response: as an SSE stream when the client accepts one (server requests and notifications can then arrive
+// before the result), otherwise as a single JSON body. Notifications and responses get 202 Accepted.
+func (h *httpHandler) handlePost(w http.ResponseWriter, r *http.Request, token string) {
+	req, ok := readHTTPMessage(w, r)
+	if !ok {
+		return
+	}
+
+	var sess *httpSession
+	if req.Method == "initialize" {
+		sess = h.newSession(false, token)
+		w.Header().Set(sessionHeader, sess.id)
+	} else if sess = h.sessionFor(w, r.Header.Get(sessionHeader), false, token); sess == nil {
+		return
+	}
+	sess.touch()
+
+	if req.ID == nil || req.Method == "" {
+		sess.srv.dispatch(req)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	key := fmt.Sprint(req.ID)
+	response := sess.await(key)
+	defer sess.stopAwaiting(key, response)
+
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		sess.srv.dispatch(req)
+		select {
+		case msg := <-response:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(msg)
+		case <-r.Context().Done():
+			// Nobody is left to receive the result: end the call, and a review it is waiting for
+			sess.srv.cancelRequest(key, errClientGone)
+		case <-sess.closed:
+			http.Error(w, "session closed", http.StatusNotFound)
+		}
+		return
+	}
+
+	stream, ok := newSSEStream(w)
+	if !ok {
+		return
+	}
+	sess.addStream(stream, false)
+	defer sess.removeStream(stream)
+	sess.srv.dispatch(req)
+	for {
+		select {
+		case msg := <-stream.msgs:
+			stream.write("message", msg)
+		case msg := <-response:
+			// Messages sent before the result go out first
+			for drained := false; !drained; {
+				select {
+				case m := <-stream.msgs:
+					stream.write("message", m)
+				default:
+					drained = true
+				}
+			}
+			stream.write("message", msg)
+			return
+		case <-stream.keepAlive.C:
+			stream.comment("keepalive")
+		case <-r.Context().Done():
+			sess.srv.cancelRequest(key, errClientGone)
+			return
+		case <-sess.closed:
+			return
+		}
+	}
+}
+
+// handleStandaloneStream opens the session's stream for server requests and notifications that are not part of
+// a POST response (GET /mcp).
+func (h *httpHandler) handleStandaloneStream(w http.ResponseWriter, r *http.Request, token string) {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		http.Error(w, "GET requires Accept: text/event-stream", http.StatusNotAcceptable)
+		return
+	}
+	sess := h.sessionFor(w, r.Header.Get(sessionHeader), false, token)
+	if sess == nil {
+		return
+	}
+	stream, ok := newSSEStream(w)
+	if !ok {
+		return
+	}
+	if !sess.addStream(stream, true) {
+		http.Error(w, "a stream is already open for this session", http.StatusConflict)
+		return
+	}
+	defer sess.removeStream(stream)
+	stream.pump(r.Context(), sess.closed)
+}
+
+// handleDelete ends a session at the client's request (DELETE /mcp).
+func (h *httpHandler) handleDelete(w http.ResponseWriter, r *http.Request, token string) {
+	sess := h.sessionFor(w, r.Header.Get(sessionHeader), false, token)
+	if sess == nil {
+		return
+	}
+	h.closeSession(sess)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSSE opens an HTTP+SSE session (GET /sse): the first event names the endpoint for the client's messages,
+// and every message from the server, results included, is sent on this stream. The session ends with the stream.
+func (h *httpHandler) handleSSE(w http.ResponseWriter, r *http.Request, token string) {
+	stream, ok := newSSEStream(w)
+	if !ok {
+		return
+	}
+	sess := h.newSession(true, token)
+	defer h.closeSession(sess)
+	sess.addStream(stream, true)
+	stream.write("endpoint", []byte(messagesEndpoint+"?sessionId="+sess.id))
+	stream.pump(r.Context(), sess.closed)
+}
+
+// handleMessages accepts a client message for an HTTP+SSE session (POST /messages?sessionId=...).
+func (h *httpHandler) handleMessages(w http.ResponseWriter, r *http.Request, token string) {
+	sess := h.sessionFor(w, r.URL.Query().Get("sessionId"), true, token)
+	if sess == nil {
+		return
+	}
+	req, ok := readHTTPMessage(w, r)
+	if !ok {
+		return
+	}
+	sess.touch()
+	sess.srv.dispatch(req)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// readHTTPMessage decodes one JSON-RPC message from the request body, answering parse errors itself.
+func readHTTPMessage(w http.ResponseWriter, r *http.Request) (*jsonRPCRequest, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHTTPMessageBytes))
+	if err != nil {
+		http.Error(w, "cannot read request body: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	var req jsonRPCRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(jsonRPCResponse{
+			JSONRPC: "2.0",
+			Error:   &rpcError{Code: ErrCodeParseError, Message: "Parse error (send one JSON-RPC message per request)"},
+		})
+		return nil, false
+	}
+	return &req, true
+}
+
+// newSession starts a client session with its own Server state, opened with the named bearer token.
+func (h *httpHandler) newSession(legacy bool, token string) *httpSession {
+	b := make([]byte, 16)
+	rand.Read(b)
+	sess := &httpSession{
+		id:       hex.EncodeToString(b),
+		token:    token,
+		legacy:   legacy,
+		waiters:  make(map[string]chan []byte),
+		lastUsed: time.Now(),
+		closed:   make(chan struct{}),
+	}
+	sess.srv = h.server.newSession(sess.id, token, sess)
+	h.mu.Lock()
+	h.sessions[sess.id] = sess
+	h.mu.Unlock()
+	fmt.Fprintf(os.Stderr, "oracle-mcp: session %s opened (token %s)\n", sess.id, token)
+	return sess
+}
+
+// sessionFor returns the session with the given id, answering with 400 (no id) or 404 (unknown or ended:
+// the client must initialize again) when there is none. A session opened with another token is unknown.
+func (h *httpHandler) sessionFor(w http.ResponseWriter, id string, legacy bool, token string) *httpSession {
+	if id == "" {
+		http.Error(w, "missing "+sessionHeader+" header; send initialize first", http.StatusBadRequest)
+		return nil
+	}
+	h.mu.Lock()
+	sess := h.sessions[id]
+	h.mu.Unlock()
+	if sess == nil || sess.legacy != legacy || sess.token != token {
+		http.Error(w, "unknown or expired session", http.StatusNotFound)
+		return nil
+	}
+	return sess
+}
+
+// closeSession ends sess: streams close, calls still running are cancelled, and once they have finished the
+// session's open transactions are rolled back.
+func (h *httpHandler) closeSession(sess *httpSession) {
+	h.mu.Lock()
+	if h.sessions[sess.id] != sess {
+		h.mu.Unlock()
+		return
+	}
+	delete(h.sessions, sess.id)
+	h.mu.Unlock()
+
+	close(sess.closed)
+	sess.srv.closeInput()
+	sess.srv.cancelAll(errors.New("session closed"))
+	go func() {
+		sess.srv.calls.Wait()
+		h.server.executorPool.EndSession(sess.id)
+	}()
+	fmt.Fprintf(os.Stderr, "oracle-mcp: session %s closed\n", sess.id)
+}
+
+// closeAllSessions ends every session (shutdown).
+func (h *httpHandler) closeAllSessions() {
+	h.mu.Lock()
+	all := make([]*httpSession, 0, len(h.sessions))
+	for _, sess := range h.sessions {
+		all = append(all, sess)
+	}
+	h.mu.Unlock()
+	for _, sess := range all {
+		h.closeSession(sess)
+	}
+}
+
+// expireSessions closes sessions that had no requests and no open stream for server.session_idle_timeout_seconds.
+func (h *httpHandler) expireSessions(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		h.mu.Lock()
+		var idle []*httpSession
+		for _, sess := range h.sessions {
+			if sess.idleFor() > h.idleTimeout {
+				idle = append(idle, sess)
+			}
+		}
+		h.mu.Unlock()
+		for _, sess := range idle {
+			h.closeSession(sess)
+		}
+	}
+}
+
+// onAuditError reports an audit log failure on stderr and to every session, since they share the log.
+func (h *httpHandler) onAuditError(err error) {
+	fmt.Fprintf(os.Stderr, "oracle-mcp: %v\n", err)
+	h.mu.Lock()
+	sessions := make([]*httpSession, 0, len(h.sessions))
+	for _, sess := range h.sessions {
+		sessions = append(sessions, sess)
+	}
+	h.mu.Unlock()
+	for _, sess := range sessions {
+		sess.srv.sendLogNotification("error", "Audit log: "+err.Error())
+	}
+}
+
+// onIdleRollback tells the session that owned the transaction; when it has already ended, the rollback is only audited.
+func (h *httpHandler) onIdleRollback(session string, connection string) {
+	h.mu.Lock()
+	sess := h.sessions[session]
+	h.mu.Unlock()
+	if sess == nil {
+		h.server.logAudit(context.Background(), "ROLLBACK", nil, nil, true, "TRANSACTION_IDLE_ROLLBACK", connection)
+		return
+	}
+	sess.srv.onIdleRollback(connection)
+}
+
+// httpSession is one client's MCP session on the HTTP transport. It is the io.Writer of its Server and routes
+// each message: a result goes to the POST waiting for it, anything else to an open stream.
+type httpSession struct {
+	id     string
+	token  string // name of the bearer token that opened the session
+	srv    *Server
+	legacy bool // HTTP+SSE: every message, results included, goes to the one stream
+	closed chan struct{}
+
+	mu         sync.Mutex
+	waiters    map[string]chan []byte // request id -> result for the POST waiting on it
+	standalone *sseStream             // GET /mcp stream, or the /sse stream
+	posts      []*sseStream           // POST response streams, oldest first
+	queue      [][]byte               // messages waiting for a stream to open
+	lastUsed   time.Time
+}
+
+// Write routes one message written by the session's Server.
+func (sess *httpSession) Write(p []byte) (int, error) {
+	msg := bytes.TrimSpace(append([]byte(nil), p...))
+	var head struct {
+		ID     interface{} `json:"id"`
+		Method string      `json:"method"`
+	}
+	json.Unmarshal(msg, &head)
+	isResult := head.Method == "" && head.ID != nil
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if isResult && !sess.legacy {
+		if ch, ok := sess.waiters[fmt.Sprint(head.ID)]; ok {
+			ch <- msg
+			delete(sess.waiters, fmt.Sprint(head.ID))
+		}
+		// Otherwise the client gave up on the POST; results must not go to other streams
+		return len(p), nil
+	}
+	switch {
+	case sess.standalone != nil:
+		sess.standalone.send(msg)
+	case len(sess.posts) > 0:
+		sess.posts[0].send(msg)
+	default:
+		if len(sess.queue) == sessionQueueLimit {
+			sess.queue = sess.queue[1:]
+		}
+		sess.queue = append(sess.queue, msg)
+	}
+	return len(p), nil
+}
+
+// await registers a POST waiting for the result of request key.
+func (sess *httpSession) await(key string) chan []byte {
+	ch := make(chan []byte, 1)
+	sess.mu.Lock()
+	sess.waiters[key] = ch
+	sess.mu.Unlock()
+	return ch
+}
+
+// stopAwaiting forgets the waiter for key when it is still registered.
+func (sess *httpSession) stopAwaiting(key string, ch chan []byte) {
+	sess.mu.Lock()
+	if sess.waiters[key] == ch {
+		delete(sess.waiters, key)
+	}
+	sess.mu.Unlock()
+}
+
+// addStream registers an open stream and hands it the queued messages. Only one standalone stream may be open.
+func (sess *httpSession) addStream(st *sseStream, standalone bool) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if standalone {
+		if sess.standalone != nil {
+			return false
+		}
+		sess.standalone = st
+	} else {
+		sess.posts = append(sess.posts, st)
+	}
+	for _, msg := range sess.queue {
+		st.send(msg)
+	}
+	sess.queue = nil
+	return true
+}
+
+// removeStream unregisters a closed stream; messages it did not write go back to the session.
+func (sess *httpSession) removeStream(st *sseStream) {
+	sess.mu.Lock()
+	if sess.standalone == st {
+		sess.standalone = nil
+	}
+	for i, other := range sess.posts {
+		if other == st {
+			sess.posts = append(sess.posts[:i], sess.posts[i+1:]...)
+			break
+		}
+	}
+	sess.lastUsed = time.Now()
+	sess.mu.Unlock()
+	st.keepAlive.Stop()
+	for {
+		select {
+		case msg := <-st.msgs:
+			sess.Write(msg)
+		default:
+			return
+		}
+	}
+}
+
+// touch records client activity.
+func (sess *httpSession) touch() {
+	sess.mu.Lock()
+	sess.lastUsed = time.Now()
+	sess.mu.Unlock()
+}
+
+// idleFor is how long the session has had no requests and no open stream (0 while a stream is open).
+func (sess *httpSession) idleFor() time.Duration {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.standalone != nil || len(sess.posts) > 0 {
+		return 0
+	}
+	return time.Since(sess.lastUsed)
+}
+
+// sseStream is an open text/event-stream response. Messages are handed over through msgs and written by the
+// goroutine serving the HTTP request.
+type sseStream struct {
+	w         http.ResponseWriter
+	flusher   http.Flusher
+	msgs      chan []byte
+	keepAlive *time.Ticker
+}
+
+func newSSEStream(w http.ResponseWriter) (*sseStream, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return nil, false
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &sseStream{w: w, flusher: flusher, msgs: make(chan []byte, sessionQueueLimit), keepAlive: time.NewTicker(sseKeepAlive)}, true
+}
+
+// send queues msg for the stream; when the client reads too slowly to keep up, the message is dropped.
+func (st *sseStream) send(msg []byte) {
+	select {
+	case st.msgs <- msg:
+	default:
+		fmt.Fprintf(os.Stderr, "oracle-mcp: stream buffer full, dropping message\n")
+	}
+}
+
+// pump writes queued messages until the client disconnects or the session closes.
+func (st *sseStream) pump(ctx context.Context, closed <-chan struct{}) {
+	for {
+		select {
+		case msg := <-st.msgs:
+			st.write("message", msg)
+		case <-st.keepAlive.C:
+			st.comment("keepalive")
+		case <-ctx.Done():
+			return
+		case <-closed:
+			return
+		}
+	}
+}
+
+func (st *sseStream) write(event string, data []byte) {
+	fmt.Fprintf(st.w, "event: %s\ndata: %s\n\n", event, data)
+	st.flusher.Flush()
+}
+
+func (st *sseStream) comment(text string) {
+	fmt.Fprintf(st.w, ": %s\n\n", text)
+	st.flusher.Flush()
+}
+
+// newSession returns a Server for one HTTP client session that shares s's connections, analyzer, auditor and
+// call workers but has its own protocol state, pending client requests, in-flight calls and transactions.
+func (s *Server) newSession(id string, token string, w io.Writer) *Server {
+	sess := &Server{
+		config:       s.config,
+		executorPool: s.executorPool,
+		analyzer:     s.analyzer,
+		policy:       s.policy,
+		confirmer:    s.confirmer,
+		auditor:      s.auditor,
+		redactor:     s.redactor,
+		writer:       w,
+		session:      id,
+		token:        token,
+		pending:      make(map[string]chan *jsonRPCRequest),
+		inflight:     make(map[string]context.CancelCauseFunc),
+		workers:      s.workers,
+		reviewing:    make(chan struct{}, 1),
+		inputClosed:  make(chan struct{}),
+	}
+	if ec, ok := s.confirmer.(*elicitationConfirmer); ok {
+		sess.confirmer = &elicitationConfirmer{server: sess, fallback: ec.fallback}
+	}
+	return sess
+}
+
+// cancelAll cancels every in-flight call of the session.
+func (s *Server) cancelAll(cause error) {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+	for _, cancel := range s.inflight {
+		cancel(cause)
+	}
+}
@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvin/oracle-mcp-server/internal/confirm"
+	"github.com/alvin/oracle-mcp-server/internal/oracle"
+	"github.com/alvin/oracle-mcp-server/internal/policy"
+	"github.com/alvin/oracle-mcp-server/internal/sqlanalyzer"
+)
+
+// estimateTimeout caps the COUNT(*) queries run before review, so a slow count holds up the dialog at most this long.
+const estimateTimeout = 30 * time.Second
+
+// rowEstimate is the number of rows the UPDATE, DELETE and MERGE statements of a call will change.
+type rowEstimate struct {
+	rows    int64
+	known   bool // false when there is nothing to count, no statement could be counted or a count failed
+	partial bool // some statements were not counted; rows is a lower bound
+}
+
+// estimateAffectedRows counts the rows of each UPDATE, DELETE and MERGE statement in analysis before review, with a
+// COUNT(*) on the same target and condition (sqlanalyzer.DMLScope.CountSQL), and describes the total in
+// req.AffectedRows. It only counts when the call is going to be reviewed. Statements that are not Countable are
+// left out of the total.
+func (s *Server) estimateAffectedRows(ctx context.Context, decision policy.Decision, analysis *sqlanalyzer.AnalysisResult, connectionName string, opts oracle.ExecOptions, req *confirm.ConfirmRequest) rowEstimate {
+	if decision.Action != policy.Confirm || len(analysis.DML) == 0 {
+		return rowEstimate{}
+	}
+	ctx, cancel := context.WithTimeout(ctx, estimateTimeout)
+	defer cancel()
+
+	est := rowEstimate{known: true}
+	var reasons []string
+	counted := 0
+	problem := ""
+	for _, scope := range analysis.DML {
+		reason := fmt.Sprintf("%s on line %d", scope.Type, scope.Line)
+		if scope.Unbounded {
+			reason += ": " + scope.Reason()
+		}
+		switch {
+		case !est.known:
+		case !scope.Countable:
+			reason += ", not counted"
+			est.partial = true
+		default:
+			n, err := s.executorPool.CountRows(ctx, connectionName, scope.CountSQL(), opts)
+			if err != nil {
+				est.known, problem = false, "count failed: "+err.Error()
+				break
+			}
+			est.rows += n
+			counted++
+		}
+		reasons = append(reasons, reason)
+	}
+	if est.known && counted == 0 {
+		est.known, problem = false, "not counted because the statements use functions or bind variables"
+	}
+	switch {
+	case !est.known:
+		req.AffectedRows = fmt.Sprintf("unknown, %s (%s)", problem, strings.Join(reasons, "; "))
+	case est.partial:
+		req.AffectedRows = fmt.Sprintf("at least %s, statements with functions or bind variables not counted (%s)", formatCount(est.rows), strings.Join(reasons, "; "))
+	default:
+		req.AffectedRows = fmt.Sprintf("about %s (%s)", formatCount(est.rows), strings.Join(reasons, "; "))
+	}
+	return est
+}
+
+// confirmLargeChange asks for a second, explicit confirmation when the estimate is above
+// security.second_confirm_row_threshold. It returns false when the call must not run; the response has then been sent.
+func (s *Server) confirmLargeChange(ctx context.Context, id interface{}, decision policy.Decision, est rowEstimate, req *confirm.ConfirmRequest) bool {
+	threshold := s.config.Security.SecondConfirmRowThreshold
+	if !est.known || threshold <= 0 || est.rows <= threshold {
+		return true
+	}
+	second := *req
+	second.Warning = fmt.Sprintf("second confirmation: about %s rows will change, more than the threshold of %s (security.second_confirm_row_threshold)",
+		formatCount(est.rows), formatCount(threshold))
+	approved, err := s.confirmSQL(ctx, &second)
+	if err != nil {
+		if s.reportInterrupted(ctx, id, err, false, req.SQL, req.MatchedKeywords, req.Binds, req.Connection) {
+			return false
+		}
+		s.logAuditRule(ctx, req.SQL, req.MatchedKeywords, req.Binds, false, "CONFIRM_ERROR: "+err.Error(), req.Connection, decision.Rule)
+		s.sendToolError(id, fmt.Sprintf("Confirmation dialog error: %v", err))
+		return false
+	}
+	if !approved {
+		s.logAuditRule(ctx, req.SQL, req.MatchedKeywords, req.Binds, false, "USER_REJECTED", req.Connection, decision.Rule)
+		s.sendError(id, ErrCodeUserRejected, "Execution cancelled by user at the second confirmation", map[string]interface{}{
+			"code":             "USER_REJECTED",
+			"matched_keywords": req.MatchedKeywords,
+			"rule":             decision.Rule,
+			"estimated_rows":   est.rows,
+		})
+		return false
+	}
+	return true
+}
+
+// formatCount writes n with thousands separators: 1234567 -> "1,234,567".
+func formatCount(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	start := 0
+	if n < 0 {
+		start = 1
+	}
+	for i := len(s) - 3; i > start; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alvin/oracle-mcp-server/internal/confirm"
+	"github.com/alvin/oracle-mcp-server/internal/policy"
+	"github.com/alvin/oracle-mcp-server/internal/sqlanalyzer"
+)
+
+// applyPolicy carries out the policy decision for a call about to run req.SQL: a deny is audited and answered with
+// ErrCodePolicyDenied without review, a confirm opens the review window. It returns false when the call must not
+// run; the response has then been sent.
+func (s *Server) applyPolicy(ctx context.Context, id interface{}, decision policy.Decision, req *confirm.ConfirmRequest) bool {
+	switch decision.Action {
+	case policy.Deny:
+		s.logAuditRule(ctx, req.SQL, req.MatchedKeywords, req.Binds, false, "POLICY_DENIED", req.Connection, decision.Rule)
+		message := fmt.Sprintf("Execution denied by policy rule %q", decision.Rule)
+		if decision.Message != "" {
+			message += ": " + decision.Message
+		}
+		s.sendError(id, ErrCodePolicyDenied, message, map[string]interface{}{
+			"code":      "POLICY_DENIED",
+			"rule":      decision.Rule,
+			"statement": decision.Statement,
+		})
+		return false
+	case policy.Confirm:
+		if !decision.Builtin {
+			req.Rule = decision.Rule
+		}
+		approved, err := s.confirmSQL(ctx, req)
+		if err != nil {
+			if s.reportInterrupted(ctx, id, err, false, req.SQL, req.MatchedKeywords, req.Binds, req.Connection) {
+				return false
+			}
+			s.logAuditRule(ctx, req.SQL, req.MatchedKeywords, req.Binds, false, "CONFIRM_ERROR: "+err.Error(), req.Connection, decision.Rule)
+			s.sendToolError(id, fmt.Sprintf("Confirmation dialog error: %v", err))
+			return false
+		}
+		if !approved {
+			s.logAuditRule(ctx, req.SQL, req.MatchedKeywords, req.Binds, false, "USER_REJECTED", req.Connection, decision.Rule)
+			s.sendError(id, ErrCodeUserRejected, "Execution cancelled by user", map[string]interface{}{
+				"code":             "USER_REJECTED",
+				"matched_keywords": req.MatchedKeywords,
+				"rule":             decision.Rule,
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// refuseOnReadOnly refuses SQL that is not a query when the connection has read_only set, before any review: the
+// call is audited as READ_ONLY_REJECTED and answered with ErrCodeReadOnly. It returns true when the call was refused.
+func (s *Server) refuseOnReadOnly(ctx context.Context, id interface{}, analysis *sqlanalyzer.AnalysisResult, binds []string, connectionName, displayConnection string) bool {
+	if analysis.IsQuery || !s.executorPool.ReadOnly(connectionName) {
+		return false
+	}
+	stmtType := sqlanalyzer.GetStatementType(analysis.NonQuery.Text)
+	s.logAudit(ctx, analysis.OriginalSQL, analysis.MatchedKeywords, binds, false, "READ_ONLY_REJECTED", displayConnection)
+	s.sendError(id, ErrCodeReadOnly, fmt.Sprintf("Connection %q is read-only: only queries may run (%s on line %d)", displayConnection, stmtType, analysis.NonQuery.Line), map[string]interface{}{
+		"code":           "READ_ONLY",
+		"connection":     displayConnection,
+		"statement_type": stmtType,
+		"line":           analysis.NonQuery.Line,
+	})
+	return true
+}
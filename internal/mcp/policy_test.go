@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alvin/oracle-mcp-server/internal/config"
+	"github.com/alvin/oracle-mcp-server/internal/confirm"
+)
+
+func TestExecuteSQL_PolicyRules(t *testing.T) {
+	c := confirm.AlwaysReject()
+	ts := newTestServer(t, c, func(cfg *config.Config) {
+		cfg.Security.Rules = []config.PolicyRule{
+			{Name: "no-drop-user", Action: "deny", StatementTypes: []string{"DROP USER"}, Message: "ask a DBA"},
+			{Name: "emp-review", Action: "confirm", Objects: []string{"EMP"}},
+		}
+	})
+
+	resps := ts.send(t,
+		toolCall(1, "execute_sql", map[string]interface{}{"sql": "DROP USER scott CASCADE"}),
+		toolCall(2, "execute_sql", map[string]interface{}{"sql": "SELECT * FROM emp"}),
+	)
+	rpcErr, ok := resps[0]["error"].(map[string]interface{})
+	if !ok || rpcErr["code"].(float64) != ErrCodePolicyDenied {
+		t.Fatalf("deny response = %v", resps[0])
+	}
+	if rpcErr["message"] != `Execution denied by policy rule "no-drop-user": ask a DBA` {
+		t.Errorf("message = %v", rpcErr["message"])
+	}
+	if data := rpcErr["data"].(map[string]interface{}); data["code"] != "POLICY_DENIED" || data["rule"] != "no-drop-user" || data["statement"].(float64) != 1 {
+		t.Errorf("data = %v", data)
+	}
+
+	rpcErr, ok = resps[1]["error"].(map[string]interface{})
+	if !ok || rpcErr["code"].(float64) != ErrCodeUserRejected || rpcErr["data"].(map[string]interface{})["rule"] != "emp-review" {
+		t.Fatalf("confirm response = %v", resps[1])
+	}
+	// a denied call never reaches the review window
+	reqs := c.Requests()
+	if len(reqs) != 1 || reqs[0].Rule != "emp-review" {
+		t.Fatalf("confirm requests = %+v", reqs)
+	}
+
+	entries := ts.auditEntries(t)
+	if len(entries) != 2 {
+		t.Fatalf("got %d audit entries, want 2", len(entries))
+	}
+	if e := entries[0]; e["AUDIT_ACTION"] != "POLICY_DENIED" || e["AUDIT_RULE"] != "no-drop-user" || e["AUDIT_APPROVED"] != "false" {
+		t.Errorf("deny audit entry = %v", e)
+	}
+	if e := entries[1]; e["AUDIT_ACTION"] != "USER_REJECTED" || e["AUDIT_RULE"] != "emp-review" {
+		t.Errorf("reject audit entry = %v", e)
+	}
+}
+
+func TestReadOnlyConnection(t *testing.T) {
+	c := confirm.NewScripted() // a refused call must never reach the review window
+	ts := newTestServer(t, c, func(cfg *config.Config) {
+		cfg.Oracle.Connections["db1"] = config.ConnectionConfig{DSN: unreachableDSN, ReadOnly: true}
+	})
+
+	resps := ts.send(t,
+		toolCall(1, "execute_sql", map[string]interface{}{"sql": "SELECT 1 FROM dual;\nDELETE FROM emp;"}),
+		toolCall(2, "query_to_csv_file", map[string]interface{}{"sql": "SELECT * FROM emp FOR UPDATE", "file_path": filepath.Join(t.TempDir(), "out.csv")}),
+		toolCall(3, "execute_sql", map[string]interface{}{"sql": "SELECT * FROM emp"}),
+	)
+	for i, want := range []string{"DELETE", "SELECT"} {
+		rpcErr, ok := resps[i]["error"].(map[string]interface{})
+		if !ok || rpcErr["code"].(float64) != ErrCodeReadOnly {
+			t.Fatalf("response %d = %v, want read-only error", i+1, resps[i])
+		}
+		if data := rpcErr["data"].(map[string]interface{}); data["code"] != "READ_ONLY" || data["statement_type"] != want || data["connection"] != "db1" {
+			t.Errorf("response %d data = %v", i+1, data)
+		}
+	}
+	if rpcErr := resps[0]["error"].(map[string]interface{}); rpcErr["data"].(map[string]interface{})["line"].(float64) != 2 {
+		t.Errorf("line = %v, want 2", rpcErr["data"])
+	}
+	// Queries go on to the database (which is unreachable here)
+	if text, isError := toolText(t, resps[2]); !isError || !strings.Contains(text, "SQL execution failed") {
+		t.Errorf("query result = %q (isError=%v), want execution failure", text, isError)
+	}
+	if len(c.Requests()) != 0 {
+		t.Errorf("confirmer saw %d requests, want 0", len(c.Requests()))
+	}
+
+	entries := ts.auditEntries(t)
+	if len(entries) != 3 {
+		t.Fatalf("got %d audit entries, want 3", len(entries))
+	}
+	for _, e := range entries[:2] {
+		if e["AUDIT_ACTION"] != "READ_ONLY_REJECTED" || e["AUDIT_APPROVED"] != "false" {
+			t.Errorf("audit entry = %v", e)
+		}
+	}
+}
+
+func TestExecuteSQL_UnboundedDML(t *testing.T) {
+	c := confirm.AlwaysReject()
+	ts := newTestServer(t, c, func(cfg *config.Config) {
+		cfg.Security.DangerKeywords = []string{"drop"} // leave the DELETE to confirm_unbounded_dml
+	})
+
+	resps := ts.send(t,
+		toolCall(1, "execute_sql", map[string]interface{}{"sql": "DELETE FROM emp WHERE 1 = 1"}),
+	)
+	rpcErr, ok := resps[0]["error"].(map[string]interface{})
+	if !ok || rpcErr["code"].(float64) != ErrCodeUserRejected || rpcErr["data"].(map[string]interface{})["rule"] != "confirm_unbounded_dml" {
+		t.Fatalf("response = %v", resps[0])
+	}
+	// the database is unreachable, so the count fails: the review says so and there is no second confirmation
+	reqs := c.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("got %d confirm requests, want 1", len(reqs))
+	}
+	if r := reqs[0]; !strings.HasPrefix(r.AffectedRows, "unknown, count failed") ||
+		!strings.Contains(r.AffectedRows, "DELETE on line 1: WHERE condition is always true") || r.Warning != "" {
+		t.Errorf("confirm request = %+v", r)
+	}
+	if e := ts.auditEntries(t); len(e) != 1 || e[0]["AUDIT_RULE"] != "confirm_unbounded_dml" {
+		t.Errorf("audit entries = %v", e)
+	}
+}
+
+func TestExecuteSQL_ReviewedDMLIsCounted(t *testing.T) {
+	c := confirm.AlwaysReject()
+	ts := newTestServer(t, c)
+
+	ts.send(t,
+		toolCall(1, "execute_sql", map[string]interface{}{"sql": "DELETE FROM emp WHERE deptno IN (10, 20)"}),
+		toolCall(2, "execute_sql", map[string]interface{}{"sql": "DELETE FROM emp WHERE id = :id", "binds": map[string]interface{}{"id": 1}}),
+	)
+	reqs := c.Requests()
+	if len(reqs) != 2 {
+		t.Fatalf("got %d confirm requests, want 2", len(reqs))
+	}
+	// a limited DELETE reviewed for its danger keyword is counted too, IN list included (and fails here: the
+	// database is unreachable)
+	if r := reqs[0].AffectedRows; !strings.HasPrefix(r, "unknown, count failed") || !strings.HasSuffix(r, "(DELETE on line 1)") {
+		t.Errorf("affected rows = %q", r)
+	}
+	if r := reqs[1].AffectedRows; r != "unknown, not counted because the statements use functions or bind variables (DELETE on line 1, not counted)" {
+		t.Errorf("affected rows with a bind = %q", r)
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	for n, want := range map[int64]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567", -12345: "-12,345"} {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
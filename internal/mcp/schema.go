@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alvin/oracle-mcp-server/internal/oracle"
+)
+
+// connectionProperty is the optional "connection" argument shared by the introspection tools.
+var connectionProperty = property{
+	Type:        "string",
+	Description: "Which configured database to use. Required when multiple connections; omit when only one.",
+}
+
+// schemaTools are the schema introspection tools listed by tools/list.
+func schemaTools() []tool {
+	limit := property{
+		Type:        "integer",
+		Description: fmt.Sprintf("Maximum results (default %d, at most %d). The result has truncated: true when more matched.", oracle.DefaultIntrospectionLimit, oracle.MaxIntrospectionLimit),
+	}
+	return []tool{
+		{
+			Name:        "list_schemas",
+			Description: "List the schemas (users) visible on a connection with their number of tables; current: true marks the session's current schema.",
+			InputSchema: inputSchema{
+				Type: "object",
+				Properties: map[string]property{
+					"pattern":    {Type: "string", Description: "Optional name filter: case-insensitive LIKE pattern; without % it matches anywhere in the name."},
+					"connection": connectionProperty,
+				},
+				Required: []string{},
+			},
+		},
+		{
+			Name:        "list_tables",
+			Description: "List the tables (and optionally views) of a schema with comments and row-count estimates from the optimizer statistics (rows_estimate, last_analyzed).",
+			InputSchema: inputSchema{
+				Type: "object",
+				Properties: map[string]property{
+					"owner":         {Type: "string", Description: "Schema to list (default: the current schema)."},
+					"pattern":       {Type: "string", Description: "Optional table name filter: case-insensitive LIKE pattern; without % it matches anywhere in the name."},
+					"include_views": {Type: "boolean", Description: "Also list views (default false)."},
+					"limit":         limit,
+					"connection":    connectionProperty,
+				},
+				Required: []string{},
+			},
+		},
+		{
+			Name:        "describe_table",
+			Description: "Describe a table or view: columns (type, nullability, default, comment), primary key, unique keys, foreign keys with the referenced table, indexes and comments. Synonyms (private, then PUBLIC) are resolved; resolved_from lists the ones followed.",
+			InputSchema: inputSchema{
+				Type: "object",
+				Properties: map[string]property{
+					"table":      {Type: "string", Description: "Table, view or synonym name, optionally qualified (OWNER.NAME). Unquoted names are case-insensitive; use \"Quoted\" names for mixed case."},
+					"owner":      {Type: "string", Description: "Schema of the table when 'table' is not qualified (default: the current schema, then PUBLIC synonyms)."},
+					"connection": connectionProperty,
+				},
+				Required: []string{"table"},
+			},
+		},
+		{
+			Name:        "search_objects",
+			Description: "Find database objects by name across all visible schemas (tables, views, synonyms and packages by default). Objects of the current schema come first; synonyms carry their target.",
+			InputSchema: inputSchema{
+				Type: "object",
+				Properties: map[string]property{
+					"pattern": {Type: "string", Description: "Case-insensitive LIKE pattern on the object name; without % it matches anywhere in the name."},
+					"object_types": {
+						Type:        "array",
+						Description: "Object types to search (default TABLE, VIEW, SYNONYM, PACKAGE). Supported: " + strings.Join(oracle.SearchableObjectTypes, ", ") + ".",
+						Items:       &property{Type: "string"},
+					},
+					"owner":      {Type: "string", Description: "Only search this schema."},
+					"limit":      limit,
+					"connection": connectionProperty,
+				},
+				Required: []string{"pattern"},
+			},
+		},
+	}
+}
+
+// handleListSchemas handles the list_schemas tool.
+func (s *Server) handleListSchemas(ctx context.Context, req *jsonRPCRequest, args map[string]interface{}) {
+	connectionName, displayConnection := s.connectionArg(args)
+	schemas, err := s.executorPool.ListSchemas(ctx, connectionName, stringArg(args, "pattern"), s.introspectOptions())
+	if err != nil {
+		s.introspectionError(ctx, req.ID, "list_schemas", err, displayConnection)
+		return
+	}
+	s.sendIntrospectionResult(req.ID, map[string]interface{}{"schemas": schemas, "count": len(schemas)})
+}
+
+// handleListTables handles the list_tables tool.
+func (s *Server) handleListTables(ctx context.Context, req *jsonRPCRequest, args map[string]interface{}) {
+	limit, err := limitArg(args)
+	if err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
+	includeViews, _ := args["include_views"].(bool)
+	connectionName, displayConnection := s.connectionArg(args)
+	tables, truncated, err := s.executorPool.ListTables(ctx, connectionName, oracle.TableFilter{
+		Owner:        stringArg(args, "owner"),
+		Pattern:      stringArg(args, "pattern"),
+		IncludeViews: includeViews,
+		Limit:        limit,
+	}, s.introspectOptions())
+	if err != nil {
+		s.introspectionError(ctx, req.ID, "list_tables", err, displayConnection)
+		return
+	}
+	s.sendIntrospectionResult(req.ID, map[string]interface{}{"tables": tables, "count": len(tables), "truncated": truncated})
+}
+
+// handleDescribeTable handles the describe_table tool.
+func (s *Server) handleDescribeTable(ctx context.Context, req *jsonRPCRequest, args map[string]interface{}) {
+	table := stringArg(args, "table")
+	if table == "" {
+		s.sendToolError(req.ID, "Missing required parameter: table")
+		return
+	}
+	connectionName, displayConnection := s.connectionArg(args)
+	desc, err := s.executorPool.DescribeTable(ctx, connectionName, stringArg(args, "owner"), table, s.introspectOptions())
+	if err != nil {
+		s.introspectionError(ctx, req.ID, "describe_table", err, displayConnection)
+		return
+	}
+	resultJSON, _ := json.MarshalIndent(desc, "", "  ")
+	s.sendToolResult(req.ID, string(resultJSON))
+}
+
+// handleSearchObjects handles the search_objects tool.
+func (s *Server) handleSearchObjects(ctx context.Context, req *jsonRPCRequest, args map[string]interface{}) {
+	pattern := stringArg(args, "pattern")
+	if pattern == "" {
+		s.sendToolError(req.ID, "Missing required parameter: pattern")
+		return
+	}
+	limit, err := limitArg(args)
+	if err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
+	var types []string
+	switch v := args["object_types"].(type) {
+	case nil:
+	case string:
+		types = strings.Split(v, ",")
+	case []interface{}:
+		for _, t := range v {
+			str, ok := t.(string)
+			if !ok {
+				s.sendToolError(req.ID, "Parameter 'object_types' must be an array of strings")
+				return
+			}
+			types = append(types, str)
+		}
+	default:
+		s.sendToolError(req.ID, "Parameter 'object_types' must be an array of strings")
+		return
+	}
+	connectionName, displayConnection := s.connectionArg(args)
+	objects, truncated, err := s.executorPool.SearchObjects(ctx, connectionName, oracle.ObjectSearch{
+		Pattern: pattern,
+		Owner:   stringArg(args, "owner"),
+		Types:   types,
+		Limit:   limit,
+	}, s.introspectOptions())
+	if err != nil {
+		s.introspectionError(ctx, req.ID, "search_objects", err, displayConnection)
+		return
+	}
+	s.sendIntrospectionResult(req.ID, map[string]interface{}{"objects": objects, "count": len(objects), "truncated": truncated})
+}
+
+// introspectOptions runs dictionary queries like execute_sql: inside the session's open transaction, if any, and
+// under the connection's query timeout.
+func (s *Server) introspectOptions() oracle.ExecOptions {
+	return oracle.ExecOptions{Session: s.session}
+}
+
+// introspectionError reports a failed introspection tool; cancellations and timeouts are audited like queries.
+func (s *Server) introspectionError(ctx context.Context, id interface{}, tool string, err error, connection string) {
+	if s.reportInterrupted(ctx, id, err, true, strings.ToUpper(tool), nil, nil, connection) {
+		return
+	}
+	s.sendToolError(id, tool+" failed: "+err.Error())
+}
+
+// sendIntrospectionResult sends out as the indented JSON tool result.
+func (s *Server) sendIntrospectionResult(id interface{}, out map[string]interface{}) {
+	if truncated, ok := out["truncated"].(bool); ok && !truncated {
+		delete(out, "truncated")
+	}
+	resultJSON, _ := json.MarshalIndent(out, "", "  ")
+	s.sendToolResult(id, string(resultJSON))
+}
+
+// stringArg returns the trimmed string argument key, or "" when it is missing or not a string.
+func stringArg(args map[string]interface{}, key string) string {
+	v, _ := args[key].(string)
+	return strings.TrimSpace(v)
+}
+
+// limitArg returns the optional "limit" argument (0 when omitted).
+func limitArg(args map[string]interface{}) (int, error) {
+	v, ok := args["limit"]
+	if !ok || v == nil {
+		return 0, nil
+	}
+	n, ok := v.(float64)
+	if !ok || n <= 0 || n != float64(int(n)) {
+		return 0, fmt.Errorf("Parameter 'limit' must be a positive integer")
+	}
+	return int(n), nil
+}
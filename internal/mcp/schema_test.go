@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alvin/oracle-mcp-server/internal/confirm"
+	"github.com/alvin/oracle-mcp-server/internal/oracle"
+)
+
+func TestSchemaTools_WithoutDatabase(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysApprove())
+
+	tests := []struct {
+		tool     string
+		args     map[string]interface{}
+		wantText string
+	}{
+		{"list_schemas", map[string]interface{}{}, `list_schemas failed: connection "db1" is currently unavailable`},
+		{"list_tables", map[string]interface{}{"owner": "hr", "include_views": true}, `list_tables failed: connection "db1" is currently unavailable`},
+		{"list_tables", map[string]interface{}{"limit": 0}, "Parameter 'limit' must be a positive integer"},
+		{"describe_table", map[string]interface{}{"table": "hr.employees"}, `describe_table failed: connection "db1" is currently unavailable`},
+		{"describe_table", map[string]interface{}{"table": "  "}, "Missing required parameter: table"},
+		{"search_objects", map[string]interface{}{"pattern": "emp", "object_types": []interface{}{"TABLE", "PACKAGE"}}, `search_objects failed: connection "db1" is currently unavailable`},
+		{"search_objects", map[string]interface{}{"pattern": "emp", "object_types": []interface{}{"DATABASE LINK"}}, `search_objects failed: unsupported object type "DATABASE LINK"`},
+		{"search_objects", map[string]interface{}{"pattern": "emp", "object_types": 3}, "Parameter 'object_types' must be an array of strings"},
+		{"search_objects", map[string]interface{}{}, "Missing required parameter: pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			resps := ts.send(t, toolCall(1, tt.tool, tt.args))
+			text, isError := toolText(t, resps[0])
+			if !isError || !strings.HasPrefix(text, tt.wantText) {
+				t.Errorf("result = %q (isError=%v), want prefix %q", text, isError, tt.wantText)
+			}
+		})
+	}
+	if entries := ts.auditEntries(t); len(entries) != 0 {
+		t.Errorf("introspection must not be audited: %v", entries)
+	}
+}
+
+func TestGetDDL_WithoutDatabase(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysApprove())
+	exportPath := filepath.Join(t.TempDir(), "hr.sql")
+
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		wantText string
+	}{
+		{"single object", map[string]interface{}{"object_type": "table", "name": "employees", "include_grants": true}, `get_ddl failed: connection "db1" is currently unavailable`},
+		{"schema export", map[string]interface{}{"owner": "hr", "file_path": exportPath}, `get_ddl failed: connection "db1" is currently unavailable`},
+		{"unknown type", map[string]interface{}{"object_type": "db link", "name": "x"}, `get_ddl failed: unsupported object type "db link"`},
+		{"name without type", map[string]interface{}{"name": "employees"}, "get_ddl failed: object type is required when a name is given"},
+		{"export without file", map[string]interface{}{"object_type": "TABLE"}, "Missing required parameter: name (or pass file_path"},
+		{"relative path", map[string]interface{}{"owner": "hr", "file_path": "hr.sql"}, "file_path must be an absolute path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resps := ts.send(t, toolCall(1, "get_ddl", tt.args))
+			text, isError := toolText(t, resps[0])
+			if !isError || !strings.HasPrefix(text, tt.wantText) {
+				t.Errorf("result = %q (isError=%v), want prefix %q", text, isError, tt.wantText)
+			}
+		})
+	}
+	if entries := ts.auditEntries(t); len(entries) != 0 {
+		t.Errorf("failed get_ddl calls must not be audited: %v", entries)
+	}
+}
+
+func TestDDLAuditText(t *testing.T) {
+	tests := []struct {
+		req  oracle.DDLRequest
+		file string
+		want string
+	}{
+		{oracle.DDLRequest{ObjectType: "table", Owner: "HR", Name: "EMP"}, "", "GET_DDL TABLE HR.EMP"},
+		{oracle.DDLRequest{ObjectType: "PACKAGE", Name: "PKG"}, "", "GET_DDL PACKAGE PKG"},
+		{oracle.DDLRequest{Owner: "HR"}, "/tmp/hr.sql", "GET_DDL SCHEMA HR TO /tmp/hr.sql"},
+		{oracle.DDLRequest{ObjectType: "view"}, "/tmp/v.sql", "GET_DDL SCHEMA VIEW TO /tmp/v.sql"},
+	}
+	for _, tt := range tests {
+		if got := ddlAuditText(tt.req, tt.file); got != tt.want {
+			t.Errorf("ddlAuditText(%+v) = %q, want %q", tt.req, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/alvin/oracle-mcp-server/internal/oracle"
+)
+
+// onErrorDescription documents the optional "on_error" argument of execute_sql and execute_sql_file.
+const onErrorDescription = "Multi-statement scripts: stop (default) skips the remaining statements after one fails, continue runs them all, " +
+	"like SQL*Plus WHENEVER SQLERROR EXIT / CONTINUE. The result lists each statement run in statements and the outcome in script."
+
+// onErrorArg returns whether the optional "on_error" argument asks to continue after a failed statement.
+func onErrorArg(args map[string]interface{}) (continueOnError bool, err error) {
+	switch v := stringArg(args, "on_error"); v {
+	case "", "stop", "stop_on_error":
+		return false, nil
+	case "continue", "continue_on_error":
+		return true, nil
+	default:
+		return false, fmt.Errorf("Parameter 'on_error' must be stop or continue")
+	}
+}
+
+// sendExecutionResult sends an execute_sql or execute_sql_file result; a script with failed statements is
+// returned as a tool error so the client does not mistake it for success, with the same JSON body.
+func (s *Server) sendExecutionResult(id interface{}, result *oracle.ExecutionResult) {
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	if result.ScriptError() == "" {
+		s.sendToolResult(id, string(resultJSON))
+		return
+	}
+	s.sendToolError(id, string(resultJSON))
+}
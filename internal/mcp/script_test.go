@@ -0,0 +1,15 @@
+package mcp
+
+import "testing"
+
+func TestOnErrorArg(t *testing.T) {
+	for in, want := range map[string]bool{"": false, "stop": false, "stop_on_error": false, "continue": true, "continue_on_error": true} {
+		got, err := onErrorArg(map[string]interface{}{"on_error": in})
+		if err != nil || got != want {
+			t.Errorf("onErrorArg(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	if _, err := onErrorArg(map[string]interface{}{"on_error": "ignore"}); err == nil {
+		t.Error("onErrorArg accepted ignore")
+	}
+}
@@ -17,6 +17,8 @@ import (
 	"github.com/alvin/oracle-mcp-server/internal/config"
 	"github.com/alvin/oracle-mcp-server/internal/confirm"
 	"github.com/alvin/oracle-mcp-server/internal/oracle"
+	"github.com/alvin/oracle-mcp-server/internal/policy"
+	"github.com/alvin/oracle-mcp-server/internal/redact"
 	"github.com/alvin/oracle-mcp-server/internal/sqlanalyzer"
 )
 
@@ -26,6 +28,10 @@ type jsonRPCRequest struct {
 	ID      interface{}     `json:"id,omitempty"`
 	Method  string          `json:"method"`
 	Params  json.RawMessage `json:"params,omitempty"`
+
+	// Result and Error are set when the line is the client's response to a server-initiated request (e.g. elicitation/create).
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *rpcError       `json:"error,omitempty"`
 }
 
 type jsonRPCResponse struct {
@@ -43,9 +49,13 @@ type rpcError struct {
 
 // MCP Protocol structures
 type initializeParams struct {
-	ProtocolVersion string     `json:"protocolVersion"`
-	Capabilities    struct{}   `json:"capabilities"`
-	ClientInfo      clientInfo `json:"clientInfo"`
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    clientCapabilities `json:"capabilities"`
+	ClientInfo      clientInfo         `json:"clientInfo"`
+}
+
+type clientCapabilities struct {
+	Elicitation *struct{} `json:"elicitation,omitempty"` // present when the client can answer elicitation/create
 }
 
 type clientInfo struct {
@@ -86,8 +96,9 @@ type inputSchema struct {
 }
 
 type property struct {
-	Type        string `json:"type"`
-	Description string `json:"description"`
+	Type        interface{} `json:"type"` // a type name, or a list of names when several JSON types are accepted
+	Description string      `json:"description"`
+	Items       *property   `json:"items,omitempty"` // element schema of "array" properties
 }
 
 type toolsListResult struct {
@@ -120,6 +131,14 @@ const (
 	ErrCodeMultiStatement = -32001
 	ErrCodePLSQLBlock     = -32002
 	ErrCodeSQLExecution   = -32003
+	// ErrCodeQueryTimeout: the statement ran past its timeout_seconds / oracle.query_timeout_seconds and was broken off.
+	ErrCodeQueryTimeout = -32004
+	// ErrCodeRequestCancelled: the client sent notifications/cancelled for the request.
+	ErrCodeRequestCancelled = -32005
+	// ErrCodePolicyDenied: a security.rules entry with action deny matched; nothing was shown or run.
+	ErrCodePolicyDenied = -32006
+	// ErrCodeReadOnly: SQL other than a query was sent to a connection with read_only set; nothing was shown or run.
+	ErrCodeReadOnly = -32007
 )
 
 // Server is the MCP server implementation.
@@ -127,14 +146,44 @@ type Server struct {
 	config       *config.Config
 	executorPool *oracle.ExecutorPool
 	analyzer     *sqlanalyzer.Analyzer
-	confirmer    *confirm.Confirmer
+	policy       *policy.Engine
+	confirmer    confirm.Confirmer
 	auditor      *audit.Auditor
+	// redactor masks secrets in the audit log, notifications, stderr and reviews; nil when security.redact is off.
+	redactor *redact.Redactor
 
 	reader *bufio.Reader
-	writer io.Writer
+	writer io.Writer // receives one JSON-RPC message (with trailing newline) per Write
 	mu     sync.Mutex
+	// session identifies the client session on the http transport ("" for stdio); transactions are per session.
+	session string
+	// token is the name of the bearer token the http session was opened with, recorded in the audit log.
+	token string
 
 	initialized bool
+	// clientElicitation is true when the client advertised the elicitation capability in initialize. Guarded by mu:
+	// a client may initialize again while calls run.
+	clientElicitation bool
+	// client is the clientInfo from initialize, recorded in the audit log. Guarded by mu.
+	client *audit.Client
+
+	// pending maps ids of server-initiated requests to the channel that receives the client's response.
+	pending    map[string]chan *jsonRPCRequest
+	pendingMu  sync.Mutex
+	nextCallID int64
+
+	// inflight maps ids of tools/call requests being handled to the cancel function of their context.
+	inflight   map[string]context.CancelCauseFunc
+	inflightMu sync.Mutex
+
+	// workers holds one token per running tools/call (at most maxConcurrentCalls); calls tracks them for shutdown.
+	workers chan struct{}
+	calls   sync.WaitGroup
+	// reviewing holds a token while a review is shown, so reviews are shown one at a time.
+	reviewing chan struct{}
+	// inputClosed is closed when the read loop ends, releasing calls that wait for a client response.
+	inputClosed    chan struct{}
+	closeInputOnce sync.Once
 
 	// verboseLogDedup avoids duplicate verbose log lines (e.g. when client triggers tool twice)
 	lastVerboseLog struct {
@@ -144,17 +193,37 @@ type Server struct {
 	}
 }
 
-// NewServer creates a new MCP server.
-func NewServer(cfg *config.Config) (*Server, error) {
+// NewServer creates a new MCP server. confirmer is asked to approve SQL that needs review
+// (danger_keywords or DDL); pass confirm.NewDialogConfirmer() for the desktop dialog.
+func NewServer(cfg *config.Config, confirmer confirm.Confirmer) (*Server, error) {
+	if confirmer == nil {
+		return nil, fmt.Errorf("confirmer is required")
+	}
+
 	connections := cfg.OracleConnections()
 	if connections == nil {
 		return nil, fmt.Errorf("no Oracle connections in config")
 	}
+	var redactor *redact.Redactor
+	if cfg.Security.Redact {
+		var err error
+		if redactor, err = redact.New(cfg.Security.RedactPatterns); err != nil {
+			return nil, fmt.Errorf("invalid security.redact_patterns: %w", err)
+		}
+	}
 
 	executorPool, err := oracle.NewExecutorPool(connections)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Oracle executor pool: %w", err)
 	}
+	executorPool.SetTransactionIdleTimeout(time.Duration(cfg.Oracle.TransactionIdleTimeoutSeconds) * time.Second)
+	executorPool.SetResultHandleTTL(time.Duration(cfg.Oracle.ResultHandleTTLSeconds) * time.Second)
+	queryTimeouts := make(map[string]time.Duration, len(cfg.Oracle.QueryTimeouts))
+	for name, seconds := range cfg.Oracle.QueryTimeouts {
+		queryTimeouts[name] = time.Duration(seconds) * time.Second
+	}
+	executorPool.SetQueryTimeouts(time.Duration(cfg.Oracle.QueryTimeoutSeconds)*time.Second, queryTimeouts)
+	executorPool.SetReadOnly(cfg.ReadOnlyConnections())
 
 	var auditor *audit.Auditor
 	if cfg.Logging.AuditLog {
@@ -162,27 +231,52 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		if cfg.ConfigPath != "" && !filepath.IsAbs(logPath) {
 			logPath = filepath.Join(filepath.Dir(cfg.ConfigPath), logPath)
 		}
-		auditor, err = audit.NewAuditor(logPath)
+		auditor, err = audit.NewAuditor(logPath, audit.Options{
+			Format:        cfg.Logging.AuditFormat,
+			MaxFileBytes:  int64(cfg.Logging.AuditMaxFileMB) << 20,
+			MaxAge:        time.Duration(cfg.Logging.AuditRetentionDays) * 24 * time.Hour,
+			MaxTotalBytes: int64(cfg.Logging.AuditMaxTotalMB) << 20,
+			MaxFiles:      cfg.Logging.AuditMaxFiles,
+			Compress:      cfg.Logging.AuditCompress,
+		})
 		if err != nil {
 			executorPool.Close()
 			return nil, fmt.Errorf("failed to create auditor: %w", err)
 		}
 	}
 
-	return &Server{
+	s := &Server{
 		config:       cfg,
 		executorPool: executorPool,
 		analyzer:     sqlanalyzer.NewAnalyzer(cfg.Security.DangerKeywords, cfg.Security.DangerKeywordMatch),
-		confirmer:    confirm.NewConfirmer(),
+		policy:       policy.New(cfg.Security, executorPool.DefaultSchema),
+		confirmer:    confirmer,
 		auditor:      auditor,
+		redactor:     redactor,
 		reader:       bufio.NewReader(os.Stdin),
 		writer:       os.Stdout,
-	}, nil
+		pending:      make(map[string]chan *jsonRPCRequest),
+		inflight:     make(map[string]context.CancelCauseFunc),
+		workers:      make(chan struct{}, maxConcurrentCalls),
+		reviewing:    make(chan struct{}, 1),
+		inputClosed:  make(chan struct{}),
+	}
+	if cfg.Security.ConfirmMode == "elicitation" {
+		s.confirmer = &elicitationConfirmer{server: s, fallback: confirmer}
+	}
+	executorPool.OnIdleRollback = func(_ string, connection string) { s.onIdleRollback(connection) }
+	if auditor != nil {
+		auditor.SetErrorHandler(s.onAuditError)
+	}
+	return s, nil
 }
 
-// Run starts the MCP server and processes requests.
+// Run starts the MCP server and processes requests. tools/call requests run concurrently (see startToolsCall);
+// when input ends, Run waits for the calls still running before it closes the connections.
 func (s *Server) Run(ctx context.Context) error {
 	defer s.Close()
+	defer s.calls.Wait()
+	defer s.closeInput()
 
 	for {
 		select {
@@ -223,10 +317,75 @@ func (s *Server) processRequest() error {
 		return nil
 	}
 
-	s.handleRequest(&req)
+	s.dispatch(&req)
 	return nil
 }
 
+// dispatch routes one message from the client: a response to a server-initiated request, or a request or notification.
+func (s *Server) dispatch(req *jsonRPCRequest) {
+	if req.Method == "" && req.ID != nil {
+		s.deliverResponse(req)
+		return
+	}
+	s.handleRequest(req)
+}
+
+// deliverResponse hands a client response to the callClient waiting for that id; unknown ids are dropped.
+func (s *Server) deliverResponse(resp *jsonRPCRequest) {
+	key := fmt.Sprint(resp.ID)
+	s.pendingMu.Lock()
+	ch, ok := s.pending[key]
+	delete(s.pending, key)
+	s.pendingMu.Unlock()
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Ignoring response with unknown id %v\n", resp.ID)
+		return
+	}
+	ch <- resp
+}
+
+// callClient sends a server-initiated request (e.g. elicitation/create) and waits for the client's response,
+// which the read loop delivers through s.pending. It is called from tools/call goroutines, never from the read loop.
+// When ctx ends first, the request is withdrawn with notifications/cancelled and ctx's error is returned.
+func (s *Server) callClient(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	s.pendingMu.Lock()
+	s.nextCallID++
+	id := fmt.Sprintf("oracle-mcp-%d", s.nextCallID)
+	ch := make(chan *jsonRPCRequest, 1)
+	s.pending[id] = ch
+	// Still holding pendingMu: the request is registered and written as one step
+	s.sendMessage(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	s.pendingMu.Unlock()
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return nil, fmt.Errorf("%s failed: %s (code %d)", method, resp.Error.Message, resp.Error.Code)
+		}
+		return resp.Result, nil
+	case <-s.inputClosed:
+		s.pendingMu.Lock()
+		delete(s.pending, id)
+		s.pendingMu.Unlock()
+		return nil, fmt.Errorf("waiting for %s response: %w", method, io.EOF)
+	case <-ctx.Done():
+		s.pendingMu.Lock()
+		delete(s.pending, id)
+		s.pendingMu.Unlock()
+		s.sendMessage(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  "notifications/cancelled",
+			"params":  cancelledParams{RequestID: id, Reason: context.Cause(ctx).Error()},
+		})
+		return nil, ctx.Err()
+	}
+}
+
 // handleRequest routes the request to the appropriate handler.
 func (s *Server) handleRequest(req *jsonRPCRequest) {
 	switch req.Method {
@@ -234,10 +393,12 @@ func (s *Server) handleRequest(req *jsonRPCRequest) {
 		s.handleInitialize(req)
 	case "initialized", "notifications/initialized":
 		// Notifications (no id): client signals init done, no response needed.
+	case "notifications/cancelled":
+		s.handleCancelled(req)
 	case "tools/list":
 		s.handleToolsList(req)
 	case "tools/call":
-		s.handleToolsCall(req)
+		s.startToolsCall(req)
 	case "ping":
 		s.handlePing(req)
 	default:
@@ -252,8 +413,18 @@ func (s *Server) handleRequest(req *jsonRPCRequest) {
 func (s *Server) handleInitialize(req *jsonRPCRequest) {
 	s.initialized = true
 
+	var params initializeParams
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err == nil {
+			s.mu.Lock()
+			s.clientElicitation = params.Capabilities.Elicitation != nil
+			s.client = &audit.Client{Name: params.ClientInfo.Name, Version: params.ClientInfo.Version}
+			s.mu.Unlock()
+		}
+	}
+
 	result := initializeResult{
-		ProtocolVersion: "2024-11-05",
+		ProtocolVersion: negotiateProtocolVersion(params.ProtocolVersion),
 		Capabilities: serverCapability{
 			Tools: &toolsCapability{
 				ListChanged: false,
@@ -269,13 +440,33 @@ func (s *Server) handleInitialize(req *jsonRPCRequest) {
 	s.sendResult(req.ID, result)
 }
 
+// supportedProtocolVersions lists MCP revisions this server speaks, newest first.
+// 2025-06-18 adds elicitation/create, used by security.confirm_mode "elicitation".
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// negotiateProtocolVersion echoes the client's version when supported, otherwise offers the latest one we speak;
+// the client disconnects if it cannot use it.
+func negotiateProtocolVersion(requested string) string {
+	for _, v := range supportedProtocolVersions {
+		if v == requested {
+			return v
+		}
+	}
+	return supportedProtocolVersions[0]
+}
+
+// bindsDescription documents the optional "binds" argument of execute_sql and the query_to_*_file tools.
+const bindsDescription = "Optional bind variables for a single statement. Object for named binds ({\"id\": 42} for :id) or array for positional binds ([42, \"x\"] for :1, :2). " +
+	"Values are strings, numbers or null, or typed objects {\"type\": \"date\", \"value\": \"2024-01-31\"} with type string, number, date, timestamp, clob or null. " +
+	"Dates accept 2006-01-02, 2006-01-02 15:04:05 or RFC 3339; numbers may be given as strings to keep full precision."
+
 // handleToolsList returns the list of available tools.
 func (s *Server) handleToolsList(req *jsonRPCRequest) {
 		result := toolsListResult{
 		Tools: []tool{
 			{
 				Name:        "execute_sql",
-				Description: "Execute SQL against an Oracle database. When multiple databases are configured (e.g. source and target), use the 'connection' argument to choose which one (call list_connections to see names). Supports SELECT, INSERT, UPDATE, DELETE, DDL (CREATE, DROP, ALTER, etc.), and multiple statements. Multiple statements: one per line, each line ending with a semicolon. DDL is auto-committed. SQL that matches config danger_keywords will open a confirmation window showing the full SQL.",
+				Description: "Execute SQL against an Oracle database. When multiple databases are configured (e.g. source and target), use the 'connection' argument to choose which one (call list_connections to see names). Supports SELECT, INSERT, UPDATE, DELETE, DDL (CREATE, DROP, ALTER, etc.), and multiple statements. Multiple statements: one per line, each line ending with a semicolon. DDL is auto-committed. SQL that matches config danger_keywords will open a confirmation window showing the full SQL. Query results are limited to max_rows rows (config oracle.max_rows by default); when truncated is true, call fetch_more with result_handle for the next page. After begin_transaction, statements on that connection run in the transaction until commit or rollback.",
 				InputSchema: inputSchema{
 					Type: "object",
 					Properties: map[string]property{
@@ -283,6 +474,22 @@ func (s *Server) handleToolsList(req *jsonRPCRequest) {
 							Type:        "string",
 							Description: "SQL to run: one statement, or multiple statements (one per line, each line ending with semicolon).",
 						},
+						"binds": {
+							Type:        []string{"object", "array"},
+							Description: bindsDescription,
+						},
+						"max_rows": {
+							Type:        "integer",
+							Description: maxRowsDescription,
+						},
+						"on_error": {
+							Type:        "string",
+							Description: onErrorDescription,
+						},
+						"timeout_seconds": {
+							Type:        "integer",
+							Description: timeoutDescription,
+						},
 						"connection": {
 							Type:        "string",
 							Description: "Which configured database to use (e.g. 'database1', 'database2'). Required when multiple connections are configured; use list_connections to see names. Omit when only one connection is configured.",
@@ -301,6 +508,18 @@ func (s *Server) handleToolsList(req *jsonRPCRequest) {
 							Type:        "string",
 							Description: "Path to the SQL file (absolute or relative to server working directory).",
 						},
+						"max_rows": {
+							Type:        "integer",
+							Description: maxRowsDescription,
+						},
+						"on_error": {
+							Type:        "string",
+							Description: onErrorDescription,
+						},
+						"timeout_seconds": {
+							Type:        "integer",
+							Description: timeoutDescription,
+						},
 						"connection": {
 							Type:        "string",
 							Description: "Which configured database to use. Required when multiple connections are configured; omit when only one is configured.",
@@ -311,7 +530,7 @@ func (s *Server) handleToolsList(req *jsonRPCRequest) {
 			},
 			{
 				Name:        "list_connections",
-				Description: "List the names of configured Oracle database connections. Use these names as the 'connection' argument in execute_sql when copying or syncing between databases.",
+				Description: "List the names of configured Oracle database connections. Use these names as the 'connection' argument in execute_sql when copying or syncing between databases. Connections marked read_only accept queries only.",
 				InputSchema: inputSchema{
 					Type:       "object",
 					Properties: map[string]property{},
@@ -320,13 +539,17 @@ func (s *Server) handleToolsList(req *jsonRPCRequest) {
 			},
 			{
 				Name:        "query_to_csv_file",
-				Description: "Execute the given SQL and write the result to a file as CSV (header + data rows, UTF-8). Format follows RFC 4180. CLOB columns are read in full. file_path must be absolute. No confirmation dialog.",
+				Description: "Execute the given SQL and write the result to a file as CSV (header + data rows, UTF-8). Format follows RFC 4180. CLOB columns are read in full. file_path must be absolute. No confirmation dialog unless a security.rules entry asks for one. Read-only connections accept queries only.",
 				InputSchema: inputSchema{
 					Type: "object",
 					Properties: map[string]property{
 						"sql": {
 							Type:        "string",
-							Description: "SQL to run (e.g. SELECT). Single or multiple statements; last result is written.",
+							Description: "SQL to run (e.g. SELECT). Single or multiple statements; the rows of the last query are written.",
+						},
+						"binds": {
+							Type:        []string{"object", "array"},
+							Description: bindsDescription,
 						},
 						"file_path": {
 							Type:        "string",
@@ -336,19 +559,27 @@ func (s *Server) handleToolsList(req *jsonRPCRequest) {
 							Type:        "string",
 							Description: "Which configured database to use. Required when multiple connections; omit when only one.",
 						},
+						"timeout_seconds": {
+							Type:        "integer",
+							Description: timeoutDescription,
+						},
 					},
 					Required: []string{"sql", "file_path"},
 				},
 			},
 			{
 				Name:        "query_to_text_file",
-				Description: "Execute the given SQL and write the result to a file as plain text: no header, columns tab-separated. No extra newlines between rows; only newlines in the cell data are written. CLOB columns are read in full. Use for procedure source or any query (including CLOB). file_path must be absolute. No confirmation dialog.",
+				Description: "Execute the given SQL and write the result to a file as plain text: no header, columns tab-separated. No extra newlines between rows; only newlines in the cell data are written. CLOB columns are read in full. Use for procedure source or any query (including CLOB). file_path must be absolute. No confirmation dialog unless a security.rules entry asks for one. Read-only connections accept queries only.",
 				InputSchema: inputSchema{
 					Type: "object",
 					Properties: map[string]property{
 						"sql": {
 							Type:        "string",
-							Description: "SQL to run (e.g. SELECT text FROM user_source ...). Single or multiple statements; last result is written.",
+							Description: "SQL to run (e.g. SELECT text FROM user_source ...). Single or multiple statements; the rows of the last query are written.",
+						},
+						"binds": {
+							Type:        []string{"object", "array"},
+							Description: bindsDescription,
 						},
 						"file_path": {
 							Type:        "string",
@@ -358,18 +589,110 @@ func (s *Server) handleToolsList(req *jsonRPCRequest) {
 							Type:        "string",
 							Description: "Which configured database to use. Required when multiple connections; omit when only one.",
 						},
+						"timeout_seconds": {
+							Type:        "integer",
+							Description: timeoutDescription,
+						},
 					},
 					Required: []string{"sql", "file_path"},
 				},
 			},
+			{
+				Name:        "fetch_more",
+				Description: "Return the next page of a query result that execute_sql or execute_sql_file truncated (truncated: true). Pass the result_handle from that result; the handle stays valid while more rows remain and expires after oracle.result_handle_ttl_seconds without use.",
+				InputSchema: inputSchema{
+					Type: "object",
+					Properties: map[string]property{
+						"result_handle": {
+							Type:        "string",
+							Description: "The result_handle returned with the truncated result.",
+						},
+						"max_rows": {
+							Type:        "integer",
+							Description: "Rows to return in this page (default: config oracle.max_rows).",
+						},
+						"timeout_seconds": {
+							Type:        "integer",
+							Description: timeoutDescription,
+						},
+					},
+					Required: []string{"result_handle"},
+				},
+			},
+			{
+				Name:        "begin_transaction",
+				Description: "Open a transaction on a connection. Until commit or rollback, execute_sql, execute_sql_file and query_to_*_file on that connection run on one dedicated session without autocommit, so results can be checked before committing. Oracle DDL still commits implicitly. An idle transaction is rolled back automatically after oracle.transaction_idle_timeout_seconds.",
+				InputSchema: inputSchema{
+					Type: "object",
+					Properties: map[string]property{
+						"connection": {
+							Type:        "string",
+							Description: "Which configured database to use. Required when multiple connections; omit when only one.",
+						},
+					},
+					Required: []string{},
+				},
+			},
+			{
+				Name:        "commit",
+				Description: "Commit the open transaction on a connection and return to autocommit.",
+				InputSchema: inputSchema{
+					Type: "object",
+					Properties: map[string]property{
+						"connection": {
+							Type:        "string",
+							Description: "Which configured database to use. Required when multiple connections; omit when only one.",
+						},
+					},
+					Required: []string{},
+				},
+			},
+			{
+				Name:        "rollback",
+				Description: "Roll back the open transaction on a connection and return to autocommit. With 'savepoint', undo only the work after that savepoint and keep the transaction open.",
+				InputSchema: inputSchema{
+					Type: "object",
+					Properties: map[string]property{
+						"savepoint": {
+							Type:        "string",
+							Description: "Optional savepoint name (set with the savepoint tool) to roll back to.",
+						},
+						"connection": {
+							Type:        "string",
+							Description: "Which configured database to use. Required when multiple connections; omit when only one.",
+						},
+					},
+					Required: []string{},
+				},
+			},
+			{
+				Name:        "savepoint",
+				Description: "Set a savepoint in the open transaction on a connection; rollback with 'savepoint' returns to it.",
+				InputSchema: inputSchema{
+					Type: "object",
+					Properties: map[string]property{
+						"name": {
+							Type:        "string",
+							Description: "Savepoint name: letters, digits, _, $ and #, starting with a letter.",
+						},
+						"connection": {
+							Type:        "string",
+							Description: "Which configured database to use. Required when multiple connections; omit when only one.",
+						},
+					},
+					Required: []string{"name"},
+				},
+			},
 		},
 	}
+	result.Tools = append(result.Tools, schemaTools()...)
+	result.Tools = append(result.Tools, getDDLTool(), explainPlanTool(), searchAuditTool())
 
 	s.sendResult(req.ID, result)
 }
 
-// handleToolsCall handles tool execution requests.
-func (s *Server) handleToolsCall(req *jsonRPCRequest) {
+// handleToolsCall handles tool execution requests; ctx is cancelled by notifications/cancelled.
+func (s *Server) handleToolsCall(ctx context.Context, req *jsonRPCRequest) {
 	var params toolCallParams
 	if err := json.Unmarshal(req.Params, &params); err != nil {
 		s.sendError(req.ID, ErrCodeInvalidParams, "Invalid params", nil)
@@ -378,22 +701,46 @@ func (s *Server) handleToolsCall(req *jsonRPCRequest) {
 
 	switch params.Name {
 	case "execute_sql":
-		s.handleExecuteSQL(req, params.Arguments)
+		s.handleExecuteSQL(ctx, req, params.Arguments)
 	case "execute_sql_file":
-		s.handleExecuteSQLFile(req, params.Arguments)
+		s.handleExecuteSQLFile(ctx, req, params.Arguments)
 	case "list_connections":
 		s.handleListConnections(req)
+	case "list_schemas":
+		s.handleListSchemas(ctx, req, params.Arguments)
+	case "list_tables":
+		s.handleListTables(ctx, req, params.Arguments)
+	case "describe_table":
+		s.handleDescribeTable(ctx, req, params.Arguments)
+	case "search_objects":
+		s.handleSearchObjects(ctx, req, params.Arguments)
+	case "get_ddl":
+		s.handleGetDDL(ctx, req, params.Arguments)
+	case "explain_plan":
+		s.handleExplainPlan(ctx, req, params.Arguments)
 	case "query_to_csv_file":
-		s.handleQueryToCSVFile(req, params.Arguments)
+		s.handleQueryToCSVFile(ctx, req, params.Arguments)
 	case "query_to_text_file":
-		s.handleQueryToTextFile(req, params.Arguments)
+		s.handleQueryToTextFile(ctx, req, params.Arguments)
+	case "begin_transaction":
+		s.handleBeginTransaction(ctx, req, params.Arguments)
+	case "commit":
+		s.handleCommit(ctx, req, params.Arguments)
+	case "rollback":
+		s.handleRollback(ctx, req, params.Arguments)
+	case "savepoint":
+		s.handleSavepoint(ctx, req, params.Arguments)
+	case "fetch_more":
+		s.handleFetchMore(ctx, req, params.Arguments)
+	case "search_audit":
+		s.handleSearchAudit(req, params.Arguments)
 	default:
 		s.sendError(req.ID, ErrCodeMethodNotFound, fmt.Sprintf("Unknown tool: %s", params.Name), nil)
 	}
 }
 
 // handleExecuteSQL handles the execute_sql tool.
-func (s *Server) handleExecuteSQL(req *jsonRPCRequest, args map[string]interface{}) {
+func (s *Server) handleExecuteSQL(ctx context.Context, req *jsonRPCRequest, args map[string]interface{}) {
 	// Extract SQL from arguments
 	sqlArg, ok := args["sql"]
 	if !ok {
@@ -407,6 +754,24 @@ func (s *Server) handleExecuteSQL(req *jsonRPCRequest, args map[string]interface
 		return
 	}
 
+	// Optional bind variables (named or positional)
+	binds, err := oracle.ParseBinds(args["binds"])
+	if err != nil {
+		s.sendToolError(req.ID, fmt.Sprintf("Invalid binds: %v", err))
+		return
+	}
+	bindLines := oracle.FormatBinds(binds)
+	maxRows, err := s.maxRowsArg(args)
+	if err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
+	continueOnError, err := onErrorArg(args)
+	if err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
+
 	// Optional: which configured connection to use (when multiple DBs are configured)
 	connectionName := ""
 	if c, ok := args["connection"]; ok && c != nil {
@@ -422,55 +787,64 @@ func (s *Server) handleExecuteSQL(req *jsonRPCRequest, args map[string]interface
 			displayConnection = names[0]
 		}
 	}
+	timeout, err := s.timeoutArg(args, connectionName)
+	if err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
 
 	// Analyze the SQL
 	analysis := s.analyzer.Analyze(sql)
 	stmtType := sqlanalyzer.GetStatementType(sql)
+	if s.refuseOnReadOnly(ctx, req.ID, analysis, bindLines, connectionName, displayConnection) {
+		return
+	}
 
-	// Confirmation when SQL contains config danger_keywords or is DDL (do not match "create" inside string literals)
-	needsConfirmation := analysis.IsDangerous ||
-		(s.config.Security.RequireConfirmForDDL && analysis.IsDDL)
-
-	if needsConfirmation {
-		confirmReq := &confirm.ConfirmRequest{
-			SQL:             sql,
-			MatchedKeywords: analysis.MatchedKeywords,
-			StatementType:   stmtType,
-			IsDDL:           analysis.IsDDL,
-			Connection:      displayConnection,
-			ConnectionIndex: connectionIndexInPool(s.executorPool, displayConnection),
-		}
-
-		approved, err := s.confirmer.Confirm(confirmReq)
-		if err != nil {
-			s.logAudit(sql, analysis.MatchedKeywords, false, "CONFIRM_ERROR: "+err.Error(), displayConnection)
-			s.sendToolError(req.ID, fmt.Sprintf("Confirmation dialog error: %v", err))
-			return
-		}
-
-		if !approved {
-			s.logAudit(sql, analysis.MatchedKeywords, false, "USER_REJECTED", displayConnection)
-			s.sendError(req.ID, ErrCodeUserRejected, "Execution cancelled by user", map[string]interface{}{
-				"code":             "USER_REJECTED",
-				"matched_keywords": analysis.MatchedKeywords,
-			})
-			return
-		}
+	// security.rules, then danger_keywords, require_confirm_for_ddl and confirm_unbounded_dml, decide whether the
+	// SQL is reviewed or refused
+	decision := s.policy.Evaluate(sql, displayConnection)
+	review := &confirm.ConfirmRequest{
+		SQL:             sql,
+		MatchedKeywords: analysis.MatchedKeywords,
+		StatementType:   stmtType,
+		IsDDL:           analysis.IsDDL,
+		Connection:      displayConnection,
+		ConnectionIndex: connectionIndexInPool(s.executorPool, displayConnection),
+		Binds:           bindLines,
+		Transaction:     s.transactionLabel(connectionName, analysis.IsDDL),
+	}
+	estimate := s.estimateAffectedRows(ctx, decision, analysis, connectionName, oracle.ExecOptions{Timeout: timeout, Session: s.session}, review)
+	if !s.applyPolicy(ctx, req.ID, decision, review) || !s.confirmLargeChange(ctx, req.ID, decision, estimate, review) {
+		return
+	}
+	// The client may have given up while the review was open
+	if s.reportInterrupted(ctx, req.ID, nil, true, sql, analysis.MatchedKeywords, bindLines, displayConnection) {
+		return
 	}
 
 	// Execute the SQL on the chosen connection
-	ctx := context.Background()
-	result, err := s.executorPool.Execute(ctx, connectionName, sql, stmtType)
+	result, err := s.executorPool.Execute(ctx, connectionName, sql, stmtType, oracle.ExecOptions{Binds: binds, MaxRows: maxRows, Timeout: timeout, Session: s.session, ContinueOnError: continueOnError})
 	if err != nil {
+		if s.reportInterrupted(ctx, req.ID, err, true, sql, analysis.MatchedKeywords, bindLines, displayConnection) {
+			return
+		}
 		// Approved=true: execution was attempted after passing confirmation (or confirmation was not required).
 		// Do not use false here — that would imply USER_REJECTED while ORA-* proves the server ran the statement.
-		s.logAudit(sql, analysis.MatchedKeywords, true, "EXECUTION_ERROR: "+err.Error(), displayConnection)
+		s.logAuditRule(ctx, sql, analysis.MatchedKeywords, bindLines, true, "EXECUTION_ERROR: "+err.Error(), displayConnection, decision.Rule)
 		s.sendToolError(req.ID, fmt.Sprintf("SQL execution failed: %v", err))
 		return
 	}
+	if result.InTransaction && analysis.IsDDL {
+		result.Warning = ddlInTransactionWarning
+	}
 
-	// Log successful execution
-	s.logAudit(sql, analysis.MatchedKeywords, true, "SUCCESS", displayConnection)
+	// Log the execution; a script with failed statements is an execution error
+	entry := audit.Entry{SQL: sql, Keywords: analysis.MatchedKeywords, Binds: bindLines, Approved: true, Action: "SUCCESS",
+		Connection: displayConnection, Rule: decision.Rule, StatementType: stmtType, RowsAffected: &result.RowsAffected}
+	if scriptErr := result.ScriptError(); scriptErr != "" {
+		entry.Action = "EXECUTION_ERROR: " + scriptErr
+	}
+	s.logAuditEntry(ctx, entry)
 
 	if s.config.Logging.VerboseLogging {
 		msg := fmt.Sprintf("[debug] Execute Action: %s, Connection: %s\n", stmtType, displayConnection)
@@ -482,17 +856,16 @@ func (s *Server) handleExecuteSQL(req *jsonRPCRequest, args map[string]interface
 		}
 		s.lastVerboseLog.mu.Unlock()
 		if !dup {
-			fmt.Fprint(os.Stderr, msg)
+			fmt.Fprint(os.Stderr, s.redactor.Redact(msg))
 		}
 	}
 
 	// Format and return result
-	resultJSON, _ := json.MarshalIndent(result, "", "  ")
-	s.sendToolResult(req.ID, string(resultJSON))
+	s.sendExecutionResult(req.ID, result)
 }
 
 // handleExecuteSQLFile reads SQL from a file, analyzes it, shows review window with formatted content if needed, then executes on approve.
-func (s *Server) handleExecuteSQLFile(req *jsonRPCRequest, args map[string]interface{}) {
+func (s *Server) handleExecuteSQLFile(ctx context.Context, req *jsonRPCRequest, args map[string]interface{}) {
 	pathArg, ok := args["file_path"]
 	if !ok {
 		s.sendToolError(req.ID, "Missing required parameter: file_path")
@@ -514,6 +887,16 @@ func (s *Server) handleExecuteSQLFile(req *jsonRPCRequest, args map[string]inter
 		filePath = filepath.Join(cwd, filePath)
 	}
 	filePath = filepath.Clean(filePath)
+	maxRows, err := s.maxRowsArg(args)
+	if err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
+	continueOnError, err := onErrorArg(args)
+	if err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
 
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -539,50 +922,56 @@ func (s *Server) handleExecuteSQLFile(req *jsonRPCRequest, args map[string]inter
 			displayConnection = names[0]
 		}
 	}
+	timeout, err := s.timeoutArg(args, connectionName)
+	if err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
 
 	analysis := s.analyzer.Analyze(sql)
 	stmtType := sqlanalyzer.GetStatementType(sql)
+	if s.refuseOnReadOnly(ctx, req.ID, analysis, nil, connectionName, displayConnection) {
+		return
+	}
 
-	needsConfirmation := analysis.IsDangerous ||
-		(s.config.Security.RequireConfirmForDDL && analysis.IsDDL)
-
-	if needsConfirmation {
-		confirmReq := &confirm.ConfirmRequest{
-			SQL:             sql,
-			MatchedKeywords: analysis.MatchedKeywords,
-			StatementType:   stmtType,
-			IsDDL:           analysis.IsDDL,
-			Connection:      displayConnection,
-			ConnectionIndex: connectionIndexInPool(s.executorPool, displayConnection),
-			SourceLabel:     "File: " + filePath,
-		}
-
-		approved, err := s.confirmer.Confirm(confirmReq)
-		if err != nil {
-			s.logAudit(sql, analysis.MatchedKeywords, false, "CONFIRM_ERROR: "+err.Error(), displayConnection)
-			s.sendToolError(req.ID, fmt.Sprintf("Confirmation dialog error: %v", err))
-			return
-		}
-
-		if !approved {
-			s.logAudit(sql, analysis.MatchedKeywords, false, "USER_REJECTED", displayConnection)
-			s.sendError(req.ID, ErrCodeUserRejected, "Execution cancelled by user", map[string]interface{}{
-				"code":             "USER_REJECTED",
-				"matched_keywords": analysis.MatchedKeywords,
-			})
-			return
-		}
+	decision := s.policy.Evaluate(sql, displayConnection)
+	review := &confirm.ConfirmRequest{
+		SQL:             sql,
+		MatchedKeywords: analysis.MatchedKeywords,
+		StatementType:   stmtType,
+		IsDDL:           analysis.IsDDL,
+		Connection:      displayConnection,
+		ConnectionIndex: connectionIndexInPool(s.executorPool, displayConnection),
+		SourceLabel:     "File: " + filePath,
+		Transaction:     s.transactionLabel(connectionName, analysis.IsDDL),
+	}
+	estimate := s.estimateAffectedRows(ctx, decision, analysis, connectionName, oracle.ExecOptions{Timeout: timeout, Session: s.session}, review)
+	if !s.applyPolicy(ctx, req.ID, decision, review) || !s.confirmLargeChange(ctx, req.ID, decision, estimate, review) {
+		return
+	}
+	if s.reportInterrupted(ctx, req.ID, nil, true, sql, analysis.MatchedKeywords, nil, displayConnection) {
+		return
 	}
 
-	ctx := context.Background()
-	result, err := s.executorPool.Execute(ctx, connectionName, sql, stmtType)
+	result, err := s.executorPool.Execute(ctx, connectionName, sql, stmtType, oracle.ExecOptions{MaxRows: maxRows, Timeout: timeout, Session: s.session, SourceFile: filePath, ContinueOnError: continueOnError})
 	if err != nil {
-		s.logAudit(sql, analysis.MatchedKeywords, true, "EXECUTION_ERROR: "+err.Error(), displayConnection)
+		if s.reportInterrupted(ctx, req.ID, err, true, sql, analysis.MatchedKeywords, nil, displayConnection) {
+			return
+		}
+		s.logAuditRule(ctx, sql, analysis.MatchedKeywords, nil, true, "EXECUTION_ERROR: "+err.Error(), displayConnection, decision.Rule)
 		s.sendToolError(req.ID, fmt.Sprintf("SQL execution failed: %v", err))
 		return
 	}
+	if result.InTransaction && analysis.IsDDL {
+		result.Warning = ddlInTransactionWarning
+	}
 
-	s.logAudit(sql, analysis.MatchedKeywords, true, "SUCCESS", displayConnection)
+	entry := audit.Entry{SQL: sql, Keywords: analysis.MatchedKeywords, Approved: true, Action: "SUCCESS",
+		Connection: displayConnection, Rule: decision.Rule, StatementType: stmtType, RowsAffected: &result.RowsAffected}
+	if scriptErr := result.ScriptError(); scriptErr != "" {
+		entry.Action = "EXECUTION_ERROR: " + scriptErr
+	}
+	s.logAuditEntry(ctx, entry)
 
 	if s.config.Logging.VerboseLogging {
 		msg := fmt.Sprintf("[debug] Execute File Action: %s, Connection: %s, File: %s\n", stmtType, displayConnection, filePath)
@@ -594,12 +983,11 @@ func (s *Server) handleExecuteSQLFile(req *jsonRPCRequest, args map[string]inter
 		}
 		s.lastVerboseLog.mu.Unlock()
 		if !dup {
-			fmt.Fprint(os.Stderr, msg)
+			fmt.Fprint(os.Stderr, s.redactor.Redact(msg))
 		}
 	}
 
-	resultJSON, _ := json.MarshalIndent(result, "", "  ")
-	s.sendToolResult(req.ID, string(resultJSON))
+	s.sendExecutionResult(req.ID, result)
 }
 
 // handleListConnections handles the list_connections tool.
@@ -614,8 +1002,8 @@ func (s *Server) handleListConnections(req *jsonRPCRequest) {
 	s.sendToolResult(req.ID, string(resultJSON))
 }
 
-// handleQueryToCSVFile handles the query_to_csv_file tool. No confirmation dialog; file_path must be absolute.
-func (s *Server) handleQueryToCSVFile(req *jsonRPCRequest, args map[string]interface{}) {
+// handleQueryToCSVFile handles the query_to_csv_file tool. Only security.rules can ask for review or refuse it (danger_keywords do not apply); file_path must be absolute.
+func (s *Server) handleQueryToCSVFile(ctx context.Context, req *jsonRPCRequest, args map[string]interface{}) {
 	sqlArg, ok := args["sql"]
 	if !ok {
 		s.sendToolError(req.ID, "Missing required parameter: sql")
@@ -648,6 +1036,13 @@ func (s *Server) handleQueryToCSVFile(req *jsonRPCRequest, args map[string]inter
 		return
 	}
 
+	binds, err := oracle.ParseBinds(args["binds"])
+	if err != nil {
+		s.sendToolError(req.ID, fmt.Sprintf("Invalid binds: %v", err))
+		return
+	}
+	bindLines := oracle.FormatBinds(binds)
+
 	connectionName := ""
 	if c, ok := args["connection"]; ok && c != nil {
 		if cs, ok := c.(string); ok {
@@ -667,11 +1062,32 @@ func (s *Server) handleQueryToCSVFile(req *jsonRPCRequest, args map[string]inter
 	if displayConnection == "" {
 		displayConnection = "default"
 	}
-
-	ctx := context.Background()
-	rowsWritten, err := s.executorPool.ExecuteToCSVFile(ctx, connectionName, sqlStr, filePath)
+	timeout, err := s.timeoutArg(args, connectionName)
+	if err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
+	if s.refuseOnReadOnly(ctx, req.ID, s.analyzer.Analyze(sqlStr), bindLines, connectionName, displayConnection) {
+		return
+	}
+	decision := s.policy.EvaluateRules(sqlStr, displayConnection)
+	if !s.applyPolicy(ctx, req.ID, decision, &confirm.ConfirmRequest{
+		SQL:             sqlStr,
+		StatementType:   sqlanalyzer.GetStatementType(sqlStr),
+		Connection:      displayConnection,
+		ConnectionIndex: connectionIndexInPool(s.executorPool, displayConnection),
+		SourceLabel:     "Output: " + filePath,
+		Binds:           bindLines,
+		Transaction:     s.transactionLabel(connectionName, false),
+	}) {
+		return
+	}
+	rowsWritten, err := s.executorPool.ExecuteToCSVFile(ctx, connectionName, sqlStr, filePath, oracle.ExecOptions{Binds: binds, Timeout: timeout, Session: s.session})
 	if err != nil {
-		s.logAudit(sqlStr, nil, false, "QUERY_TO_CSV_ERROR: "+err.Error(), displayConnection)
+		if s.reportInterrupted(ctx, req.ID, err, true, sqlStr, nil, bindLines, displayConnection) {
+			return
+		}
+		s.logAuditRule(ctx, sqlStr, nil, bindLines, false, "QUERY_TO_CSV_ERROR: "+err.Error(), displayConnection, decision.Rule)
 		if strings.Contains(strings.ToLower(err.Error()), "unavailable") || strings.Contains(strings.ToLower(err.Error()), "connection") {
 			s.sendToolError(req.ID, "Connection is currently unavailable; call list_connections to retry.")
 		} else {
@@ -680,7 +1096,8 @@ func (s *Server) handleQueryToCSVFile(req *jsonRPCRequest, args map[string]inter
 		return
 	}
 
-	s.logAudit(sqlStr, nil, true, "QUERY_TO_CSV", displayConnection)
+	s.logAuditEntry(ctx, audit.Entry{SQL: sqlStr, Binds: bindLines, Approved: true, Action: "QUERY_TO_CSV",
+		Connection: displayConnection, Rule: decision.Rule, RowsAffected: &rowsWritten})
 	out := map[string]interface{}{
 		"file_path":     filePath,
 		"rows_written":  rowsWritten,
@@ -690,8 +1107,8 @@ func (s *Server) handleQueryToCSVFile(req *jsonRPCRequest, args map[string]inter
 	s.sendToolResult(req.ID, string(resultJSON))
 }
 
-// handleQueryToTextFile handles the query_to_text_file tool. No confirmation dialog; file_path must be absolute.
-func (s *Server) handleQueryToTextFile(req *jsonRPCRequest, args map[string]interface{}) {
+// handleQueryToTextFile handles the query_to_text_file tool. Only security.rules can ask for review or refuse it (danger_keywords do not apply); file_path must be absolute.
+func (s *Server) handleQueryToTextFile(ctx context.Context, req *jsonRPCRequest, args map[string]interface{}) {
 	sqlArg, ok := args["sql"]
 	if !ok {
 		s.sendToolError(req.ID, "Missing required parameter: sql")
@@ -724,6 +1141,13 @@ func (s *Server) handleQueryToTextFile(req *jsonRPCRequest, args map[string]inte
 		return
 	}
 
+	binds, err := oracle.ParseBinds(args["binds"])
+	if err != nil {
+		s.sendToolError(req.ID, fmt.Sprintf("Invalid binds: %v", err))
+		return
+	}
+	bindLines := oracle.FormatBinds(binds)
+
 	connectionName := ""
 	if c, ok := args["connection"]; ok && c != nil {
 		if cs, ok := c.(string); ok {
@@ -743,11 +1167,32 @@ func (s *Server) handleQueryToTextFile(req *jsonRPCRequest, args map[string]inte
 	if displayConnection == "" {
 		displayConnection = "default"
 	}
-
-	ctx := context.Background()
-	rowsWritten, err := s.executorPool.ExecuteToTextFile(ctx, connectionName, sqlStr, filePath)
+	timeout, err := s.timeoutArg(args, connectionName)
+	if err != nil {
+		s.sendToolError(req.ID, err.Error())
+		return
+	}
+	if s.refuseOnReadOnly(ctx, req.ID, s.analyzer.Analyze(sqlStr), bindLines, connectionName, displayConnection) {
+		return
+	}
+	decision := s.policy.EvaluateRules(sqlStr, displayConnection)
+	if !s.applyPolicy(ctx, req.ID, decision, &confirm.ConfirmRequest{
+		SQL:             sqlStr,
+		StatementType:   sqlanalyzer.GetStatementType(sqlStr),
+		Connection:      displayConnection,
+		ConnectionIndex: connectionIndexInPool(s.executorPool, displayConnection),
+		SourceLabel:     "Output: " + filePath,
+		Binds:           bindLines,
+		Transaction:     s.transactionLabel(connectionName, false),
+	}) {
+		return
+	}
+	rowsWritten, err := s.executorPool.ExecuteToTextFile(ctx, connectionName, sqlStr, filePath, oracle.ExecOptions{Binds: binds, Timeout: timeout, Session: s.session})
 	if err != nil {
-		s.logAudit(sqlStr, nil, false, "QUERY_TO_TEXT_ERROR: "+err.Error(), displayConnection)
+		if s.reportInterrupted(ctx, req.ID, err, true, sqlStr, nil, bindLines, displayConnection) {
+			return
+		}
+		s.logAuditRule(ctx, sqlStr, nil, bindLines, false, "QUERY_TO_TEXT_ERROR: "+err.Error(), displayConnection, decision.Rule)
 		if strings.Contains(strings.ToLower(err.Error()), "unavailable") || strings.Contains(strings.ToLower(err.Error()), "connection") {
 			s.sendToolError(req.ID, "Connection is currently unavailable; call list_connections to retry.")
 		} else {
@@ -756,7 +1201,8 @@ func (s *Server) handleQueryToTextFile(req *jsonRPCRequest, args map[string]inte
 		return
 	}
 
-	s.logAudit(sqlStr, nil, true, "QUERY_TO_TEXT", displayConnection)
+	s.logAuditEntry(ctx, audit.Entry{SQL: sqlStr, Binds: bindLines, Approved: true, Action: "QUERY_TO_TEXT",
+		Connection: displayConnection, Rule: decision.Rule, RowsAffected: &rowsWritten})
 	out := map[string]interface{}{
 		"file_path":     filePath,
 		"rows_written":  rowsWritten,
@@ -826,8 +1272,21 @@ func (s *Server) sendResponse(resp *jsonRPCResponse) {
 		return
 	}
 
-	s.writer.Write(data)
-	s.writer.Write([]byte("\n"))
+	s.writer.Write(append(data, '\n'))
+}
+
+// sendMessage writes any JSON-RPC message (e.g. a server-initiated request) to the client.
+func (s *Server) sendMessage(msg interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to marshal message: %v\n", err)
+		return
+	}
+
+	s.writer.Write(append(data, '\n'))
 }
 
 // logNotificationParams is the params for MCP notifications/message (structured logging).
@@ -846,6 +1305,7 @@ type logNotificationMessage struct {
 
 // sendLogNotification sends an MCP log notification so the client (e.g. Cursor) can show it with the correct level (debug/info/error).
 // Uses stdout as a proper JSON-RPC notification; do not use stderr for this so the client can display debug vs error correctly.
+// Secrets in message are masked (security.redact).
 func (s *Server) sendLogNotification(level, message string) {
 	msg := logNotificationMessage{
 		JSONRPC: "2.0",
@@ -853,7 +1313,7 @@ func (s *Server) sendLogNotification(level, message string) {
 		Params: logNotificationParams{
 			Level:  level,
 			Logger: "oracle-mcp",
-			Data:   map[string]string{"message": message},
+			Data:   map[string]string{"message": s.redactor.Redact(message)},
 		},
 	}
 	s.mu.Lock()
@@ -862,15 +1322,62 @@ func (s *Server) sendLogNotification(level, message string) {
 	if err != nil {
 		return
 	}
-	s.writer.Write(data)
-	s.writer.Write([]byte("\n"))
+	s.writer.Write(append(data, '\n'))
 }
 
-// logAudit logs an audit entry if auditing is enabled. connection is the DB alias (e.g. "database1", "database2").
-func (s *Server) logAudit(sql string, keywords []string, approved bool, action string, connection string) {
-	if s.auditor != nil {
-		s.auditor.Log(sql, keywords, approved, action, connection)
+// onAuditError reports an audit log failure (disk full, permissions, ...) on stderr and to the client, since the
+// calls themselves go on.
+func (s *Server) onAuditError(err error) {
+	fmt.Fprintf(os.Stderr, "oracle-mcp: %v\n", err)
+	s.sendLogNotification("error", "Audit log: "+err.Error())
+}
+
+// auditCallKey is the context key of the auditCall of a tools/call.
+type auditCallKey struct{}
+
+// auditCall is what the audit log records about the tools/call a context belongs to.
+type auditCall struct {
+	requestID string
+	start     time.Time
+}
+
+// logAudit logs an audit entry if auditing is enabled. connection is the DB alias (e.g. "database1", "database2");
+// binds are the display strings from oracle.FormatBinds (nil when the statement has no bind variables).
+func (s *Server) logAudit(ctx context.Context, sql string, keywords []string, binds []string, approved bool, action string, connection string) {
+	s.logAuditRule(ctx, sql, keywords, binds, approved, action, connection, "")
+}
+
+// logAuditRule is logAudit for calls checked by the policy engine; rule is the rule that decided ("" for none).
+func (s *Server) logAuditRule(ctx context.Context, sql string, keywords []string, binds []string, approved bool, action string, connection string, rule string) {
+	s.logAuditEntry(ctx, audit.Entry{SQL: sql, Keywords: keywords, Binds: binds, Approved: approved, Action: action, Connection: connection, Rule: rule})
+}
+
+// logAuditEntry completes e with the statement type, the request id and duration of the call in ctx and the client
+// from initialize, masks secrets in its SQL, binds and action, and logs it if auditing is enabled.
+func (s *Server) logAuditEntry(ctx context.Context, e audit.Entry) {
+	if s.auditor == nil {
+		return
 	}
+	if e.StatementType == "" {
+		e.StatementType = sqlanalyzer.GetStatementType(e.SQL)
+	}
+	e.SQL = s.redactor.Redact(e.SQL)
+	e.Binds = s.redactor.RedactAll(e.Binds)
+	e.Action = s.redactor.Redact(e.Action)
+	if call, ok := ctx.Value(auditCallKey{}).(*auditCall); ok {
+		e.RequestID = call.requestID
+		e.DurationMS = time.Since(call.start).Milliseconds()
+	}
+	e.Client, _ = s.clientInfo()
+	e.Token = s.token
+	s.auditor.Write(e)
+}
+
+// clientInfo returns the clientInfo from initialize and whether the client supports elicitation.
+func (s *Server) clientInfo() (*audit.Client, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.client, s.clientElicitation
 }
 
 // connectionIndexInPool returns the 0-based index of the named connection for review UI header color (Java parity).
@@ -0,0 +1,469 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvin/oracle-mcp-server/internal/audit"
+	"github.com/alvin/oracle-mcp-server/internal/config"
+	"github.com/alvin/oracle-mcp-server/internal/confirm"
+)
+
+// unreachableDSN points at a closed port, so the connection is marked unavailable at startup and
+// execution fails fast after the confirmation step. Tests never need a real database.
+const unreachableDSN = "scott/tiger@//127.0.0.1:1/NOPE"
+
+// testServer wraps a Server whose stdin/stdout are replaced by in-memory buffers.
+type testServer struct {
+	*Server
+	out      *bytes.Buffer
+	auditDir string
+}
+
+func newTestServer(t *testing.T, confirmer confirm.Confirmer, opts ...func(*config.Config)) *testServer {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Oracle.Connections = map[string]config.ConnectionConfig{"db1": {DSN: unreachableDSN}}
+	cfg.Logging.VerboseLogging = false
+	cfg.Logging.LogFile = filepath.Join(dir, "audit.log")
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	s, err := NewServer(cfg, confirmer)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(s.Close)
+	out := &bytes.Buffer{}
+	s.writer = out
+	return &testServer{Server: s, out: out, auditDir: dir}
+}
+
+// send feeds JSON-RPC lines through processRequest and returns the decoded responses written to stdout.
+// After each line it waits until every tools/call has finished or is waiting for a client response,
+// so the output order is deterministic.
+func (ts *testServer) send(t *testing.T, lines ...string) []map[string]interface{} {
+	t.Helper()
+	ts.out.Reset()
+	ts.reader = bufio.NewReader(strings.NewReader(strings.Join(lines, "\n") + "\n"))
+	for {
+		err := ts.processRequest()
+		ts.settle(t)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			t.Fatalf("processRequest: %v", err)
+		}
+	}
+	var responses []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(ts.out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid JSON response %q: %v", line, err)
+		}
+		responses = append(responses, m)
+	}
+	return responses
+}
+
+// settle waits until each running tools/call is waiting for a client response (or none is running).
+func (ts *testServer) settle(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ts.inflightMu.Lock()
+		running := len(ts.inflight)
+		ts.inflightMu.Unlock()
+		ts.pendingMu.Lock()
+		waiting := len(ts.pending)
+		ts.pendingMu.Unlock()
+		if running == waiting {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d tools/call requests still running after 5s", running-waiting)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// auditEntries parses every AUDIT_* record written so far.
+func (ts *testServer) auditEntries(t *testing.T) []map[string]string {
+	t.Helper()
+	files, _ := filepath.Glob(filepath.Join(ts.auditDir, "audit_*.log"))
+	var entries []map[string]string
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("read audit log: %v", err)
+		}
+		for _, rec := range strings.Split(string(data), "######AUDIT_END######\n") {
+			if strings.TrimSpace(rec) == "" {
+				continue
+			}
+			e := map[string]string{}
+			header, sql, _ := strings.Cut(rec, "AUDIT_SQL=\n")
+			for _, line := range strings.Split(header, "\n") {
+				if k, v, ok := strings.Cut(line, "="); ok {
+					e[k] = v
+				}
+			}
+			e["AUDIT_SQL"] = strings.TrimSuffix(sql, "\n")
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func toolCall(id int, name string, args map[string]interface{}) string {
+	b, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  "tools/call",
+		"params":  map[string]interface{}{"name": name, "arguments": args},
+	})
+	return string(b)
+}
+
+// toolText returns the text content and isError flag of a tools/call result.
+func toolText(t *testing.T, resp map[string]interface{}) (string, bool) {
+	t.Helper()
+	result, ok := resp["result"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("response has no result: %v", resp)
+	}
+	content := result["content"].([]interface{})
+	text := content[0].(map[string]interface{})["text"].(string)
+	isError, _ := result["isError"].(bool)
+	return text, isError
+}
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	tests := []struct {
+		requested string
+		want      string
+	}{
+		{"2025-06-18", "2025-06-18"},
+		{"2024-11-05", "2024-11-05"},
+		{"2099-01-01", "2025-06-18"}, // newer than we speak
+		{"", "2025-06-18"},
+	}
+	for _, tt := range tests {
+		if got := negotiateProtocolVersion(tt.requested); got != tt.want {
+			t.Errorf("negotiateProtocolVersion(%q) = %q, want %q", tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestProcessRequest_Protocol(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysApprove())
+
+	resps := ts.send(t,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"0"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"no/such"}`,
+		`{not json`,
+	)
+	if len(resps) != 5 {
+		t.Fatalf("got %d responses, want 5 (notification must not be answered): %v", len(resps), resps)
+	}
+	if !ts.initialized {
+		t.Error("initialized = false after initialize")
+	}
+	if got := resps[1]["result"].(map[string]interface{})["status"]; got != "ok" {
+		t.Errorf("ping status = %v, want ok", got)
+	}
+	var names []string
+	for _, tl := range resps[2]["result"].(map[string]interface{})["tools"].([]interface{}) {
+		names = append(names, tl.(map[string]interface{})["name"].(string))
+	}
+	for _, want := range []string{"execute_sql", "execute_sql_file", "list_connections", "query_to_csv_file", "query_to_text_file", "list_schemas", "list_tables", "describe_table", "search_objects", "get_ddl", "explain_plan", "search_audit"} {
+		found := false
+		for _, n := range names {
+			if n == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("tools/list missing %q (got %v)", want, names)
+		}
+	}
+	if code := resps[3]["error"].(map[string]interface{})["code"].(float64); code != ErrCodeMethodNotFound {
+		t.Errorf("unknown method code = %v, want %d", code, ErrCodeMethodNotFound)
+	}
+	if code := resps[4]["error"].(map[string]interface{})["code"].(float64); code != ErrCodeParseError {
+		t.Errorf("parse error code = %v, want %d", code, ErrCodeParseError)
+	}
+}
+
+func TestExecuteSQL_UserRejected(t *testing.T) {
+	c := confirm.AlwaysReject()
+	ts := newTestServer(t, c)
+
+	resps := ts.send(t, toolCall(1, "execute_sql", map[string]interface{}{"sql": "DROP TABLE t"}))
+	if len(resps) != 1 {
+		t.Fatalf("got %d responses, want 1", len(resps))
+	}
+	rpcErr, ok := resps[0]["error"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("want JSON-RPC error, got %v", resps[0])
+	}
+	if rpcErr["code"].(float64) != ErrCodeUserRejected {
+		t.Errorf("code = %v, want %d", rpcErr["code"], ErrCodeUserRejected)
+	}
+	if rpcErr["data"].(map[string]interface{})["code"] != "USER_REJECTED" {
+		t.Errorf("data.code = %v, want USER_REJECTED", rpcErr["data"])
+	}
+
+	reqs := c.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("confirmer saw %d requests, want 1", len(reqs))
+	}
+	if reqs[0].StatementType != "DROP" || !reqs[0].IsDDL || reqs[0].Connection != "db1" {
+		t.Errorf("confirm request = %+v", reqs[0])
+	}
+
+	entries := ts.auditEntries(t)
+	if len(entries) != 1 {
+		t.Fatalf("got %d audit entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e["AUDIT_ACTION"] != "USER_REJECTED" || e["AUDIT_APPROVED"] != "false" || e["AUDIT_CONNECTION"] != "db1" || e["AUDIT_SQL"] != "DROP TABLE t" {
+		t.Errorf("audit entry = %v", e)
+	}
+}
+
+func TestExecuteSQL_JSONLAudit(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysReject(), func(cfg *config.Config) {
+		cfg.Logging.AuditFormat = "jsonl"
+		cfg.Logging.LogFile = filepath.Join(filepath.Dir(cfg.Logging.LogFile), "audit.jsonl")
+	})
+
+	ts.send(t,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"siem-test","version":"2.1"}}}`,
+		toolCall(7, "execute_sql", map[string]interface{}{"sql": "DROP TABLE t"}),
+		toolCall(8, "execute_sql", map[string]interface{}{"sql": "TRUNCATE TABLE t"}),
+	)
+	files, _ := filepath.Glob(filepath.Join(ts.auditDir, "audit_*.jsonl"))
+	if len(files) != 1 {
+		t.Fatalf("got %d JSONL audit files, want 1", len(files))
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d records, want 2:\n%s", len(lines), data)
+	}
+	var first, second audit.Entry
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	if first.Seq != 1 || first.RequestID != "7" || first.StatementType != "DROP" || first.Action != "USER_REJECTED" ||
+		first.Connection != "db1" || first.Client == nil || first.Client.Name != "siem-test" || first.Client.Version != "2.1" {
+		t.Errorf("first record = %+v", first)
+	}
+	if second.Seq != 2 || second.RequestID != "8" || second.PrevHash == "" {
+		t.Errorf("second record = %+v", second)
+	}
+	if _, err := audit.Verify(filepath.Join(ts.auditDir, "audit.jsonl")); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+}
+
+func TestExecuteSQL_Redaction(t *testing.T) {
+	const sql = "ALTER USER app IDENTIFIED BY s3cret"
+	tests := []struct {
+		name       string
+		opt        func(*config.Config)
+		wantReview string
+	}{
+		{"default", func(*config.Config) {}, "ALTER USER app IDENTIFIED BY ***"},
+		{"pattern", func(cfg *config.Config) { cfg.Security.RedactPatterns = []string{`\bapp\b`} }, "ALTER USER *** IDENTIFIED BY ***"},
+		{"off", func(cfg *config.Config) { cfg.Security.Redact = false }, sql},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := confirm.AlwaysReject()
+			ts := newTestServer(t, c, tt.opt)
+			ts.send(t, toolCall(1, "execute_sql", map[string]interface{}{"sql": sql, "binds": map[string]interface{}{"new_password": "hunter2"}}))
+
+			reqs := c.Requests()
+			if len(reqs) != 1 {
+				t.Fatalf("got %d reviews, want 1", len(reqs))
+			}
+			review := reqs[0]
+			if review.SQL != tt.wantReview {
+				t.Errorf("review SQL = %q, want %q", review.SQL, tt.wantReview)
+			}
+			if masked := tt.wantReview != sql; masked {
+				if review.RevealSQL != sql || len(review.RevealBinds) != 1 || !strings.Contains(review.RevealBinds[0], "hunter2") {
+					t.Errorf("review reveals %q %v, want the SQL and binds as they run", review.RevealSQL, review.RevealBinds)
+				}
+				if strings.Contains(strings.Join(review.Binds, ""), "hunter2") {
+					t.Errorf("review binds = %v, want the password masked", review.Binds)
+				}
+			} else if review.Masked() {
+				t.Errorf("review with redaction off is masked: %+v", review)
+			}
+			entries := ts.auditEntries(t)
+			if len(entries) != 1 || entries[0]["AUDIT_SQL"] != tt.wantReview ||
+				strings.Contains(entries[0]["AUDIT_BINDS"], "hunter2") != (tt.wantReview == sql) {
+				t.Errorf("audit entries = %v, want SQL %q", entries, tt.wantReview)
+			}
+		})
+	}
+}
+
+func TestExecuteSQL_ApprovedRunsStatement(t *testing.T) {
+	c := confirm.AlwaysApprove()
+	ts := newTestServer(t, c)
+
+	resps := ts.send(t, toolCall(1, "execute_sql", map[string]interface{}{"sql": "DELETE FROM t", "connection": "db1"}))
+	text, isError := toolText(t, resps[0])
+	// The connection is unreachable, so reaching the executor proves the approve path was taken.
+	if !isError || !strings.Contains(text, "SQL execution failed") {
+		t.Errorf("result = %q (isError=%v), want execution failure", text, isError)
+	}
+	if len(c.Requests()) != 1 {
+		t.Errorf("confirmer saw %d requests, want 1", len(c.Requests()))
+	}
+	entries := ts.auditEntries(t)
+	if len(entries) != 1 || !strings.HasPrefix(entries[0]["AUDIT_ACTION"], "EXECUTION_ERROR") || entries[0]["AUDIT_APPROVED"] != "true" {
+		t.Errorf("audit entries = %v", entries)
+	}
+}
+
+func TestExecuteSQL_ConfirmError(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysError(errors.New("no display")))
+
+	resps := ts.send(t, toolCall(1, "execute_sql", map[string]interface{}{"sql": "TRUNCATE TABLE t"}))
+	text, isError := toolText(t, resps[0])
+	if !isError || !strings.Contains(text, "Confirmation dialog error: no display") {
+		t.Errorf("result = %q (isError=%v)", text, isError)
+	}
+	entries := ts.auditEntries(t)
+	if len(entries) != 1 || entries[0]["AUDIT_ACTION"] != "CONFIRM_ERROR: no display" || entries[0]["AUDIT_APPROVED"] != "false" {
+		t.Errorf("audit entries = %v", entries)
+	}
+}
+
+func TestExecuteSQL_SafeSQLSkipsConfirmation(t *testing.T) {
+	c := confirm.NewScripted() // any Confirm call would fail with ErrNoScriptedAnswer
+	ts := newTestServer(t, c)
+
+	resps := ts.send(t, toolCall(1, "execute_sql", map[string]interface{}{"sql": "SELECT * FROM dual"}))
+	if _, isError := toolText(t, resps[0]); !isError {
+		t.Error("want execution error on unreachable connection")
+	}
+	if len(c.Requests()) != 0 {
+		t.Errorf("confirmer saw %d requests, want 0", len(c.Requests()))
+	}
+	entries := ts.auditEntries(t)
+	if len(entries) != 1 || !strings.HasPrefix(entries[0]["AUDIT_ACTION"], "EXECUTION_ERROR") {
+		t.Errorf("audit entries = %v", entries)
+	}
+}
+
+func TestExecuteSQL_MissingSQL(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysApprove())
+
+	resps := ts.send(t, toolCall(1, "execute_sql", map[string]interface{}{}))
+	text, isError := toolText(t, resps[0])
+	if !isError || text != "Missing required parameter: sql" {
+		t.Errorf("result = %q (isError=%v)", text, isError)
+	}
+	if len(ts.auditEntries(t)) != 0 {
+		t.Error("argument errors must not be audited")
+	}
+}
+
+func TestExecuteSQL_BindsShownAndAudited(t *testing.T) {
+	c := confirm.NewScripted(confirm.Answer{Approved: false})
+	ts := newTestServer(t, c)
+
+	resps := ts.send(t, toolCall(1, "execute_sql", map[string]interface{}{
+		"sql":   "DELETE FROM t WHERE id = :id AND created < :cutoff",
+		"binds": map[string]interface{}{"id": 42, "cutoff": map[string]interface{}{"type": "date", "value": "2024-01-31"}},
+	}))
+	if _, ok := resps[0]["error"]; !ok {
+		t.Fatalf("want USER_REJECTED error, got %v", resps[0])
+	}
+	want := []string{":cutoff = '2024-01-31' (date)", ":id = 42 (number)"}
+	if reqs := c.Requests(); len(reqs) != 1 || strings.Join(reqs[0].Binds, "|") != strings.Join(want, "|") {
+		t.Errorf("confirm requests = %+v, want binds %q", reqs, want)
+	}
+	entries := ts.auditEntries(t)
+	if len(entries) != 1 || entries[0]["AUDIT_BINDS"] != strings.Join(want, "; ") {
+		t.Errorf("audit entries = %v", entries)
+	}
+}
+
+func TestExecuteSQL_InvalidBinds(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysApprove())
+
+	resps := ts.send(t, toolCall(1, "execute_sql", map[string]interface{}{"sql": "SELECT :1 FROM dual", "binds": "42"}))
+	text, isError := toolText(t, resps[0])
+	if !isError || !strings.HasPrefix(text, "Invalid binds:") {
+		t.Errorf("result = %q (isError=%v)", text, isError)
+	}
+	if len(ts.auditEntries(t)) != 0 {
+		t.Error("argument errors must not be audited")
+	}
+}
+
+func TestExecuteSQLFile_RecordedAnswers(t *testing.T) {
+	c := confirm.NewScripted(confirm.Answer{Approved: false}, confirm.Answer{Approved: true})
+	ts := newTestServer(t, c)
+
+	path := filepath.Join(t.TempDir(), "script.sql")
+	if err := os.WriteFile(path, []byte("DROP TABLE a;\nDROP TABLE b;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	call := toolCall(1, "execute_sql_file", map[string]interface{}{"file_path": path})
+	resps := ts.send(t, call, call, call)
+	if len(resps) != 3 {
+		t.Fatalf("got %d responses, want 3", len(resps))
+	}
+	if _, ok := resps[0]["error"]; !ok {
+		t.Errorf("first call: want USER_REJECTED error, got %v", resps[0])
+	}
+	if text, isError := toolText(t, resps[1]); !isError || !strings.Contains(text, "SQL execution failed") {
+		t.Errorf("second call: result = %q", text)
+	}
+	if text, _ := toolText(t, resps[2]); !strings.Contains(text, confirm.ErrNoScriptedAnswer.Error()) {
+		t.Errorf("third call: result = %q, want scripted answers exhausted", text)
+	}
+
+	reqs := c.Requests()
+	if len(reqs) != 3 || reqs[0].SourceLabel != "File: "+path {
+		t.Errorf("confirm requests = %+v", reqs)
+	}
+	var actions []string
+	for _, e := range ts.auditEntries(t) {
+		actions = append(actions, e["AUDIT_ACTION"])
+	}
+	if len(actions) != 3 || actions[0] != "USER_REJECTED" || !strings.HasPrefix(actions[1], "EXECUTION_ERROR") || !strings.HasPrefix(actions[2], "CONFIRM_ERROR") {
+		t.Errorf("audit actions = %v", actions)
+	}
+}
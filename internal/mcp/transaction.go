@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ddlInTransactionWarning is added to the result of DDL that ran while a transaction was open.
+const ddlInTransactionWarning = "Oracle DDL commits implicitly: the work done earlier in this transaction is now committed and its savepoints are gone. The transaction stays open for further statements."
+
+// handleBeginTransaction handles the begin_transaction tool.
+func (s *Server) handleBeginTransaction(ctx context.Context, req *jsonRPCRequest, args map[string]interface{}) {
+	connectionName, displayConnection := s.connectionArg(args)
+	info, err := s.executorPool.BeginTransaction(ctx, s.session, connectionName)
+	if err != nil {
+		s.logAudit(ctx, "BEGIN TRANSACTION", nil, nil, true, "TRANSACTION_BEGIN_ERROR: "+err.Error(), displayConnection)
+		s.sendToolError(req.ID, "begin_transaction failed: "+err.Error())
+		return
+	}
+	s.logAudit(ctx, "BEGIN TRANSACTION", nil, nil, true, "TRANSACTION_BEGIN", info.Connection)
+	s.sendTransactionResult(req.ID, map[string]interface{}{
+		"transaction": info,
+		"message":     fmt.Sprintf("Transaction open on %s. Statements on this connection are not committed until commit; rollback undoes them.", info.Connection),
+	})
+}
+
+// handleCommit handles the commit tool.
+func (s *Server) handleCommit(ctx context.Context, req *jsonRPCRequest, args map[string]interface{}) {
+	connectionName, displayConnection := s.connectionArg(args)
+	info, err := s.executorPool.Commit(s.session, connectionName)
+	if err != nil {
+		s.logAudit(ctx, "COMMIT", nil, nil, true, "COMMIT_ERROR: "+err.Error(), displayConnection)
+		s.sendToolError(req.ID, "commit failed: "+err.Error())
+		return
+	}
+	s.logAudit(ctx, "COMMIT", nil, nil, true, "COMMIT", info.Connection)
+	s.sendTransactionResult(req.ID, map[string]interface{}{
+		"connection": info.Connection,
+		"message":    "Transaction committed; the connection is back in autocommit mode.",
+	})
+}
+
+// handleRollback handles the rollback tool (whole transaction, or to a savepoint).
+func (s *Server) handleRollback(ctx context.Context, req *jsonRPCRequest, args map[string]interface{}) {
+	connectionName, displayConnection := s.connectionArg(args)
+	savepoint := ""
+	if v, ok := args["savepoint"].(string); ok {
+		savepoint = strings.TrimSpace(v)
+	}
+	auditSQL := "ROLLBACK"
+	if savepoint != "" {
+		auditSQL = "ROLLBACK TO SAVEPOINT " + strings.ToUpper(savepoint)
+	}
+
+	info, err := s.executorPool.Rollback(ctx, s.session, connectionName, savepoint)
+	if err != nil {
+		s.logAudit(ctx, auditSQL, nil, nil, true, "ROLLBACK_ERROR: "+err.Error(), displayConnection)
+		s.sendToolError(req.ID, "rollback failed: "+err.Error())
+		return
+	}
+	s.logAudit(ctx, auditSQL, nil, nil, true, "ROLLBACK", info.Connection)
+	if savepoint != "" {
+		s.sendTransactionResult(req.ID, map[string]interface{}{
+			"transaction": info,
+			"message":     fmt.Sprintf("Rolled back to savepoint %s; the transaction is still open.", strings.ToUpper(savepoint)),
+		})
+		return
+	}
+	s.sendTransactionResult(req.ID, map[string]interface{}{
+		"connection": info.Connection,
+		"message":    "Transaction rolled back; the connection is back in autocommit mode.",
+	})
+}
+
+// handleSavepoint handles the savepoint tool.
+func (s *Server) handleSavepoint(ctx context.Context, req *jsonRPCRequest, args map[string]interface{}) {
+	name, ok := args["name"].(string)
+	if !ok || strings.TrimSpace(name) == "" {
+		s.sendToolError(req.ID, "Missing required parameter: name")
+		return
+	}
+	name = strings.TrimSpace(name)
+	connectionName, displayConnection := s.connectionArg(args)
+
+	info, err := s.executorPool.Savepoint(ctx, s.session, connectionName, name)
+	if err != nil {
+		s.logAudit(ctx, "SAVEPOINT "+name, nil, nil, true, "SAVEPOINT_ERROR: "+err.Error(), displayConnection)
+		s.sendToolError(req.ID, "savepoint failed: "+err.Error())
+		return
+	}
+	s.logAudit(ctx, "SAVEPOINT "+strings.ToUpper(name), nil, nil, true, "SAVEPOINT", info.Connection)
+	s.sendTransactionResult(req.ID, map[string]interface{}{
+		"transaction": info,
+		"message":     fmt.Sprintf("Savepoint %s set.", strings.ToUpper(name)),
+	})
+}
+
+// sendTransactionResult sends out as the indented JSON tool result.
+func (s *Server) sendTransactionResult(id interface{}, out map[string]interface{}) {
+	resultJSON, _ := json.MarshalIndent(out, "", "  ")
+	s.sendToolResult(id, string(resultJSON))
+}
+
+// connectionArg returns the optional "connection" argument and its display name for audit
+// (the only configured name when the argument is omitted and there is just one connection).
+func (s *Server) connectionArg(args map[string]interface{}) (connectionName, displayConnection string) {
+	if c, ok := args["connection"].(string); ok {
+		connectionName = strings.TrimSpace(c)
+	}
+	displayConnection = connectionName
+	if displayConnection == "" {
+		if names := s.executorPool.Names(); len(names) == 1 {
+			displayConnection = names[0]
+		}
+	}
+	return connectionName, displayConnection
+}
+
+// transactionLabel describes the open transaction on the connection for the review dialog, or "" when none is open.
+func (s *Server) transactionLabel(connectionName string, isDDL bool) string {
+	info, ok := s.executorPool.Transaction(s.session, connectionName)
+	if !ok {
+		return ""
+	}
+	label := "open since " + info.Started.Format("15:04:05")
+	if len(info.Savepoints) > 0 {
+		label += " (savepoints: " + strings.Join(info.Savepoints, ", ") + ")"
+	}
+	if isDDL {
+		label += "; this DDL will commit it"
+	}
+	return label
+}
+
+// onIdleRollback records a transaction that the pool rolled back after the idle timeout and tells the client.
+func (s *Server) onIdleRollback(connection string) {
+	s.logAudit(context.Background(), "ROLLBACK", nil, nil, true, "TRANSACTION_IDLE_ROLLBACK", connection)
+	s.sendLogNotification("warning", fmt.Sprintf("Transaction on %s was rolled back after %d seconds without activity.",
+		connection, s.config.Oracle.TransactionIdleTimeoutSeconds))
+}
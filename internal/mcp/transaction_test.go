@@ -0,0 +1,45 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alvin/oracle-mcp-server/internal/confirm"
+)
+
+func TestTransactionTools_WithoutDatabase(t *testing.T) {
+	ts := newTestServer(t, confirm.AlwaysApprove())
+
+	tests := []struct {
+		tool       string
+		args       map[string]interface{}
+		wantText   string
+		wantAction string // "" means the call must not be audited
+	}{
+		{"begin_transaction", map[string]interface{}{}, `begin_transaction failed: connection "db1" is currently unavailable`, "TRANSACTION_BEGIN_ERROR"},
+		{"commit", map[string]interface{}{}, `commit failed: no transaction is open on "db1"`, "COMMIT_ERROR"},
+		{"rollback", map[string]interface{}{"savepoint": "before_fix"}, `rollback failed: no transaction is open on "db1"`, "ROLLBACK_ERROR"},
+		{"savepoint", map[string]interface{}{"name": "1bad"}, `savepoint failed: invalid savepoint name "1bad"`, "SAVEPOINT_ERROR"},
+		{"savepoint", map[string]interface{}{}, "Missing required parameter: name", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			before := len(ts.auditEntries(t))
+			resps := ts.send(t, toolCall(1, tt.tool, tt.args))
+			text, isError := toolText(t, resps[0])
+			if !isError || !strings.HasPrefix(text, tt.wantText) {
+				t.Errorf("result = %q (isError=%v), want prefix %q", text, isError, tt.wantText)
+			}
+			entries := ts.auditEntries(t)
+			if tt.wantAction == "" {
+				if len(entries) != before {
+					t.Errorf("argument errors must not be audited: %v", entries[before:])
+				}
+				return
+			}
+			if len(entries) != before+1 || !strings.HasPrefix(entries[before]["AUDIT_ACTION"], tt.wantAction) || entries[before]["AUDIT_CONNECTION"] != "db1" {
+				t.Errorf("audit entries = %v, want one %s entry", entries[before:], tt.wantAction)
+			}
+		})
+	}
+}
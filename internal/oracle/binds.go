@@ -0,0 +1,216 @@
+// Package oracle: bind variables for execute_sql and the query_to_*_file tools.
+package oracle
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/godror/godror"
+)
+
+// Bind is one bind variable. Name is set for :name binds and empty for positional binds.
+type Bind struct {
+	Name  string
+	Type  string      // string, number, date, timestamp, clob or null
+	Value interface{} // value as given by the client (for display)
+	arg   interface{} // driver value
+}
+
+// bindTypes lists the accepted "type" values for typed bind objects.
+var bindTypes = []string{"string", "number", "date", "timestamp", "clob", "null"}
+
+// dateLayouts and timestampLayouts are the accepted formats for date and timestamp binds.
+var (
+	dateLayouts      = []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05"}
+	timestampLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02T15:04:05.999999999", "2006-01-02"}
+)
+
+// maxBindDisplayLen limits how much of a bind value is shown in review dialogs and the audit log.
+const maxBindDisplayLen = 200
+
+// ParseBinds converts the "binds" tool argument into binds.
+// An object binds by name ({"id": 42} for :id); an array binds by position ([42, "x"] for :1, :2).
+// Each value is a JSON string, number or null, or a typed object {"type": "date", "value": "2024-01-31"}
+// with type string, number, date, timestamp, clob or null. A nil argument yields no binds.
+func ParseBinds(raw interface{}) ([]Bind, error) {
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		// Sorted by name so review dialogs and audit entries list binds in a stable order
+		binds := make([]Bind, 0, len(v))
+		for name := range v {
+			clean := strings.TrimPrefix(strings.TrimSpace(name), ":")
+			if clean == "" {
+				return nil, fmt.Errorf("bind name cannot be empty")
+			}
+			b, err := parseBindValue(v[name])
+			if err != nil {
+				return nil, fmt.Errorf("bind :%s: %w", clean, err)
+			}
+			b.Name = clean
+			binds = append(binds, b)
+		}
+		sort.Slice(binds, func(i, j int) bool { return binds[i].Name < binds[j].Name })
+		return binds, nil
+	case []interface{}:
+		binds := make([]Bind, 0, len(v))
+		for i, item := range v {
+			b, err := parseBindValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("bind :%d: %w", i+1, err)
+			}
+			binds = append(binds, b)
+		}
+		return binds, nil
+	default:
+		return nil, fmt.Errorf("binds must be an object (named) or an array (positional)")
+	}
+}
+
+// parseBindValue converts one JSON value (plain or typed object) into a Bind without name.
+func parseBindValue(raw interface{}) (Bind, error) {
+	switch v := raw.(type) {
+	case nil:
+		return Bind{Type: "null"}, nil
+	case string:
+		return Bind{Type: "string", Value: v, arg: v}, nil
+	case bool:
+		return Bind{}, fmt.Errorf("boolean values are not supported; use a number or string")
+	case float64:
+		return Bind{Type: "number", Value: v, arg: numberArg(v)}, nil
+	case map[string]interface{}:
+		typ, _ := v["type"].(string)
+		typ = strings.ToLower(strings.TrimSpace(typ))
+		value, hasValue := v["value"]
+		if typ == "" {
+			return Bind{}, fmt.Errorf("typed bind needs \"type\" (one of %s)", strings.Join(bindTypes, ", "))
+		}
+		if typ == "null" {
+			return Bind{Type: "null"}, nil
+		}
+		if !hasValue || value == nil {
+			// Typed NULL, e.g. {"type": "date", "value": null}
+			if !slices.Contains(bindTypes, typ) {
+				return Bind{}, fmt.Errorf("unknown type %q (one of %s)", typ, strings.Join(bindTypes, ", "))
+			}
+			return Bind{Type: typ}, nil
+		}
+		return parseTypedBind(typ, value)
+	default:
+		return Bind{}, fmt.Errorf("unsupported value %v", raw)
+	}
+}
+
+// parseTypedBind converts {"type": typ, "value": value} into a Bind.
+func parseTypedBind(typ string, value interface{}) (Bind, error) {
+	switch typ {
+	case "string", "clob":
+		s, ok := value.(string)
+		if !ok {
+			return Bind{}, fmt.Errorf("%s value must be a string", typ)
+		}
+		if typ == "clob" {
+			return Bind{Type: typ, Value: s, arg: godror.Lob{IsClob: true, Reader: strings.NewReader(s)}}, nil
+		}
+		return Bind{Type: typ, Value: s, arg: s}, nil
+	case "number":
+		switch n := value.(type) {
+		case float64:
+			return Bind{Type: typ, Value: n, arg: numberArg(n)}, nil
+		case string:
+			// Strings keep full precision (e.g. 38-digit NUMBER keys)
+			s := strings.TrimSpace(n)
+			if _, err := strconv.ParseFloat(s, 64); err != nil {
+				return Bind{}, fmt.Errorf("invalid number %q", n)
+			}
+			return Bind{Type: typ, Value: s, arg: godror.Number(s)}, nil
+		default:
+			return Bind{}, fmt.Errorf("number value must be a number or numeric string")
+		}
+	case "date", "timestamp":
+		s, ok := value.(string)
+		if !ok {
+			return Bind{}, fmt.Errorf("%s value must be a string (e.g. 2024-01-31 or 2024-01-31T13:45:00Z)", typ)
+		}
+		layouts := dateLayouts
+		if typ == "timestamp" {
+			layouts = timestampLayouts
+		}
+		for _, layout := range layouts {
+			if t, err := time.ParseInLocation(layout, strings.TrimSpace(s), time.Local); err == nil {
+				return Bind{Type: typ, Value: s, arg: t}, nil
+			}
+		}
+		return Bind{}, fmt.Errorf("invalid %s %q (use 2006-01-02, 2006-01-02 15:04:05 or RFC 3339)", typ, s)
+	default:
+		return Bind{}, fmt.Errorf("unknown type %q (one of %s)", typ, strings.Join(bindTypes, ", "))
+	}
+}
+
+// numberArg passes whole JSON numbers as int64 so Oracle sees an exact integer.
+func numberArg(f float64) interface{} {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int64(f)
+	}
+	return f
+}
+
+// bindArgs returns the driver arguments: sql.Named for named binds, plain values in order for positional binds.
+func bindArgs(binds []Bind) []interface{} {
+	if len(binds) == 0 {
+		return nil
+	}
+	args := make([]interface{}, len(binds))
+	for i, b := range binds {
+		if b.Name != "" {
+			args[i] = sql.Named(b.Name, b.arg)
+		} else {
+			args[i] = b.arg
+		}
+	}
+	return args
+}
+
+// String returns the bind for review dialogs and the audit log, e.g. ":id = 42 (number)".
+// Long string and CLOB values are shortened and line breaks are escaped so each bind stays on one line.
+func (b Bind) String() string {
+	value := "NULL"
+	switch v := b.Value.(type) {
+	case nil:
+	case string:
+		if r := []rune(v); len(r) > maxBindDisplayLen {
+			v = string(r[:maxBindDisplayLen]) + fmt.Sprintf("... (%d chars)", len(r))
+		}
+		v = strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(v)
+		if b.Type == "number" {
+			value = v
+		} else {
+			value = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+		}
+	default:
+		value = fmt.Sprint(v)
+	}
+	return fmt.Sprintf(":%s = %s (%s)", b.Name, value, b.Type)
+}
+
+// FormatBinds returns one display line per bind; positional binds are shown as :1, :2, ...
+func FormatBinds(binds []Bind) []string {
+	if len(binds) == 0 {
+		return nil
+	}
+	out := make([]string, len(binds))
+	for i, b := range binds {
+		if b.Name == "" {
+			b.Name = strconv.Itoa(i + 1)
+		}
+		out[i] = b.String()
+	}
+	return out
+}
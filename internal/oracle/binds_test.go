@@ -0,0 +1,104 @@
+package oracle
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/godror/godror"
+)
+
+func parseJSONBinds(t *testing.T, s string) ([]Bind, error) {
+	t.Helper()
+	var raw interface{}
+	if err := json.Unmarshal([]byte(s), &raw); err != nil {
+		t.Fatalf("invalid test JSON %q: %v", s, err)
+	}
+	return ParseBinds(raw)
+}
+
+func TestParseBinds_Named(t *testing.T) {
+	binds, err := parseJSONBinds(t, `{":name": "x", "id": 42, "when": {"type": "date", "value": "2024-01-31"}, "gone": null}`)
+	if err != nil {
+		t.Fatalf("ParseBinds: %v", err)
+	}
+	got := FormatBinds(binds)
+	want := []string{":gone = NULL (null)", ":id = 42 (number)", ":name = 'x' (string)", ":when = '2024-01-31' (date)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FormatBinds = %q, want %q", got, want)
+	}
+
+	args := bindArgs(binds)
+	if n, ok := args[1].(sql.NamedArg); !ok || n.Name != "id" || n.Value != int64(42) {
+		t.Errorf("args[1] = %#v, want sql.Named(\"id\", int64(42))", args[1])
+	}
+	if n := args[3].(sql.NamedArg); !n.Value.(time.Time).Equal(time.Date(2024, 1, 31, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("date arg = %v", n.Value)
+	}
+}
+
+func TestParseBinds_Positional(t *testing.T) {
+	binds, err := parseJSONBinds(t, `[1.5, "it's", {"type": "number", "value": "12345678901234567890123"}, {"type": "clob", "value": "big"}]`)
+	if err != nil {
+		t.Fatalf("ParseBinds: %v", err)
+	}
+	got := FormatBinds(binds)
+	want := []string{":1 = 1.5 (number)", ":2 = 'it''s' (string)", ":3 = 12345678901234567890123 (number)", ":4 = 'big' (clob)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FormatBinds = %q, want %q", got, want)
+	}
+
+	args := bindArgs(binds)
+	if args[0] != 1.5 || args[1] != "it's" || args[2] != godror.Number("12345678901234567890123") {
+		t.Errorf("args = %#v", args)
+	}
+	if lob, ok := args[3].(godror.Lob); !ok || !lob.IsClob {
+		t.Errorf("clob arg = %#v, want godror.Lob{IsClob: true}", args[3])
+	}
+}
+
+func TestParseBinds_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"scalar", `"x"`, "must be an object"},
+		{"boolean", `[true]`, "bind :1: boolean values are not supported"},
+		{"unknown type", `{"d": {"type": "blob", "value": "x"}}`, `bind :d: unknown type "blob"`},
+		{"unknown type of NULL", `{"d": {"type": "blob", "value": null}}`, `bind :d: unknown type "blob"`},
+		{"unknown type without value", `[{"type": "raw"}]`, `bind :1: unknown type "raw"`},
+		{"missing type", `{"d": {"value": "x"}}`, `bind :d: typed bind needs "type"`},
+		{"bad date", `{"d": {"type": "date", "value": "31/01/2024"}}`, `bind :d: invalid date "31/01/2024"`},
+		{"bad number", `[{"type": "number", "value": "abc"}]`, `bind :1: invalid number "abc"`},
+		{"empty name", `{":": 1}`, "bind name cannot be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseJSONBinds(t, tt.json)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseBinds(%s) error = %v, want containing %q", tt.json, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindString_ShortensAndEscapes(t *testing.T) {
+	long := Bind{Name: "doc", Type: "clob", Value: strings.Repeat("a", maxBindDisplayLen+10)}
+	if s := long.String(); !strings.HasSuffix(s, "... (210 chars)' (clob)") {
+		t.Errorf("String() = %q, want shortened value", s)
+	}
+	// Cut at a character, not inside a multi-byte one
+	wide := Bind{Name: "txt", Type: "string", Value: strings.Repeat("é", maxBindDisplayLen+1)}
+	if s := wide.String(); !utf8.ValidString(s) || !strings.HasSuffix(s, "é... (201 chars)' (string)") {
+		t.Errorf("String() = %q, want shortened by characters", s)
+	}
+	multi := Bind{Name: "txt", Type: "string", Value: "a\r\nb"}
+	if s := multi.String(); s != `:txt = 'a\r\nb' (string)` {
+		t.Errorf("String() = %q, want escaped line breaks", s)
+	}
+}
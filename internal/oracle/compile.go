@@ -0,0 +1,98 @@
+// Package oracle: PL/SQL compilation errors after CREATE PROCEDURE/FUNCTION/PACKAGE statements.
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CompileError is one error or warning from ALL_ERRORS for an object created by the executed SQL. Line and
+// Position are relative to the object's stored source; FileLine is the matching line of ExecOptions.SourceFile.
+type CompileError struct {
+	Object    string `json:"object"` // OWNER.NAME
+	Type      string `json:"type"`   // PROCEDURE, FUNCTION, PACKAGE, PACKAGE BODY, ...
+	Line      int    `json:"line"`
+	Position  int    `json:"position"`
+	Text      string `json:"text"`
+	Attribute string `json:"attribute"` // ERROR or WARNING
+	File      string `json:"file,omitempty"`
+	FileLine  int    `json:"file_line,omitempty"`
+}
+
+// plsqlCreation matches the head of a PL/SQL creation statement up to the object name; leading comments are
+// allowed. Group 1 is the object type, group 2 the (possibly qualified, quoted) name.
+var plsqlCreation = regexp.MustCompile(`(?is)^\s*(?:(?:--[^\n]*(?:\n|$)|/\*.*?\*/)\s*)*create\s+(?:or\s+replace\s+)?(?:(?:editionable|noneditionable)\s+)?` +
+	`(procedure|function|package\s+body|package|trigger|type\s+body|type)\s+((?:"[^"]+"|[\w$#]+)(?:\s*\.\s*(?:"[^"]+"|[\w$#]+))?)`)
+
+// compiledObject is the object a PL/SQL creation statement defines.
+type compiledObject struct {
+	owner, name, typ string
+	sourceLine       int // line of the statement on which the stored source (the object type keyword) starts, from 0
+}
+
+// parseCompiledObject returns the object a PL/SQL creation statement defines.
+func parseCompiledObject(stmt string) (compiledObject, bool) {
+	m := plsqlCreation.FindStringSubmatchIndex(stmt)
+	if m == nil {
+		return compiledObject{}, false
+	}
+	owner, name := splitQualifiedName(strings.Join(strings.Fields(stmt[m[4]:m[5]]), ""))
+	return compiledObject{
+		owner:      identifier(owner),
+		name:       identifier(name),
+		typ:        strings.ToUpper(strings.Join(strings.Fields(stmt[m[2]:m[3]]), " ")),
+		sourceLine: strings.Count(stmt[:m[2]], "\n"),
+	}, true
+}
+
+// compileErrors reads the compilation errors of the object stmt created. startLine is the line of the script
+// (from 1) on which stmt starts; it maps the errors to the file when file is not "".
+func compileErrors(ctx context.Context, q queryer, stmt string, file string, startLine int) ([]CompileError, error) {
+	obj, ok := parseCompiledObject(stmt)
+	if !ok {
+		return nil, nil
+	}
+	rows, err := q.QueryContext(ctx, `SELECT line, position, text, attribute FROM all_errors
+ WHERE owner = NVL(:owner, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')) AND name = :name AND type = :type
+ ORDER BY sequence`, sql.Named("owner", obj.owner), sql.Named("name", obj.name), sql.Named("type", obj.typ))
+	if err != nil {
+		return nil, fmt.Errorf("reading compilation errors of %s %s failed: %w", obj.typ, qualifiedName(obj.owner, obj.name), err)
+	}
+	defer rows.Close()
+	var errs []CompileError
+	for rows.Next() {
+		ce := CompileError{Object: qualifiedName(obj.owner, obj.name), Type: obj.typ}
+		var text, attribute sql.NullString
+		if err := rows.Scan(&ce.Line, &ce.Position, &text, &attribute); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		ce.Text = strings.TrimSpace(text.String)
+		ce.Attribute = attribute.String
+		if file != "" {
+			ce.File = file
+			ce.FileLine = startLine + obj.sourceLine + ce.Line - 1
+		}
+		errs = append(errs, ce)
+	}
+	return errs, rows.Err()
+}
+
+// isCompileWarning reports whether err only signals that the statement succeeded with compilation errors
+// (ORA-24344), which compileErrors then reports.
+func isCompileWarning(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "ORA-24344")
+}
+
+// invalidObjects counts the objects with at least one compilation error (not only warnings).
+func invalidObjects(errs []CompileError) int {
+	seen := map[string]bool{}
+	for _, ce := range errs {
+		if ce.Attribute == "ERROR" {
+			seen[ce.Type+" "+ce.Object] = true
+		}
+	}
+	return len(seen)
+}
@@ -0,0 +1,36 @@
+package oracle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCompiledObject(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want compiledObject
+		ok   bool
+	}{
+		{"CREATE OR REPLACE PROCEDURE hr.raise_salary(p_id NUMBER) AS\nBEGIN NULL; END;", compiledObject{owner: "HR", name: "RAISE_SALARY", typ: "PROCEDURE"}, true},
+		{"-- header\n/* multi\n line */\ncreate or replace\npackage   body \"Pkg\" as\nend;", compiledObject{name: "Pkg", typ: "PACKAGE BODY", sourceLine: 4}, true},
+		{"CREATE EDITIONABLE FUNCTION f RETURN NUMBER IS BEGIN RETURN 1; END;", compiledObject{name: "F", typ: "FUNCTION"}, true},
+		{"CREATE TABLE procedure_log (id NUMBER)", compiledObject{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseCompiledObject(tt.stmt)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCompiledObject(%q) = %+v, %v, want %+v, %v", tt.stmt, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestInvalidObjects(t *testing.T) {
+	errs := []CompileError{
+		{Object: "HR.P", Type: "PACKAGE BODY", Attribute: "ERROR"},
+		{Object: "HR.P", Type: "PACKAGE BODY", Attribute: "ERROR"},
+		{Object: "HR.F", Type: "FUNCTION", Attribute: "WARNING"},
+	}
+	if n := invalidObjects(errs); n != 1 {
+		t.Errorf("invalidObjects = %d, want 1", n)
+	}
+}
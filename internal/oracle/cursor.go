@@ -0,0 +1,270 @@
+// Package oracle: row limits and open cursors continued by fetch_more.
+package oracle
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultResultHandleTTL is how long an unread cursor stays open after its last page.
+const DefaultResultHandleTTL = 5 * time.Minute
+
+// maxCursorsPerSession limits the open result handles of one client session on one connection; each one holds a
+// session out of the connection's pool (SetMaxOpenConns), so the session's oldest handle there is closed when
+// another of its queries is truncated.
+const maxCursorsPerSession = 2
+
+// cursor is a query result read page by page. A truncated execute_sql keeps it open under a result handle.
+type cursor struct {
+	rows    *sql.Rows
+	columns []string
+	next    []interface{}      // row read ahead to detect that more rows remain; first row of the next page
+	fetched int64              // rows returned so far
+	cancel  context.CancelFunc // ends the context the rows were opened with; nil when not kept
+	guard   *readOnlyGuard     // read-only transaction the rows were opened in; ended on close
+	slot    *poolSlot          // pool slot of the session the rows pin outside a transaction; released on close
+
+	// Set when the cursor is registered in the pool
+	handle     string
+	session    string // client session that ran the query; only it may fetch_more
+	connection string
+	tx         *transaction // non-nil when opened inside a transaction; closed before it ends
+	opened     time.Time
+	timer      *time.Timer
+
+	mu     sync.Mutex // serializes fetch_more and close
+	closed bool
+}
+
+// readPage returns up to max rows (all remaining rows when max <= 0) and whether more rows remain.
+// Values are converted with convertValue, so CLOB columns are read in full.
+func (c *cursor) readPage(max int) ([][]interface{}, bool, error) {
+	page := make([][]interface{}, 0)
+	if c.next != nil {
+		page = append(page, c.next)
+		c.next = nil
+	}
+	for c.rows.Next() {
+		row, err := c.scan()
+		if err != nil {
+			return nil, false, err
+		}
+		if max > 0 && len(page) == max {
+			c.next = row
+			c.fetched += int64(len(page))
+			return page, true, nil
+		}
+		page = append(page, row)
+	}
+	if err := c.rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("error iterating rows: %w", err)
+	}
+	c.fetched += int64(len(page))
+	return page, false, nil
+}
+
+// scan reads the current row.
+func (c *cursor) scan() ([]interface{}, error) {
+	numCols := len(c.columns)
+	values := make([]interface{}, numCols)
+	valuePtrs := make([]interface{}, numCols)
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+	if err := c.rows.Scan(valuePtrs...); err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+	// Convert values to proper types for JSON serialization
+	row := make([]interface{}, numCols)
+	for i, v := range values {
+		row[i] = convertValue(v)
+	}
+	return row, nil
+}
+
+// close releases the cursor's session; the caller holds c.mu.
+func (c *cursor) close() {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.rows.Close()
+	if c.guard != nil {
+		c.guard.end()
+	}
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.slot.release()
+}
+
+// SetResultHandleTTL sets how long an open result handle waits for fetch_more. d <= 0 uses DefaultResultHandleTTL.
+func (p *ExecutorPool) SetResultHandleTTL(d time.Duration) {
+	if d <= 0 {
+		d = DefaultResultHandleTTL
+	}
+	p.mu.Lock()
+	p.cursorTTL = d
+	p.mu.Unlock()
+}
+
+// FetchMore returns the next page (up to maxRows rows; all remaining when maxRows <= 0) of a truncated query.
+// The result carries the same handle while more rows remain; the handle is closed after the last page.
+// timeout works as ExecOptions.Timeout for the cursor's connection. When ctx is cancelled or the timeout expires
+// during the read, the cursor is closed and the handle is gone. A handle of another client session is unknown.
+func (p *ExecutorPool) FetchMore(ctx context.Context, session string, handle string, maxRows int, timeout time.Duration) (*ExecutionResult, error) {
+	start := time.Now()
+	p.mu.RLock()
+	c := p.cursors[handle]
+	ttl := p.cursorTTL
+	p.mu.RUnlock()
+	if c == nil || c.session != session {
+		return nil, fmt.Errorf("unknown or expired result handle %q; run the query again", handle)
+	}
+	timeout, err := p.QueryTimeout(c.connection, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if c.tx != nil {
+		// Same session as the transaction: wait for its current statement
+		t, err := p.lockTransaction(c.tx.key)
+		if err != nil {
+			return nil, err
+		}
+		if t != nil {
+			defer p.releaseTransaction(t)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, fmt.Errorf("unknown or expired result handle %q; run the query again", handle)
+	}
+	c.timer.Stop()
+	readCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		readCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if c.cancel != nil {
+		stop := context.AfterFunc(readCtx, c.cancel)
+		defer stop()
+	}
+
+	page, more, err := c.readPage(maxRows)
+	if err != nil {
+		p.forgetCursor(c)
+		c.close()
+		if ctx.Err() == nil && readCtx.Err() == context.DeadlineExceeded {
+			return nil, &TimeoutError{Connection: c.connection, Timeout: timeout, Err: err}
+		}
+		return nil, err
+	}
+	result := &ExecutionResult{
+		Columns:       c.columns,
+		Rows:          page,
+		Success:       true,
+		StatementType: "SELECT",
+		ExecutionTime: time.Since(start).Milliseconds(),
+		InTransaction: c.tx != nil,
+		Truncated:     more,
+		RowsFetched:   c.fetched,
+	}
+	if more {
+		result.ResultHandle = handle
+		c.timer.Reset(ttl)
+	} else {
+		p.forgetCursor(c)
+		c.close()
+	}
+	return result, nil
+}
+
+// registerCursor keeps c, opened by session on the named connection, open under a new result handle and returns
+// the handle. When the session already has maxCursorsPerSession open handles on the connection, its oldest is closed.
+func (p *ExecutorPool) registerCursor(session string, name string, t *transaction, c *cursor) string {
+	c.handle = newResultHandle()
+	c.session = session
+	c.connection = name
+	c.tx = t
+	c.opened = time.Now()
+
+	p.mu.Lock()
+	var oldest *cursor
+	n := 0
+	for _, other := range p.cursors {
+		if other.session == session && other.connection == name {
+			n++
+			if oldest == nil || other.opened.Before(oldest.opened) {
+				oldest = other
+			}
+		}
+	}
+	if n < maxCursorsPerSession {
+		oldest = nil
+	} else {
+		delete(p.cursors, oldest.handle)
+	}
+	c.timer = time.AfterFunc(p.cursorTTL, func() { p.expireCursor(c) })
+	p.cursors[c.handle] = c
+	p.mu.Unlock()
+
+	if oldest != nil {
+		oldest.mu.Lock()
+		oldest.close()
+		oldest.mu.Unlock()
+	}
+	return c.handle
+}
+
+// expireCursor is the TTL timer callback.
+func (p *ExecutorPool) expireCursor(c *cursor) {
+	p.forgetCursor(c)
+	c.mu.Lock()
+	c.close()
+	c.mu.Unlock()
+}
+
+// forgetCursor removes c from the handle map.
+func (p *ExecutorPool) forgetCursor(c *cursor) {
+	p.mu.Lock()
+	if p.cursors[c.handle] == c {
+		delete(p.cursors, c.handle)
+	}
+	p.mu.Unlock()
+}
+
+// closeCursors closes every open handle for which match returns true (all when match is nil).
+func (p *ExecutorPool) closeCursors(match func(c *cursor) bool) {
+	p.mu.Lock()
+	var closing []*cursor
+	for handle, c := range p.cursors {
+		if match == nil || match(c) {
+			closing = append(closing, c)
+			delete(p.cursors, handle)
+		}
+	}
+	p.mu.Unlock()
+	for _, c := range closing {
+		c.mu.Lock()
+		c.close()
+		c.mu.Unlock()
+	}
+}
+
+// newResultHandle returns a random handle for fetch_more.
+func newResultHandle() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "rh_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,34 @@
+package oracle
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCursors_BelongToTheirSession(t *testing.T) {
+	p := &ExecutorPool{cursors: make(map[string]*cursor), cursorTTL: time.Minute}
+	var handles []string
+	for _, session := range []string{"a", "a", "b"} {
+		handles = append(handles, p.registerCursor(session, "db1", nil, &cursor{}))
+	}
+	t.Cleanup(func() {
+		for _, c := range p.cursors {
+			c.timer.Stop()
+		}
+	})
+
+	// Session b's query does not evict session a's handles, although a is at its limit
+	for _, h := range handles {
+		if p.cursors[h] == nil {
+			t.Errorf("handle %s was closed", h)
+		}
+	}
+	for _, session := range []string{"b", ""} {
+		_, err := p.FetchMore(context.Background(), session, handles[0], 10, 0)
+		if err == nil || !strings.Contains(err.Error(), "unknown or expired result handle") {
+			t.Errorf("FetchMore from session %q: err = %v, want unknown handle", session, err)
+		}
+	}
+}
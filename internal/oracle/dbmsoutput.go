@@ -0,0 +1,82 @@
+// Package oracle: DBMS_OUTPUT capture for PL/SQL blocks and procedure calls.
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/godror/godror"
+)
+
+// MaxDBMSOutputBytes caps the DBMS_OUTPUT text kept in one ExecutionResult; lines past it are dropped and
+// DBMSOutputTruncated is set.
+const MaxDBMSOutputBytes = 1 << 20
+
+// dbmsOutputBatch is the number of lines fetched per DBMS_OUTPUT.GET_LINES call.
+const dbmsOutputBatch = 128
+
+// printsOutput reports whether a statement of the given type (sqlanalyzer.GetStatementType) is a PL/SQL block or
+// procedure call whose DBMS_OUTPUT is captured.
+func printsOutput(stmtType string) bool {
+	switch stmtType {
+	case "BEGIN", "DECLARE", "CALL":
+		return true
+	}
+	return false
+}
+
+// executeBlock runs a PL/SQL block or call with DBMS_OUTPUT enabled and appends what it printed to
+// result.DBMSOutput. q must be one session (a *sql.Conn or the transaction): DBMS_OUTPUT is session state.
+// When the block fails, its output is added to the error, since that is usually what explains it.
+func (e *Executor) executeBlock(ctx context.Context, q queryer, sqlText string, result *ExecutionResult, args ...interface{}) error {
+	if _, err := q.ExecContext(ctx, `BEGIN DBMS_OUTPUT.ENABLE(NULL); END;`); err != nil {
+		return fmt.Errorf("enabling DBMS_OUTPUT failed: %w", err)
+	}
+	// DISABLE also purges what was not read, so the next block on this session starts empty
+	defer q.ExecContext(context.WithoutCancel(ctx), `BEGIN DBMS_OUTPUT.DISABLE; END;`)
+
+	execErr := e.executeStatement(ctx, q, sqlText, result, args...)
+	readErr := readDBMSOutput(ctx, q, result)
+	if execErr != nil {
+		if readErr == nil && len(result.DBMSOutput) > 0 {
+			return fmt.Errorf("%w\nDBMS_OUTPUT:\n%s", execErr, strings.Join(result.DBMSOutput, "\n"))
+		}
+		return execErr
+	}
+	if readErr != nil {
+		return fmt.Errorf("reading DBMS_OUTPUT failed: %w", readErr)
+	}
+	return nil
+}
+
+// readDBMSOutput drains the session's DBMS_OUTPUT buffer into result until it is empty or MaxDBMSOutputBytes
+// is reached.
+func readDBMSOutput(ctx context.Context, q queryer, result *ExecutionResult) error {
+	size := 0
+	for _, line := range result.DBMSOutput {
+		size += len(line) + 1
+	}
+	lines := make([]string, dbmsOutputBatch)
+	var n int64
+	for !result.DBMSOutputTruncated {
+		n = int64(len(lines))
+		if _, err := q.ExecContext(ctx, `BEGIN DBMS_OUTPUT.GET_LINES(:1, :2); END;`,
+			godror.PlSQLArrays, sql.Out{Dest: &lines}, sql.Out{Dest: &n, In: true}); err != nil {
+			return err
+		}
+		for _, line := range lines[:n] {
+			if size+len(line)+1 > MaxDBMSOutputBytes {
+				result.DBMSOutputTruncated = true
+				break
+			}
+			size += len(line) + 1
+			result.DBMSOutput = append(result.DBMSOutput, line)
+		}
+		if n < int64(len(lines)) {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package oracle
+
+import (
+	"testing"
+
+	"github.com/alvin/oracle-mcp-server/internal/sqlanalyzer"
+)
+
+func TestPrintsOutput(t *testing.T) {
+	for sql, want := range map[string]bool{
+		"BEGIN\n  DBMS_OUTPUT.PUT_LINE('X');\nEND;":              true,
+		"DECLARE N NUMBER; BEGIN NULL; END;":                     true,
+		"CALL HR.REFRESH_STATS(1)":                               true,
+		"BEGIN;":                                                 true,
+		"-- header\nBEGIN\n  DBMS_OUTPUT.PUT_LINE('X');\nEND;":   true,
+		"/* load */ <<main>> DECLARE N NUMBER; BEGIN NULL; END;": true,
+		"BEGINNING_BALANCE":                                      false,
+		"UPDATE EMP SET SAL = SAL * 1.1":                         false,
+		"CREATE OR REPLACE PROCEDURE P AS BEGIN NULL; END;":      false,
+		"-- BEGIN\nSELECT 1 FROM DUAL":                           false,
+		"":                                                       false,
+	} {
+		if got := printsOutput(sqlanalyzer.GetStatementType(sql)); got != want {
+			t.Errorf("printsOutput(%q) = %v, want %v", sql, got, want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// Package oracle: object DDL from DBMS_METADATA (get_ddl).
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// DDLObjectTypes are the DBMS_METADATA object types GetDDL accepts. SchemaDDLObjectTypes is the order used for a
+// whole-schema export when no type is given (objects before the ones that depend on them).
+var (
+	DDLObjectTypes = []string{"TABLE", "VIEW", "MATERIALIZED_VIEW", "INDEX", "SEQUENCE", "SYNONYM", "PACKAGE", "PACKAGE_SPEC",
+		"PACKAGE_BODY", "PROCEDURE", "FUNCTION", "TRIGGER", "TYPE", "TYPE_SPEC", "TYPE_BODY"}
+	SchemaDDLObjectTypes = []string{"SEQUENCE", "TYPE", "TABLE", "VIEW", "MATERIALIZED_VIEW", "FUNCTION", "PROCEDURE", "PACKAGE", "TRIGGER", "SYNONYM"}
+)
+
+// DDLRequest selects the objects GetDDL returns.
+type DDLRequest struct {
+	ObjectType string // DBMS_METADATA type (spaces allowed: "PACKAGE BODY"); "" with Name "" exports every SchemaDDLObjectTypes type
+	Owner      string // schema; "" uses the session's current schema
+	Name       string // object name; "" exports every object of ObjectType in the schema
+
+	// Dependent DDL appended after each object, where the object type has it
+	IncludeGrants   bool
+	IncludeIndexes  bool
+	IncludeTriggers bool
+	IncludeComments bool
+}
+
+// DDLResult is the DDL of the requested objects, ready to run in SQL*Plus or SQLcl.
+type DDLResult struct {
+	DDL     string      `json:"ddl"`
+	Objects []DDLObject `json:"objects"`
+	Errors  []string    `json:"errors,omitempty"` // objects of a schema export whose DDL could not be generated
+}
+
+// DDLObject names one object in a DDLResult.
+type DDLObject struct {
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+}
+
+// dependentDDL lists the GET_DEPENDENT_DDL types that apply to each base type; other types only have grants.
+var dependentDDL = map[string][]string{
+	"TABLE":             {"COMMENT", "INDEX", "TRIGGER", "OBJECT_GRANT"},
+	"VIEW":              {"COMMENT", "TRIGGER", "OBJECT_GRANT"},
+	"MATERIALIZED_VIEW": {"COMMENT", "INDEX", "OBJECT_GRANT"},
+}
+
+// dependentTypes returns the dependent DDL types of a base type.
+func dependentTypes(typ string) []string {
+	if deps, ok := dependentDDL[typ]; ok {
+		return deps
+	}
+	return []string{"OBJECT_GRANT"}
+}
+
+// ddlTransforms makes DBMS_METADATA output clean and runnable: no storage or tablespace clauses, a terminator after
+// each statement. They are session settings, so GetDDL sets them on its own session and resets them afterwards.
+const ddlTransforms = `BEGIN
+  DBMS_METADATA.SET_TRANSFORM_PARAM(DBMS_METADATA.SESSION_TRANSFORM, 'DEFAULT');
+  DBMS_METADATA.SET_TRANSFORM_PARAM(DBMS_METADATA.SESSION_TRANSFORM, 'PRETTY', TRUE);
+  DBMS_METADATA.SET_TRANSFORM_PARAM(DBMS_METADATA.SESSION_TRANSFORM, 'SQLTERMINATOR', TRUE);
+  DBMS_METADATA.SET_TRANSFORM_PARAM(DBMS_METADATA.SESSION_TRANSFORM, 'STORAGE', FALSE);
+  DBMS_METADATA.SET_TRANSFORM_PARAM(DBMS_METADATA.SESSION_TRANSFORM, 'SEGMENT_ATTRIBUTES', FALSE);
+END;`
+
+const ddlTransformsReset = `BEGIN DBMS_METADATA.SET_TRANSFORM_PARAM(DBMS_METADATA.SESSION_TRANSFORM, 'DEFAULT'); END;`
+
+// GetDDL returns the DDL of one object, or of every object of a type (or of all SchemaDDLObjectTypes) in a schema.
+// A missing single object is an error; in a schema export, objects that fail are listed in DDLResult.Errors.
+func (p *ExecutorPool) GetDDL(ctx context.Context, connectionName string, req DDLRequest, opts ExecOptions) (*DDLResult, error) {
+	req.Owner, req.Name = identifier(req.Owner), identifier(req.Name)
+	types := SchemaDDLObjectTypes
+	if req.ObjectType != "" {
+		typ, err := ddlObjectType(req.ObjectType)
+		if err != nil {
+			return nil, err
+		}
+		types = []string{typ}
+	} else if req.Name != "" {
+		return nil, fmt.Errorf("object type is required when a name is given (one of %s)", strings.Join(DDLObjectTypes, ", "))
+	}
+
+	var result *DDLResult
+	err := p.run(ctx, connectionName, opts, func(ctx context.Context, name string, ex *Executor, opts ExecOptions) error {
+		// The transforms must be set on the session that calls GET_DDL
+		return ex.session(ctx, opts, func(q queryer) error {
+			if _, err := q.ExecContext(ctx, ddlTransforms); err != nil {
+				return fmt.Errorf("DBMS_METADATA transform setup failed: %w", err)
+			}
+			defer q.ExecContext(context.WithoutCancel(ctx), ddlTransformsReset)
+
+			var err error
+			result, err = getDDL(ctx, q, req, types)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// getDDL collects the DDL on q, which has the transforms set.
+func getDDL(ctx context.Context, q queryer, req DDLRequest, types []string) (*DDLResult, error) {
+	if req.Owner == "" {
+		if err := q.QueryRowContext(ctx, `SELECT SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') FROM dual`).Scan(&req.Owner); err != nil {
+			return nil, fmt.Errorf("query failed: %w", err)
+		}
+	}
+	if len(types) > 1 {
+		// Triggers are exported as objects of their own
+		req.IncludeTriggers = false
+	}
+	var objects []DDLObject
+	if req.Name != "" {
+		objects = []DDLObject{{Owner: req.Owner, Name: req.Name, Type: types[0]}}
+	} else {
+		for _, typ := range types {
+			found, err := schemaObjects(ctx, q, req.Owner, typ)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, found...)
+		}
+		if len(objects) == 0 {
+			return nil, fmt.Errorf("no %s objects visible in schema %s", strings.Join(types, ", "), req.Owner)
+		}
+	}
+
+	result := &DDLResult{Objects: make([]DDLObject, 0, len(objects))}
+	var out strings.Builder
+	for _, obj := range objects {
+		ddl, err := objectDDL(ctx, q, obj, req)
+		if err != nil {
+			if req.Name != "" || ctx.Err() != nil {
+				return nil, err
+			}
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(ddl)
+		result.Objects = append(result.Objects, obj)
+	}
+	result.DDL = out.String()
+	return result, nil
+}
+
+// objectDDL returns the DDL of obj followed by the requested dependent DDL.
+func objectDDL(ctx context.Context, q queryer, obj DDLObject, req DDLRequest) (string, error) {
+	ddl, err := metadataCLOB(ctx, q, `SELECT DBMS_METADATA.GET_DDL(:type, :name, :owner) FROM dual`, obj.Type, obj.Name, obj.Owner)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", obj.Type, qualifiedName(obj.Owner, obj.Name), err)
+	}
+	parts := []string{cleanDDL(ddl)}
+	for _, dep := range dependentTypes(obj.Type) {
+		wanted := (dep == "OBJECT_GRANT" && req.IncludeGrants) || (dep == "INDEX" && req.IncludeIndexes) ||
+			(dep == "TRIGGER" && req.IncludeTriggers) || (dep == "COMMENT" && req.IncludeComments)
+		if !wanted {
+			continue
+		}
+		ddl, err := metadataCLOB(ctx, q, `SELECT DBMS_METADATA.GET_DEPENDENT_DDL(:type, :name, :owner) FROM dual`, dep, obj.Name, obj.Owner)
+		if err != nil {
+			// ORA-31608: the object has no dependent objects of this type
+			if strings.Contains(err.Error(), "ORA-31608") {
+				continue
+			}
+			return "", fmt.Errorf("%s %s: %s: %w", obj.Type, qualifiedName(obj.Owner, obj.Name), strings.ToLower(dep), err)
+		}
+		parts = append(parts, cleanDDL(ddl))
+	}
+	return strings.Join(parts, "\n\n") + "\n", nil
+}
+
+// metadataCLOB runs a DBMS_METADATA query and returns its CLOB result as a string.
+func metadataCLOB(ctx context.Context, q queryer, query string, typ, name, owner string) (string, error) {
+	var v interface{}
+	if err := q.QueryRowContext(ctx, query, sql.Named("type", typ), sql.Named("name", name), sql.Named("owner", owner)).Scan(&v); err != nil {
+		return "", err
+	}
+	s, _ := convertValue(v).(string)
+	return s, nil
+}
+
+// schemaObjects lists the objects of a DBMS_METADATA type in owner, skipping generated and recycle bin objects
+// and the container tables of materialized views.
+func schemaObjects(ctx context.Context, q queryer, owner, typ string) ([]DDLObject, error) {
+	rows, err := q.QueryContext(ctx, `SELECT o.object_name FROM all_objects o
+ WHERE o.owner = :owner AND o.object_type = :type AND o.generated = 'N' AND o.object_name NOT LIKE 'BIN$%'
+   AND NOT (o.object_type = 'TABLE' AND EXISTS (SELECT 1 FROM all_mviews m WHERE m.owner = o.owner AND m.mview_name = o.object_name))
+ ORDER BY o.object_name`, sql.Named("owner", owner), sql.Named("type", dictionaryObjectType(typ)))
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+	var objects []DDLObject
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		objects = append(objects, DDLObject{Owner: owner, Name: name, Type: typ})
+	}
+	return objects, rows.Err()
+}
+
+// ddlObjectType validates an object type and returns its DBMS_METADATA name.
+func ddlObjectType(s string) (string, error) {
+	typ := strings.ToUpper(strings.Join(strings.Fields(strings.ReplaceAll(s, "_", " ")), "_"))
+	for _, t := range DDLObjectTypes {
+		if typ == t {
+			return typ, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported object type %q (supported: %s)", s, strings.Join(DDLObjectTypes, ", "))
+}
+
+// dictionaryObjectType maps a DBMS_METADATA type to ALL_OBJECTS.OBJECT_TYPE.
+func dictionaryObjectType(typ string) string {
+	switch typ {
+	case "PACKAGE_SPEC":
+		return "PACKAGE"
+	case "TYPE_SPEC":
+		return "TYPE"
+	}
+	return strings.ReplaceAll(typ, "_", " ")
+}
+
+// cleanDDL trims trailing blanks and the blank lines and indentation DBMS_METADATA puts around each statement.
+// Only statement starts (after a blank or "/" line) lose their indentation, so PL/SQL source is kept as is.
+func cleanDDL(ddl string) string {
+	lines := strings.Split(strings.ReplaceAll(ddl, "\r\n", "\n"), "\n")
+	prev := ""
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if prev == "" || prev == "/" {
+			line = strings.TrimLeft(line, " ")
+		}
+		lines[i] = line
+		prev = line
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,28 @@
+package oracle
+
+import "testing"
+
+func TestDDLObjectType(t *testing.T) {
+	for in, want := range map[string]string{"table": "TABLE", "package body": "PACKAGE_BODY", "Materialized_View": "MATERIALIZED_VIEW", " type  spec ": "TYPE_SPEC"} {
+		got, err := ddlObjectType(in)
+		if err != nil || got != want {
+			t.Errorf("ddlObjectType(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ddlObjectType("DB_LINK"); err == nil {
+		t.Error("ddlObjectType accepted DB_LINK")
+	}
+	for in, want := range map[string]string{"PACKAGE_BODY": "PACKAGE BODY", "PACKAGE_SPEC": "PACKAGE", "MATERIALIZED_VIEW": "MATERIALIZED VIEW", "TABLE": "TABLE"} {
+		if got := dictionaryObjectType(in); got != want {
+			t.Errorf("dictionaryObjectType(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCleanDDL(t *testing.T) {
+	in := "\n  CREATE TABLE \"HR\".\"T\" \n   (\t\"ID\" NUMBER\n   ) ;\n\n  CREATE OR REPLACE PACKAGE BODY \"HR\".\"P\" AS\n  PROCEDURE x IS BEGIN NULL; END;\nEND;\n/\n  ALTER TRIGGER \"HR\".\"TRG\" ENABLE;\n"
+	want := "CREATE TABLE \"HR\".\"T\"\n   (\t\"ID\" NUMBER\n   ) ;\n\nCREATE OR REPLACE PACKAGE BODY \"HR\".\"P\" AS\n  PROCEDURE x IS BEGIN NULL; END;\nEND;\n/\nALTER TRIGGER \"HR\".\"TRG\" ENABLE;"
+	if got := cleanDDL(in); got != want {
+		t.Errorf("cleanDDL =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -30,6 +30,50 @@ type ExecutionResult struct {
 	StatementType string `json:"statement_type"`
 	ExecutionTime int64  `json:"execution_time_ms"`
 	Warning       string `json:"warning,omitempty"`
+	InTransaction bool   `json:"in_transaction,omitempty"` // ran inside an open transaction; not committed yet
+
+	// Lines printed with DBMS_OUTPUT by PL/SQL blocks and CALL statements, at most MaxDBMSOutputBytes in total
+	DBMSOutput          []string `json:"dbms_output,omitempty"`
+	DBMSOutputTruncated bool     `json:"dbms_output_truncated,omitempty"`
+
+	// Errors and warnings of PL/SQL objects created by the SQL (ALL_ERRORS); an object with errors is invalid
+	CompileErrors []CompileError `json:"compile_errors,omitempty"`
+
+	// Multi-statement scripts: one entry per statement run, and the script's outcome. Columns and Rows stay empty;
+	// RowsAffected is the total. Success is false when a statement failed.
+	Statements []StatementResult `json:"statements,omitempty"`
+	Script     *ScriptStatus     `json:"script,omitempty"`
+
+	// Row limit (ExecOptions.MaxRows): Truncated is set when more rows remain, RowsFetched counts the rows returned
+	// so far for the query (across fetch_more pages), and ResultHandle names the open cursor for fetch_more.
+	Truncated    bool   `json:"truncated,omitempty"`
+	RowsFetched  int64  `json:"rows_fetched,omitempty"`
+	ResultHandle string `json:"result_handle,omitempty"`
+
+	cursor *cursor // open cursor of a truncated query, picked up by ExecutorPool.Execute
+}
+
+// ExecOptions are optional per-call settings for Execute and the query-to-file helpers.
+type ExecOptions struct {
+	// Binds are passed to the driver with the statement. Only a single statement may be bound.
+	Binds []Bind
+	// MaxRows limits the rows returned by a query; 0 returns all rows.
+	MaxRows int
+	// Timeout cancels the call after this long; 0 uses the connection's query timeout (see ExecutorPool.QueryTimeout).
+	// Only ExecutorPool applies it.
+	Timeout time.Duration
+	// Session is the client session the call belongs to ("" for stdio); it selects that session's open transaction.
+	Session string
+	// SourceFile is the script the SQL was read from (execute_sql_file); compile errors are mapped to its lines.
+	SourceFile string
+	// ContinueOnError runs the rest of a multi-statement script after a statement fails; by default it stops.
+	ContinueOnError bool
+
+	tx         *transaction   // set by ExecutorPool when a transaction is open; nil runs on the autocommit pool
+	guard      *readOnlyGuard // set by ExecutorPool for client SQL on a read-only connection outside a transaction
+	slot       *poolSlot      // set by ExecutorPool outside a transaction: the pool session the call runs on
+	clientSQL  bool           // the SQL comes from the client (not a catalog query of this package)
+	keepCursor bool           // leave a truncated final query's cursor open in ExecutionResult.cursor
 }
 
 // Executor handles Oracle database connections and SQL execution.
@@ -38,6 +82,10 @@ type Executor struct {
 	dsn string
 }
 
+// MaxOpenConns is the size of each connection's session pool. ExecutorPool lets at most this many autocommit
+// calls, kept cursors and open transactions use one connection at a time; further calls wait for a slot.
+const MaxOpenConns = 5
+
 // NewExecutor creates a new Oracle executor with the given DSN.
 func NewExecutor(dsn string) (*Executor, error) {
 	db, err := sql.Open("godror", dsn)
@@ -46,7 +94,7 @@ func NewExecutor(dsn string) (*Executor, error) {
 	}
 
 	// Configure connection pool
-	db.SetMaxOpenConns(5)
+	db.SetMaxOpenConns(MaxOpenConns)
 	db.SetMaxIdleConns(2)
 	db.SetConnMaxLifetime(time.Hour)
 
@@ -73,195 +121,179 @@ func (e *Executor) Close() error {
 	return nil
 }
 
+// session calls fn with one database session: the open transaction's (or read-only guard's) when opts has one,
+// otherwise a session reserved from the pool for the duration of fn. Use it when statements depend on session state.
+func (e *Executor) session(ctx context.Context, opts ExecOptions, fn func(q queryer) error) error {
+	if opts.tx != nil {
+		return fn(opts.tx.tx)
+	}
+	if opts.guard != nil {
+		return fn(opts.guard.tx)
+	}
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reserve a connection: %w", err)
+	}
+	defer conn.Close()
+	return fn(conn)
+}
+
 // Execute runs the given SQL (single or multiple statements) and returns the result.
-// Order: (1) lines that are only "/" split like SQL*Plus/SQLcl — must run before IsSingleStatementBlock,
-// otherwise a script that starts with BEGIN and contains " end " (e.g. END IF) is misclassified as one
-// anonymous block and "/" is sent to Oracle. (2) single PL/SQL creation or anonymous block. (3) split on ";\n".
-// Each fragment is executed via the existing driver (godror / ODPI-C, typically with Instant Client).
-func (e *Executor) Execute(ctx context.Context, sqlText string, statementType string) (*ExecutionResult, error) {
+// The script is split like SQL*Plus/SQLcl (sqlanalyzer.SplitStatements): ";" ends a SQL statement, while a PL/SQL
+// block or unit runs to a line holding only "/" (or the end of the script), which is never sent to Oracle.
+// Each statement is executed via the existing driver (godror / ODPI-C, typically with Instant Client).
+func (e *Executor) Execute(ctx context.Context, sqlText string, statementType string, opts ExecOptions) (*ExecutionResult, error) {
 	start := time.Now()
 	result := &ExecutionResult{
 		StatementType: statementType,
 		Success:       false,
-	}
-
-	normalized := strings.ReplaceAll(strings.TrimSpace(sqlText), "\r\n", "\n")
-	normalized = strings.ReplaceAll(normalized, "\r", "\n")
-
-	var statements []string
-	if hasStandaloneSlashLine(normalized) {
-		// SQL*Plus / SQLcl: "/" on its own line terminates a PL/SQL buffer. Splitting on ";\n"
-		// breaks blocks that contain semicolons inside BEGIN...END.
-		statements = splitBySlashLines(normalized)
-	} else if sqlanalyzer.IsSingleStatementBlock(normalized) {
-		statements = []string{normalized}
-	} else {
-		statements = splitStatements(normalized)
-	}
-
-	for _, raw := range statements {
-		for _, st := range expandStatementSegment(raw) {
-			st = strings.TrimSpace(st)
-			if st == "" {
-				continue
-			}
-			if !strings.HasSuffix(st, ";") {
-				st = st + ";"
-			}
-			// Keep trailing semicolon for PL/SQL creation and anonymous blocks (BEGIN...END;) so Oracle compiles/runs correctly
-			if !sqlanalyzer.KeepTrailingSemicolon(st) {
-				st = strings.TrimSuffix(st, ";") // Oracle driver does not want trailing semicolon for ordinary SQL
-			}
-			st = strings.TrimSpace(st)
-			upper := strings.ToUpper(st)
-			isQuery := strings.HasPrefix(upper, "SELECT") || strings.HasPrefix(upper, "WITH")
-			if isQuery {
-				if err := e.executeQuery(ctx, st, result); err != nil {
-					return nil, err
+		InTransaction: opts.tx != nil,
+	}
+	var q queryer = e.db
+	if opts.tx != nil {
+		q = opts.tx.tx
+	} else if opts.guard != nil {
+		q = opts.guard.tx
+	}
+	if opts.keepCursor {
+		// A kept cursor outlives this call, but database/sql closes rows when their context ends: run on a
+		// context that the caller's ctx (timeout, cancellation) can only cancel until Execute returns.
+		parent := ctx
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(context.WithoutCancel(parent))
+		stop := context.AfterFunc(parent, cancel)
+		defer func() {
+			stop()
+			if result.cursor != nil {
+				result.cursor.cancel = cancel
+				if opts.guard != nil {
+					// The rows belong to the read-only transaction: it ends when the cursor closes
+					result.cursor.guard = opts.guard
+					opts.guard.kept = true
 				}
-			} else {
-				if err := e.executeStatement(ctx, st, result); err != nil {
-					return nil, err
+				if opts.slot != nil {
+					// The rows pin their pool session until the cursor closes
+					result.cursor.slot = opts.slot
+					opts.slot.kept = true
 				}
+			} else {
+				cancel()
 			}
-		}
+		}()
 	}
 
-	result.ExecutionTime = time.Since(start).Milliseconds()
-	result.Success = true
-	if isDDLStatement(statementType) {
-		result.Warning = "DDL statements are auto-committed in Oracle"
-	}
-	return result, nil
-}
+	normalized := strings.ReplaceAll(sqlText, "\r\n", "\n")
+	normalized = strings.ReplaceAll(normalized, "\r", "\n")
 
-// hasStandaloneSlashLine reports whether the script uses a line containing only "/" (SQL*Plus run buffer).
-func hasStandaloneSlashLine(sql string) bool {
-	for _, line := range strings.Split(sql, "\n") {
-		if strings.TrimSpace(line) == "/" {
-			return true
-		}
+	statements := sqlanalyzer.SplitStatements(normalized)
+	pieces := make([]string, len(statements))
+	lines := make([]int, len(statements))
+	for i, st := range statements {
+		pieces[i], lines[i] = st.Text, st.Line
+	}
+	args := bindArgs(opts.Binds)
+	if len(args) > 0 && len(pieces) > 1 {
+		return nil, fmt.Errorf("binds are only supported for a single statement (got %d statements)", len(pieces))
 	}
-	return false
-}
 
-// splitBySlashLines splits a script on lines that are only "/" (after trim). Those lines are not
-// sent to Oracle. Empty segments are skipped.
-func splitBySlashLines(sql string) []string {
-	var parts []string
-	var b strings.Builder
-	firstLine := true
-	for _, line := range strings.Split(sql, "\n") {
-		if strings.TrimSpace(line) == "/" {
-			if p := strings.TrimSpace(b.String()); p != "" {
-				parts = append(parts, p)
-			}
-			b.Reset()
-			firstLine = true
-			continue
+	if len(pieces) > 1 {
+		if err := e.executeScript(ctx, q, pieces, lines, result, opts); err != nil {
+			return nil, err
 		}
-		if !firstLine {
-			b.WriteByte('\n')
+	} else if len(pieces) == 1 {
+		if err := e.executePiece(ctx, q, pieces[0], lines[0], result, opts, opts.keepCursor, args...); err != nil {
+			return nil, err
 		}
-		b.WriteString(line)
-		firstLine = false
-	}
-	if p := strings.TrimSpace(b.String()); p != "" {
-		parts = append(parts, p)
 	}
-	return parts
-}
 
-// expandStatementSegment splits one chunk from the script (e.g. between "/" lines) into executable pieces:
-// nested "/" lines (each sub-chunk expanded again), else a single PL/SQL block, else ";\n"-separated statements.
-func expandStatementSegment(sql string) []string {
-	sql = strings.TrimSpace(sql)
-	if sql == "" {
-		return nil
+	result.ExecutionTime = time.Since(start).Milliseconds()
+	result.Success = result.Script == nil || result.Script.Failed == 0
+	if isDDLStatement(statementType) {
+		result.Warning = "DDL statements are auto-committed in Oracle"
 	}
-	if hasStandaloneSlashLine(sql) {
-		var out []string
-		for _, chunk := range splitBySlashLines(sql) {
-			out = append(out, expandStatementSegment(chunk)...)
-		}
-		return out
+	compiled := result.CompileErrors
+	for _, st := range result.Statements {
+		compiled = append(compiled, st.CompileErrors...)
 	}
-	if sqlanalyzer.IsSingleStatementBlock(sql) {
-		return []string{sql}
+	if n := invalidObjects(compiled); n > 0 {
+		result.Warning = fmt.Sprintf("%d PL/SQL object(s) compiled with errors and are invalid; see compile_errors", n)
 	}
-	return splitStatements(sql)
+	return result, nil
 }
 
-// splitStatements splits SQL by semicolon at end of line (;\n). Used for multi-statement scripts.
-func splitStatements(sql string) []string {
-	const sep = ";\n"
-	var out []string
-	for {
-		i := strings.Index(sql, sep)
-		if i < 0 {
-			break
+// executePiece runs one statement of the script (as split by Execute), starting on the given script line, into
+// result. keep leaves a truncated query's cursor open in result.cursor.
+func (e *Executor) executePiece(ctx context.Context, q queryer, st string, line int, result *ExecutionResult, opts ExecOptions, keep bool, args ...interface{}) error {
+	if !strings.HasSuffix(st, ";") {
+		st = st + ";"
+	}
+	// Keep trailing semicolon for PL/SQL creation and anonymous blocks (BEGIN...END;) so Oracle compiles/runs correctly
+	if !sqlanalyzer.KeepTrailingSemicolon(st) {
+		st = strings.TrimSuffix(st, ";") // Oracle driver does not want trailing semicolon for ordinary SQL
+	}
+	st = strings.TrimSpace(st)
+	// Classified by its first word as the lexer sees it: a statement of a script keeps its leading comments
+	stmtType := sqlanalyzer.GetStatementType(st)
+	switch {
+	case stmtType == "SELECT" || stmtType == "WITH":
+		return e.executeQuery(ctx, q, st, result, opts.MaxRows, keep, args...)
+	case sqlanalyzer.IsPLSQLCreationStatement(st):
+		// Oracle creates the object even when it does not compile; report the errors instead of plain success
+		if err := e.executeStatement(ctx, q, st, result, args...); err != nil && !isCompileWarning(err) {
+			return err
 		}
-		segment := strings.TrimSpace(sql[:i])
-		sql = sql[i+len(sep):]
-		if segment != "" {
-			out = append(out, segment)
+		errs, err := compileErrors(ctx, q, st, opts.SourceFile, line)
+		if err != nil {
+			return err
 		}
+		result.CompileErrors = append(result.CompileErrors, errs...)
+		return nil
+	case printsOutput(stmtType):
+		// DBMS_OUTPUT is per session: enable, run and drain on the same one
+		return e.session(ctx, opts, func(q queryer) error {
+			return e.executeBlock(ctx, q, st, result, args...)
+		})
+	default:
+		return e.executeStatement(ctx, q, st, result, args...)
 	}
-	if s := strings.TrimSpace(sql); s != "" {
-		out = append(out, s)
-	}
-	return out
 }
 
-// executeQuery handles SELECT statements.
-func (e *Executor) executeQuery(ctx context.Context, sqlText string, result *ExecutionResult) error {
-	rows, err := e.db.QueryContext(ctx, sqlText)
+// executeQuery handles SELECT statements. With maxRows > 0 at most maxRows rows are returned and Truncated is set
+// when more remain; keep leaves that cursor open in result.cursor instead of closing it.
+func (e *Executor) executeQuery(ctx context.Context, q queryer, sqlText string, result *ExecutionResult, maxRows int, keep bool, args ...interface{}) error {
+	rows, err := q.QueryContext(ctx, sqlText, args...)
 	if err != nil {
 		return fmt.Errorf("query execution failed: %w", err)
 	}
-	defer rows.Close()
 
 	// Get column names
 	columns, err := rows.Columns()
 	if err != nil {
+		rows.Close()
 		return fmt.Errorf("failed to get columns: %w", err)
 	}
 	result.Columns = columns
 
-	// Prepare scan destinations
-	numCols := len(columns)
-	result.Rows = make([][]interface{}, 0)
-
-	for rows.Next() {
-		// Create slice to hold column values
-		values := make([]interface{}, numCols)
-		valuePtrs := make([]interface{}, numCols)
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return fmt.Errorf("failed to scan row: %w", err)
-		}
-
-		// Convert values to proper types for JSON serialization
-		rowData := make([]interface{}, numCols)
-		for i, v := range values {
-			rowData[i] = convertValue(v)
-		}
-		result.Rows = append(result.Rows, rowData)
-	}
-
-	if err := rows.Err(); err != nil {
-		return fmt.Errorf("error iterating rows: %w", err)
+	c := &cursor{rows: rows, columns: columns}
+	page, more, err := c.readPage(maxRows)
+	if err != nil {
+		rows.Close()
+		return err
+	}
+	result.Rows = page
+	result.Truncated = more
+	result.RowsFetched = c.fetched
+	if more && keep {
+		result.cursor = c
+	} else {
+		rows.Close()
 	}
-
 	return nil
 }
 
 // executeStatement handles DML/DDL statements.
-func (e *Executor) executeStatement(ctx context.Context, sqlText string, result *ExecutionResult) error {
-	execResult, err := e.db.ExecContext(ctx, sqlText)
+func (e *Executor) executeStatement(ctx context.Context, q queryer, sqlText string, result *ExecutionResult, args ...interface{}) error {
+	execResult, err := q.ExecContext(ctx, sqlText, args...)
 	if err != nil {
 		return fmt.Errorf("statement execution failed: %w", err)
 	}
@@ -324,14 +356,20 @@ func (e *Executor) TestConnection(ctx context.Context) error {
 // ExecuteToCSVFile runs the SQL (same as Execute), then writes the result to a CSV file.
 // Header row + data rows, UTF-8. RFC 4180: fields containing comma, quote, or newline are quoted; " escaped as "".
 // CLOB columns are read in full (via convertValue). Returns rows written, or 0 and error on failure.
-func (e *Executor) ExecuteToCSVFile(ctx context.Context, sqlText string, filePath string) (int64, error) {
+func (e *Executor) ExecuteToCSVFile(ctx context.Context, sqlText string, filePath string, opts ExecOptions) (int64, error) {
 	stmtType := sqlanalyzer.GetStatementType(sqlText)
-	result, err := e.Execute(ctx, sqlText, stmtType)
+	result, err := e.Execute(ctx, sqlText, stmtType, opts)
 	if err != nil {
 		return 0, err
 	}
-	if !result.Success {
-		return 0, fmt.Errorf("execution failed: %s", result.Warning)
+	return writeCSVFile(filePath, result)
+}
+
+// writeCSVFile writes the rows of result (see exportRows) to a CSV file.
+func writeCSVFile(filePath string, result *ExecutionResult) (int64, error) {
+	columns, rows, err := exportRows(result)
+	if err != nil {
+		return 0, err
 	}
 
 	f, err := os.Create(filePath)
@@ -344,11 +382,11 @@ func (e *Executor) ExecuteToCSVFile(ctx context.Context, sqlText string, filePat
 	w := csv.NewWriter(f)
 	var rowsWritten int64
 
-	if len(result.Columns) > 0 && result.Rows != nil {
-		if err := w.Write(result.Columns); err != nil {
+	if len(columns) > 0 {
+		if err := w.Write(columns); err != nil {
 			return 0, fmt.Errorf("write header: %w", err)
 		}
-		for _, row := range result.Rows {
+		for _, row := range rows {
 			cells := make([]string, len(row))
 			for i, v := range row {
 				cells[i] = cellToString(v)
@@ -375,14 +413,20 @@ func (e *Executor) ExecuteToCSVFile(ctx context.Context, sqlText string, filePat
 // ExecuteToTextFile runs the SQL (same as Execute), then writes the result to a plain text file.
 // No header; columns tab-separated per row. No extra newlines between rows (only newlines in cell data are written).
 // CLOB columns are read in full. UTF-8. Returns rows written.
-func (e *Executor) ExecuteToTextFile(ctx context.Context, sqlText string, filePath string) (int64, error) {
+func (e *Executor) ExecuteToTextFile(ctx context.Context, sqlText string, filePath string, opts ExecOptions) (int64, error) {
 	stmtType := sqlanalyzer.GetStatementType(sqlText)
-	result, err := e.Execute(ctx, sqlText, stmtType)
+	result, err := e.Execute(ctx, sqlText, stmtType, opts)
 	if err != nil {
 		return 0, err
 	}
-	if !result.Success {
-		return 0, fmt.Errorf("execution failed: %s", result.Warning)
+	return writeTextFile(filePath, result)
+}
+
+// writeTextFile writes the rows of result (see exportRows) to a plain text file.
+func writeTextFile(filePath string, result *ExecutionResult) (int64, error) {
+	columns, rows, err := exportRows(result)
+	if err != nil {
+		return 0, err
 	}
 
 	f, err := os.Create(filePath)
@@ -393,8 +437,8 @@ func (e *Executor) ExecuteToTextFile(ctx context.Context, sqlText string, filePa
 
 	var rowsWritten int64
 
-	if len(result.Columns) > 0 && result.Rows != nil {
-		for _, row := range result.Rows {
+	if len(columns) > 0 {
+		for _, row := range rows {
 			for i, v := range row {
 				if i > 0 {
 					_, _ = f.WriteString("\t")
@@ -414,6 +458,21 @@ func (e *Executor) ExecuteToTextFile(ctx context.Context, sqlText string, filePa
 	return rowsWritten, nil
 }
 
+// exportRows returns the result set the query-to-file helpers write: the query's, or for a multi-statement script
+// that of the last statement that returned rows. No columns means no statement did, and the files get the rows
+// affected instead. A failed script is an error.
+func exportRows(result *ExecutionResult) ([]string, [][]interface{}, error) {
+	if !result.Success {
+		return nil, nil, fmt.Errorf("execution failed: %s", result.ScriptError())
+	}
+	for i := len(result.Statements) - 1; i >= 0; i-- {
+		if st := result.Statements[i]; len(st.Columns) > 0 {
+			return st.Columns, st.Rows, nil
+		}
+	}
+	return result.Columns, result.Rows, nil
+}
+
 // cellToString converts a cell value to string for CSV/text output.
 func cellToString(v interface{}) string {
 	if v == nil {
@@ -0,0 +1,242 @@
+// Package oracle: execution plans (explain_plan) from EXPLAIN PLAN or from the cursor cache after a real run.
+package oracle
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PlanOperation is one step of an execution plan; Children are the row sources it consumes.
+type PlanOperation struct {
+	ID               int    `json:"id"`
+	Operation        string `json:"operation"` // operation and options, e.g. "TABLE ACCESS BY INDEX ROWID"
+	Object           string `json:"object,omitempty"`
+	Alias            string `json:"alias,omitempty"`
+	Cost             *int64 `json:"cost,omitempty"`
+	Cardinality      *int64 `json:"cardinality,omitempty"` // estimated rows
+	Bytes            *int64 `json:"bytes,omitempty"`
+	TimeSeconds      *int64 `json:"time_seconds,omitempty"`
+	AccessPredicates string `json:"access_predicates,omitempty"`
+	FilterPredicates string `json:"filter_predicates,omitempty"`
+	PartitionStart   string `json:"partition_start,omitempty"`
+	PartitionStop    string `json:"partition_stop,omitempty"`
+
+	// Actual run statistics of the last execution (actual mode only)
+	ActualRows *int64 `json:"actual_rows,omitempty"`
+	Starts     *int64 `json:"starts,omitempty"`
+	ElapsedUS  *int64 `json:"elapsed_us,omitempty"`
+	BufferGets *int64 `json:"buffer_gets,omitempty"`
+
+	Children []*PlanOperation `json:"children,omitempty"`
+
+	parentID sql.NullInt64
+}
+
+// ExplainResult is the plan of one statement as DBMS_XPLAN text and as an operation tree.
+type ExplainResult struct {
+	Mode         string         `json:"mode"` // "estimate" (EXPLAIN PLAN) or "actual" (executed)
+	SQLID        string         `json:"sql_id,omitempty"`
+	RowsReturned *int64         `json:"rows_returned,omitempty"` // actual mode: rows the query returned (discarded)
+	Plan         *PlanOperation `json:"plan"`
+	PlanText     string         `json:"plan_text"`
+}
+
+// explainPrefix matches an EXPLAIN PLAN ... FOR prefix that callers may include.
+var explainPrefix = regexp.MustCompile(`(?is)^\s*explain\s+plan\b.*?\bfor\s+`)
+
+// ExplainPlan returns the execution plan of sqlText on the named connection. With actual false the statement is only
+// explained (EXPLAIN PLAN into the session's private PLAN_TABLE); with actual true it must be a query, which is run
+// to the end with row source statistics so the plan carries actual rows (DBMS_XPLAN.DISPLAY_CURSOR); that needs
+// access to V$SESSION and V$SQL_PLAN_STATISTICS_ALL. opts.Binds apply to the actual run.
+func (p *ExecutorPool) ExplainPlan(ctx context.Context, connectionName string, sqlText string, actual bool, opts ExecOptions) (*ExplainResult, error) {
+	stmt := ExplainTarget(sqlText)
+	if stmt == "" {
+		return nil, fmt.Errorf("no statement to explain")
+	}
+	var result *ExplainResult
+	// EXPLAIN PLAN writes PLAN_TABLE but does not run the statement; an actual run is client SQL
+	opts.clientSQL = actual
+	err := p.run(ctx, connectionName, opts, func(ctx context.Context, name string, ex *Executor, opts ExecOptions) error {
+		return ex.session(ctx, opts, func(q queryer) error {
+			var err error
+			if actual {
+				result, err = explainActual(ctx, q, stmt, bindArgs(opts.Binds))
+			} else {
+				result, err = explainEstimate(ctx, q, stmt)
+			}
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ExplainTarget returns the statement to explain: sqlText without an EXPLAIN PLAN ... FOR prefix or trailing semicolon.
+func ExplainTarget(sqlText string) string {
+	stmt := explainPrefix.ReplaceAllString(sqlText, "")
+	return strings.TrimRight(strings.TrimSpace(stmt), "; \t\r\n")
+}
+
+// explainEstimate explains stmt into PLAN_TABLE (a global temporary table, so private to the session) under a
+// unique statement id and removes the rows afterwards.
+func explainEstimate(ctx context.Context, q queryer, stmt string) (*ExplainResult, error) {
+	b := make([]byte, 8)
+	rand.Read(b)
+	id := "MCP_" + hex.EncodeToString(b)
+	// The id is generated here, so it can be spliced into the statement
+	if _, err := q.ExecContext(ctx, "EXPLAIN PLAN SET STATEMENT_ID = '"+id+"' FOR "+stmt); err != nil {
+		return nil, fmt.Errorf("EXPLAIN PLAN failed: %w", err)
+	}
+	defer q.ExecContext(context.WithoutCancel(ctx), `DELETE FROM plan_table WHERE statement_id = :id`, sql.Named("id", id))
+
+	ops, err := planOperations(ctx, q, `SELECT id, parent_id, operation, options, object_owner, object_name, object_alias,
+       cost, cardinality, bytes, time, access_predicates, filter_predicates, partition_start, partition_stop,
+       NULL, NULL, NULL, NULL
+  FROM plan_table WHERE statement_id = :id ORDER BY id`, sql.Named("id", id))
+	if err != nil {
+		return nil, err
+	}
+	text, err := planText(ctx, q, `SELECT plan_table_output FROM TABLE(DBMS_XPLAN.DISPLAY('PLAN_TABLE', :id, 'TYPICAL'))`, sql.Named("id", id))
+	if err != nil {
+		return nil, err
+	}
+	return &ExplainResult{Mode: "estimate", Plan: buildPlanTree(ops), PlanText: text}, nil
+}
+
+// explainActual runs the query with statistics_level = ALL, then reads its plan and row source statistics from the
+// cursor cache.
+func explainActual(ctx context.Context, q queryer, stmt string, args []interface{}) (*ExplainResult, error) {
+	if _, err := q.ExecContext(ctx, `ALTER SESSION SET statistics_level = ALL`); err != nil {
+		return nil, fmt.Errorf("enabling row source statistics failed: %w", err)
+	}
+	defer q.ExecContext(context.WithoutCancel(ctx), `ALTER SESSION SET statistics_level = TYPICAL`)
+
+	rows, err := q.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query execution failed: %w", err)
+	}
+	var n int64
+	for rows.Next() {
+		n++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	// The session's previous statement is the query just run
+	var sqlID string
+	var child int64
+	if err := q.QueryRowContext(ctx, `SELECT prev_sql_id, prev_child_number FROM v$session WHERE sid = SYS_CONTEXT('USERENV', 'SID')`).Scan(&sqlID, &child); err != nil {
+		return nil, cursorCacheError(err)
+	}
+	ops, err := planOperations(ctx, q, `SELECT id, parent_id, operation, options, object_owner, object_name, object_alias,
+       cost, cardinality, bytes, time, access_predicates, filter_predicates, partition_start, partition_stop,
+       last_output_rows, last_starts, last_elapsed_time, last_cr_buffer_gets
+  FROM v$sql_plan_statistics_all WHERE sql_id = :sql_id AND child_number = :child ORDER BY id`,
+		sql.Named("sql_id", sqlID), sql.Named("child", child))
+	if err != nil {
+		return nil, cursorCacheError(err)
+	}
+	text, err := planText(ctx, q, `SELECT plan_table_output FROM TABLE(DBMS_XPLAN.DISPLAY_CURSOR(:sql_id, :child, 'ALLSTATS LAST +COST +BYTES'))`,
+		sql.Named("sql_id", sqlID), sql.Named("child", child))
+	if err != nil {
+		return nil, cursorCacheError(err)
+	}
+	return &ExplainResult{Mode: "actual", SQLID: sqlID, RowsReturned: &n, Plan: buildPlanTree(ops), PlanText: text}, nil
+}
+
+// cursorCacheError explains the privileges actual mode needs when the dynamic performance views are not visible.
+func cursorCacheError(err error) error {
+	if strings.Contains(err.Error(), "ORA-00942") {
+		return fmt.Errorf("actual mode needs SELECT on V$SESSION, V$SQL_PLAN_STATISTICS_ALL and V$SQL (e.g. SELECT_CATALOG_ROLE): %w", err)
+	}
+	return fmt.Errorf("query failed: %w", err)
+}
+
+// planOperations reads plan rows in the column order of the plan queries above.
+func planOperations(ctx context.Context, q queryer, query string, args ...interface{}) ([]*PlanOperation, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+	var ops []*PlanOperation
+	for rows.Next() {
+		op := &PlanOperation{}
+		var operation, options, owner, object, alias, access, filter, pstart, pstop sql.NullString
+		var cost, card, bytes, secs, actualRows, starts, elapsed, gets sql.NullInt64
+		if err := rows.Scan(&op.ID, &op.parentID, &operation, &options, &owner, &object, &alias,
+			&cost, &card, &bytes, &secs, &access, &filter, &pstart, &pstop,
+			&actualRows, &starts, &elapsed, &gets); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		op.Operation = strings.TrimSpace(operation.String + " " + options.String)
+		if object.String != "" {
+			op.Object = qualifiedName(owner.String, object.String)
+		}
+		op.Alias = alias.String
+		op.AccessPredicates, op.FilterPredicates = access.String, filter.String
+		op.PartitionStart, op.PartitionStop = pstart.String, pstop.String
+		op.Cost, op.Cardinality, op.Bytes, op.TimeSeconds = nullInt(cost), nullInt(card), nullInt(bytes), nullInt(secs)
+		op.ActualRows, op.Starts, op.ElapsedUS, op.BufferGets = nullInt(actualRows), nullInt(starts), nullInt(elapsed), nullInt(gets)
+		ops = append(ops, op)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("no plan found for the statement")
+	}
+	return ops, nil
+}
+
+// planText returns the DBMS_XPLAN output as one string.
+func planText(ctx context.Context, q queryer, query string, args ...interface{}) (string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return "", fmt.Errorf("DBMS_XPLAN failed: %w", err)
+	}
+	defer rows.Close()
+	var lines []string
+	for rows.Next() {
+		var line sql.NullString
+		if err := rows.Scan(&line); err != nil {
+			return "", fmt.Errorf("failed to scan row: %w", err)
+		}
+		lines = append(lines, line.String)
+	}
+	return strings.Join(lines, "\n"), rows.Err()
+}
+
+// buildPlanTree links the operations (ordered by id) under their parents and returns the root.
+func buildPlanTree(ops []*PlanOperation) *PlanOperation {
+	byID := make(map[int]*PlanOperation, len(ops))
+	var root *PlanOperation
+	for _, op := range ops {
+		byID[op.ID] = op
+	}
+	for _, op := range ops {
+		if parent, ok := byID[int(op.parentID.Int64)]; op.parentID.Valid && ok {
+			parent.Children = append(parent.Children, op)
+		} else if root == nil {
+			root = op
+		}
+	}
+	return root
+}
+
+// nullInt returns a pointer to the value, or nil for NULL.
+func nullInt(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int64
+}
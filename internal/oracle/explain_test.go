@@ -0,0 +1,37 @@
+package oracle
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestExplainTarget(t *testing.T) {
+	for in, want := range map[string]string{
+		"SELECT * FROM emp;":                                              "SELECT * FROM emp",
+		"explain plan for delete from emp where id = 1":                   "delete from emp where id = 1",
+		"EXPLAIN PLAN SET STATEMENT_ID = 'a' FOR\nSELECT 1 FROM dual ;\n": "SELECT 1 FROM dual",
+		"  ;  ": "",
+	} {
+		if got := ExplainTarget(in); got != want {
+			t.Errorf("ExplainTarget(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBuildPlanTree(t *testing.T) {
+	parent := func(id int64) sql.NullInt64 { return sql.NullInt64{Int64: id, Valid: true} }
+	ops := []*PlanOperation{
+		{ID: 0, Operation: "SELECT STATEMENT"},
+		{ID: 1, Operation: "HASH JOIN", parentID: parent(0)},
+		{ID: 2, Operation: "TABLE ACCESS FULL", Object: "HR.DEPARTMENTS", parentID: parent(1)},
+		{ID: 3, Operation: "TABLE ACCESS FULL", Object: "HR.EMPLOYEES", parentID: parent(1)},
+	}
+	root := buildPlanTree(ops)
+	if root == nil || root.ID != 0 || len(root.Children) != 1 {
+		t.Fatalf("root = %+v, want operation 0 with one child", root)
+	}
+	join := root.Children[0]
+	if join.ID != 1 || len(join.Children) != 2 || join.Children[0].Object != "HR.DEPARTMENTS" || join.Children[1].Object != "HR.EMPLOYEES" {
+		t.Errorf("join = %+v, want the two table scans in id order", join)
+	}
+}
@@ -7,16 +7,31 @@ import (
 	"log"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/godror/godror"
 )
 
 // ExecutorPool holds multiple Executors by name (e.g. "source", "target").
 // Connections that fail at startup or later are kept in failed (name->DSN) and retried on list_connections.
+// Each client session has at most one explicit transaction per connection name open at a time (see BeginTransaction).
 type ExecutorPool struct {
-	executors map[string]*Executor
-	failed    map[string]string // name -> DSN for retry
-	dsns      map[string]string // name -> DSN for all configured (used when demoting a connection to failed)
-	names     []string          // all configured names, stable order
-	mu        sync.RWMutex
+	executors     map[string]*Executor
+	failed        map[string]string      // name -> DSN for retry
+	dsns          map[string]string      // name -> DSN for all configured (used when demoting a connection to failed)
+	names         []string               // all configured names, stable order
+	txs           map[txKey]*transaction // (session, name) -> open transaction
+	txIdleTimeout time.Duration
+	cursors       map[string]*cursor // result handle -> open cursor (fetch_more)
+	cursorTTL     time.Duration
+	queryTimeout  time.Duration            // default query timeout; 0 = none
+	queryTimeouts map[string]time.Duration // name -> query timeout, overrides queryTimeout
+	slots         map[string]chan struct{} // name -> MaxOpenConns slots for autocommit calls
+	readOnly      map[string]bool          // names of read-only connections (SetReadOnly)
+	mu            sync.RWMutex
+
+	// OnIdleRollback, if set, is called after an open transaction was rolled back because it sat idle too long.
+	OnIdleRollback func(session string, connectionName string)
 }
 
 // NewExecutorPool creates a pool of executors from a name -> DSN map.
@@ -32,9 +47,16 @@ func NewExecutorPool(connections map[string]string) (*ExecutorPool, error) {
 		failed:    make(map[string]string),
 		dsns:      make(map[string]string),
 		names:     make([]string, 0, len(connections)),
+		txs:       make(map[txKey]*transaction),
+		cursors:   make(map[string]*cursor),
+		slots:     make(map[string]chan struct{}),
+
+		txIdleTimeout: DefaultTransactionIdleTimeout,
+		cursorTTL:     DefaultResultHandleTTL,
 	}
 	for name, dsn := range connections {
 		pool.dsns[name] = dsn
+		pool.slots[name] = make(chan struct{}, MaxOpenConns)
 	}
 	for name, dsn := range connections {
 		ex, err := NewExecutor(dsn)
@@ -51,8 +73,22 @@ func NewExecutorPool(connections map[string]string) (*ExecutorPool, error) {
 	return pool, nil
 }
 
-// Close closes all connections in the pool.
+// Close closes open result handles, rolls back open transactions and closes all connections in the pool.
 func (p *ExecutorPool) Close() {
+	p.closeCursors(nil)
+	p.mu.Lock()
+	txs := p.txs
+	p.txs = nil
+	p.mu.Unlock()
+	for key, t := range txs {
+		t.mu.Lock()
+		if !t.closed {
+			t.end(false)
+			log.Printf("oracle-mcp: open transaction on %q rolled back at shutdown", key.connection)
+		}
+		t.mu.Unlock()
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	for _, ex := range p.executors {
@@ -68,6 +104,7 @@ func (p *ExecutorPool) Close() {
 type ConnectionStatus struct {
 	Name      string `json:"name"`
 	Available bool   `json:"available"`
+	ReadOnly  bool   `json:"read_only,omitempty"`
 }
 
 // RetryFailed tries to connect to all currently failed connections.
@@ -95,7 +132,7 @@ func (p *ExecutorPool) ListConnectionsWithStatus() []ConnectionStatus {
 	out := make([]ConnectionStatus, 0, len(p.names))
 	for _, name := range p.names {
 		_, ok := p.executors[name]
-		out = append(out, ConnectionStatus{Name: name, Available: ok})
+		out = append(out, ConnectionStatus{Name: name, Available: ok, ReadOnly: p.readOnly[name]})
 	}
 	return out
 }
@@ -109,80 +146,197 @@ func (p *ExecutorPool) Names() []string {
 	return out
 }
 
-// Execute runs SQL on the named connection. If connectionName is "" and there is exactly one connection, that one is used.
-func (p *ExecutorPool) Execute(ctx context.Context, connectionName string, sqlText string, statementType string) (*ExecutionResult, error) {
-	name := connectionName
-	if name == "" {
-		p.mu.RLock()
-		n := len(p.names)
-		if n == 1 {
-			name = p.names[0]
-		}
-		p.mu.RUnlock()
-		if name == "" {
-			return nil, fmt.Errorf("connection name is required when multiple databases are configured; use list_connections to see names")
-		}
-	}
-
+// DefaultSchema returns the schema unqualified names resolve to on the named connection: the user of its DSN,
+// upper-cased unless quoted. It returns "" for an unknown connection or a DSN without a user (external
+// authentication). ALTER SESSION SET CURRENT_SCHEMA is not taken into account.
+func (p *ExecutorPool) DefaultSchema(name string) string {
 	p.mu.RLock()
-	ex, ok := p.executors[name]
-	_, inFailed := p.failed[name]
+	dsn, ok := p.dsns[name]
 	p.mu.RUnlock()
 	if !ok {
-		if inFailed {
-			return nil, fmt.Errorf("connection %q is currently unavailable (connection failed); call list_connections to retry", name)
-		}
-		return nil, fmt.Errorf("unknown connection %q; use list_connections to see configured names", name)
+		return ""
 	}
-
-	result, err := ex.Execute(ctx, sqlText, statementType)
-	if err != nil && p.isConnectionError(err) {
-		p.markConnectionFailed(name, ex)
+	params, err := godror.ParseConnString(dsn)
+	if err != nil {
+		return ""
+	}
+	user := strings.TrimSpace(params.Username)
+	if strings.HasPrefix(user, `"`) {
+		return strings.Trim(user, `"`)
 	}
+	return strings.ToUpper(user)
+}
+
+// Execute runs SQL on the named connection. If connectionName is "" and there is exactly one connection, that one is used.
+// When a transaction is open on the connection, the SQL runs inside it. A query truncated by opts.MaxRows keeps
+// its cursor open under result.ResultHandle for FetchMore.
+func (p *ExecutorPool) Execute(ctx context.Context, connectionName string, sqlText string, statementType string, opts ExecOptions) (*ExecutionResult, error) {
+	var result *ExecutionResult
+	opts.keepCursor = true
+	opts.clientSQL = true
+	err := p.run(ctx, connectionName, opts, func(ctx context.Context, name string, ex *Executor, opts ExecOptions) error {
+		var err error
+		result, err = ex.Execute(ctx, sqlText, statementType, opts)
+		if err == nil && result.cursor != nil {
+			result.ResultHandle = p.registerCursor(opts.Session, name, opts.tx, result.cursor)
+			result.cursor = nil
+		}
+		return err
+	})
 	return result, err
 }
 
 // ExecuteToCSVFile runs the SQL on the named connection and writes the result to a CSV file.
 // filePath must be absolute. Returns rows written.
-func (p *ExecutorPool) ExecuteToCSVFile(ctx context.Context, connectionName string, sqlText string, filePath string) (int64, error) {
-	name, ex, err := p.executorByName(connectionName)
-	if err != nil {
-		return 0, err
-	}
-	n, err := ex.ExecuteToCSVFile(ctx, sqlText, filePath)
-	if err != nil && p.isConnectionError(err) {
-		p.markConnectionFailed(name, ex)
-	}
+func (p *ExecutorPool) ExecuteToCSVFile(ctx context.Context, connectionName string, sqlText string, filePath string, opts ExecOptions) (int64, error) {
+	var n int64
+	opts.clientSQL = true
+	err := p.run(ctx, connectionName, opts, func(ctx context.Context, name string, ex *Executor, opts ExecOptions) error {
+		var err error
+		n, err = ex.ExecuteToCSVFile(ctx, sqlText, filePath, opts)
+		return err
+	})
 	return n, err
 }
 
 // ExecuteToTextFile runs the SQL on the named connection and writes the result to a plain text file.
 // filePath must be absolute. Returns rows written.
-func (p *ExecutorPool) ExecuteToTextFile(ctx context.Context, connectionName string, sqlText string, filePath string) (int64, error) {
+func (p *ExecutorPool) ExecuteToTextFile(ctx context.Context, connectionName string, sqlText string, filePath string, opts ExecOptions) (int64, error) {
+	var n int64
+	opts.clientSQL = true
+	err := p.run(ctx, connectionName, opts, func(ctx context.Context, name string, ex *Executor, opts ExecOptions) error {
+		var err error
+		n, err = ex.ExecuteToTextFile(ctx, sqlText, filePath, opts)
+		return err
+	})
+	return n, err
+}
+
+// CountRows runs a SELECT COUNT(*) query on the named connection (in the session's open transaction, if any) and
+// returns the count.
+func (p *ExecutorPool) CountRows(ctx context.Context, connectionName string, query string, opts ExecOptions) (int64, error) {
+	var n int64
+	opts.clientSQL = true
+	err := p.run(ctx, connectionName, opts, func(ctx context.Context, name string, ex *Executor, opts ExecOptions) error {
+		return ex.session(ctx, opts, func(q queryer) error {
+			return q.QueryRowContext(ctx, query).Scan(&n)
+		})
+	})
+	return n, err
+}
+
+// run calls fn with the named connection's executor, inside its open transaction when there is one, and with the
+// query timeout applied to ctx. Outside a transaction it first waits for one of the connection's MaxOpenConns slots
+// (the wait counts toward the timeout; a kept cursor holds the slot on), and client SQL on a read-only connection
+// runs in a read-only transaction.
+// A connection error demotes the connection to failed (and drops its transaction); an error after the timeout is
+// returned as *TimeoutError.
+func (p *ExecutorPool) run(ctx context.Context, connectionName string, opts ExecOptions, fn func(ctx context.Context, name string, ex *Executor, opts ExecOptions) error) error {
 	name, ex, err := p.executorByName(connectionName)
 	if err != nil {
-		return 0, err
+		return err
 	}
-	n, err := ex.ExecuteToTextFile(ctx, sqlText, filePath)
-	if err != nil && p.isConnectionError(err) {
+	timeout, err := p.QueryTimeout(name, opts.Timeout)
+	if err != nil {
+		return err
+	}
+	runCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	t, err := p.lockTransaction(txKey{opts.Session, name})
+	if err != nil {
+		return err
+	}
+	if t != nil {
+		opts.tx = t
+		defer p.releaseTransaction(t)
+		err = fn(runCtx, name, ex, opts)
+	} else if opts.slot, err = p.acquireSlot(runCtx, name); err == nil {
+		defer func() {
+			if !opts.slot.kept {
+				opts.slot.release()
+			}
+		}()
+		if opts.clientSQL && p.ReadOnly(name) {
+			opts.guard, err = beginReadOnly(runCtx, ex)
+		}
+		if err == nil {
+			err = fn(runCtx, name, ex, opts)
+		}
+		if opts.guard != nil && !opts.guard.kept {
+			opts.guard.end()
+		}
+	}
+	if err != nil && ctx.Err() == nil && runCtx.Err() == context.DeadlineExceeded {
+		return &TimeoutError{Connection: name, Timeout: timeout, Err: err}
+	}
+	// A cancelled call breaks the statement, not the session
+	if err != nil && ctx.Err() == nil && isConnectionError(err) {
+		if t != nil {
+			p.dropTransaction(t)
+			log.Printf("oracle-mcp: transaction on %q lost with its connection", name)
+		}
 		p.markConnectionFailed(name, ex)
 	}
-	return n, err
+	return err
+}
+
+// poolSlot is one of a connection's MaxOpenConns slots, each standing for one session of its pool. An autocommit
+// call holds one while it runs; a kept cursor (kept set) and an open transaction hold theirs until they close, so
+// a call never waits inside database/sql for a session that is pinned.
+type poolSlot struct {
+	slots chan struct{}
+	kept  bool // handed over to a kept cursor, which releases it on close
+
+	once sync.Once
+}
+
+// release frees the slot; later calls do nothing. A nil slot (unknown connection) is ignored.
+func (s *poolSlot) release() {
+	if s == nil {
+		return
+	}
+	s.once.Do(func() { <-s.slots })
+}
+
+// acquireSlot waits until one of name's MaxOpenConns slots is free, or ctx ends. It returns nil for a name
+// without slots.
+func (p *ExecutorPool) acquireSlot(ctx context.Context, name string) (*poolSlot, error) {
+	p.mu.RLock()
+	slots := p.slots[name]
+	p.mu.RUnlock()
+	if slots == nil {
+		return nil, nil
+	}
+	select {
+	case slots <- struct{}{}:
+		return &poolSlot{slots: slots}, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a free session on %q: %w", name, ctx.Err())
+	}
+}
+
+// resolveName returns connectionName, or the only configured name when connectionName is "".
+func (p *ExecutorPool) resolveName(connectionName string) (string, error) {
+	if connectionName != "" {
+		return connectionName, nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(p.names) == 1 {
+		return p.names[0], nil
+	}
+	return "", fmt.Errorf("connection name is required when multiple databases are configured; use list_connections to see names")
 }
 
 // executorByName returns the resolved connection name and executor, or error if not found / unavailable.
 func (p *ExecutorPool) executorByName(connectionName string) (resolvedName string, ex *Executor, err error) {
-	name := connectionName
-	if name == "" {
-		p.mu.RLock()
-		n := len(p.names)
-		if n == 1 {
-			name = p.names[0]
-		}
-		p.mu.RUnlock()
-		if name == "" {
-			return "", nil, fmt.Errorf("connection name is required when multiple databases are configured; use list_connections to see names")
-		}
+	name, err := p.resolveName(connectionName)
+	if err != nil {
+		return "", nil, err
 	}
 
 	p.mu.RLock()
@@ -200,7 +354,7 @@ func (p *ExecutorPool) executorByName(connectionName string) (resolvedName strin
 
 // isConnectionError returns true if the error indicates a broken/dead connection
 // (TNS, listener, network, etc.) so we can demote the connection to failed.
-func (p *ExecutorPool) isConnectionError(err error) bool {
+func isConnectionError(err error) bool {
 	if err == nil {
 		return false
 	}
@@ -216,6 +370,7 @@ func (p *ExecutorPool) isConnectionError(err error) bool {
 
 // markConnectionFailed moves the connection from executors to failed (closed and will be retried on list_connections).
 func (p *ExecutorPool) markConnectionFailed(name string, ex *Executor) {
+	p.closeCursors(func(c *cursor) bool { return c.connection == name })
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	if p.executors == nil {
@@ -0,0 +1,38 @@
+package oracle
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquireSlot_LimitsConcurrentCalls(t *testing.T) {
+	p := &ExecutorPool{slots: map[string]chan struct{}{"db1": make(chan struct{}, MaxOpenConns)}}
+	var held []*poolSlot
+	for i := 0; i < MaxOpenConns; i++ {
+		slot, err := p.acquireSlot(context.Background(), "db1")
+		if err != nil {
+			t.Fatalf("slot %d: %v", i+1, err)
+		}
+		held = append(held, slot)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.acquireSlot(ctx, "db1"); err == nil || !strings.Contains(err.Error(), `waiting for a free session on "db1"`) {
+		t.Fatalf("slot %d: err = %v, want to wait until ctx ends", MaxOpenConns+1, err)
+	}
+
+	// Releasing twice frees one slot only
+	held[0].release()
+	held[0].release()
+	if _, err := p.acquireSlot(context.Background(), "db1"); err != nil {
+		t.Errorf("slot after release: %v", err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.acquireSlot(ctx, "db1"); err == nil {
+		t.Error("a slot released twice was handed out twice")
+	}
+}
@@ -0,0 +1,65 @@
+// Package oracle: read-only connections, whose autocommit calls run inside a read-only transaction.
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// readOnlyGuard is the read-only transaction an autocommit call on a read-only connection runs in (SET TRANSACTION
+// READ ONLY): Oracle refuses DML, SELECT FOR UPDATE and other writes in it with ORA-01456. It ends with the call,
+// or with the result's cursor when one is kept for fetch_more.
+type readOnlyGuard struct {
+	tx   *sql.Tx
+	kept bool // handed over to a kept cursor, which ends it on close
+
+	once sync.Once
+}
+
+// SetReadOnly marks the named connections read-only: calls outside an explicit transaction run in a read-only
+// transaction, and BeginTransaction opens read-only transactions. Callers are expected to refuse anything but
+// queries before they get here; the transaction is the second line of defence.
+func (p *ExecutorPool) SetReadOnly(names []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.readOnly = make(map[string]bool, len(names))
+	for _, name := range names {
+		p.readOnly[name] = true
+	}
+}
+
+// ReadOnly reports whether the named connection is read-only. "" stands for the only configured connection.
+func (p *ExecutorPool) ReadOnly(connectionName string) bool {
+	name, err := p.resolveName(connectionName)
+	if err != nil {
+		return false
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.readOnly[name]
+}
+
+// beginReadOnly starts the read-only transaction for one call on ex. Like BeginTransaction it does not tie the
+// transaction to ctx: a kept cursor outlives the call.
+func beginReadOnly(ctx context.Context, ex *Executor) (*readOnlyGuard, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	tx, err := ex.db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin read-only transaction: %w", err)
+	}
+	return &readOnlyGuard{tx: tx}, nil
+}
+
+// end rolls the transaction back (it holds no changes) and returns its session to the pool.
+func (g *readOnlyGuard) end() {
+	g.once.Do(func() {
+		if err := g.tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("oracle-mcp: ending read-only transaction: %v", err)
+		}
+	})
+}
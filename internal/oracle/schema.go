@@ -0,0 +1,636 @@
+// Package oracle: schema introspection (list_schemas, list_tables, describe_table, search_objects) on the ALL_* views.
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Result size of ListTables and SearchObjects when the caller passes no limit, and the largest limit accepted.
+const (
+	DefaultIntrospectionLimit = 200
+	MaxIntrospectionLimit     = 1000
+)
+
+// maxSynonymHops bounds synonym chains followed by DescribeTable (Oracle itself rejects loops only on use).
+const maxSynonymHops = 8
+
+// SearchableObjectTypes are the object types SearchObjects accepts; DefaultSearchObjectTypes is used when none are given.
+var (
+	SearchableObjectTypes    = []string{"TABLE", "VIEW", "MATERIALIZED VIEW", "SYNONYM", "PACKAGE", "PROCEDURE", "FUNCTION", "SEQUENCE", "TYPE", "TRIGGER"}
+	DefaultSearchObjectTypes = []string{"TABLE", "VIEW", "SYNONYM", "PACKAGE"}
+)
+
+// SchemaInfo is one schema (database user) visible to the connected user.
+type SchemaInfo struct {
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	Tables  int64     `json:"tables"`            // tables visible to the connected user
+	Current bool      `json:"current,omitempty"` // the session's current schema
+}
+
+// TableFilter selects the tables returned by ListTables.
+type TableFilter struct {
+	Owner        string // schema; "" uses the session's current schema
+	Pattern      string // case-insensitive LIKE pattern on the name; without % it matches anywhere in the name
+	IncludeViews bool
+	Limit        int // 0 uses DefaultIntrospectionLimit
+}
+
+// TableInfo is one table or view in a ListTables result.
+type TableInfo struct {
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+	Type  string `json:"type"` // TABLE or VIEW
+	// RowsEstimate is NUM_ROWS from the optimizer statistics (omitted when the table was never analyzed).
+	RowsEstimate *int64     `json:"rows_estimate,omitempty"`
+	LastAnalyzed *time.Time `json:"last_analyzed,omitempty"`
+	Partitioned  bool       `json:"partitioned,omitempty"`
+	Temporary    bool       `json:"temporary,omitempty"`
+	Comment      string     `json:"comment,omitempty"`
+}
+
+// TableDescription is the structure of a table or view returned by DescribeTable.
+type TableDescription struct {
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+	Type  string `json:"type"` // TABLE, VIEW or MATERIALIZED VIEW
+	// ResolvedFrom lists the synonyms followed to reach the object, in order (e.g. PUBLIC.EMPLOYEES).
+	ResolvedFrom []string     `json:"resolved_from,omitempty"`
+	Comment      string       `json:"comment,omitempty"`
+	RowsEstimate *int64       `json:"rows_estimate,omitempty"`
+	LastAnalyzed *time.Time   `json:"last_analyzed,omitempty"`
+	Columns      []ColumnInfo `json:"columns"`
+	PrimaryKey   *Constraint  `json:"primary_key,omitempty"`
+	UniqueKeys   []Constraint `json:"unique_keys,omitempty"`
+	ForeignKeys  []Constraint `json:"foreign_keys,omitempty"`
+	Indexes      []IndexInfo  `json:"indexes,omitempty"`
+}
+
+// ColumnInfo describes one column.
+type ColumnInfo struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"` // as in DDL, e.g. VARCHAR2(30 CHAR), NUMBER(10,2), TIMESTAMP(6)
+	Nullable bool   `json:"nullable"`
+	Default  string `json:"default,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// Constraint is a primary key, unique or foreign key constraint.
+type Constraint struct {
+	Name       string         `json:"name"`
+	Columns    []string       `json:"columns"`
+	Disabled   bool           `json:"disabled,omitempty"`
+	References *ConstraintRef `json:"references,omitempty"` // foreign keys only
+	OnDelete   string         `json:"on_delete,omitempty"`  // CASCADE or SET NULL; omitted for NO ACTION
+}
+
+// ConstraintRef is the key a foreign key references.
+type ConstraintRef struct {
+	Owner      string   `json:"owner"`
+	Table      string   `json:"table"`
+	Constraint string   `json:"constraint"`
+	Columns    []string `json:"columns"`
+}
+
+// IndexInfo describes one index on a table.
+type IndexInfo struct {
+	Owner   string   `json:"owner"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"` // NORMAL, BITMAP, FUNCTION-BASED NORMAL, ...
+	Unique  bool     `json:"unique"`
+	Columns []string `json:"columns"` // descending columns carry a " DESC" suffix
+	Status  string   `json:"status,omitempty"`
+}
+
+// ObjectSearch selects the objects returned by SearchObjects.
+type ObjectSearch struct {
+	Pattern string   // case-insensitive LIKE pattern on the name; without % it matches anywhere in the name
+	Owner   string   // "" searches every visible schema
+	Types   []string // from SearchableObjectTypes; empty uses DefaultSearchObjectTypes
+	Limit   int      // 0 uses DefaultIntrospectionLimit
+}
+
+// ObjectInfo is one object in a SearchObjects result.
+type ObjectInfo struct {
+	Owner       string     `json:"owner"`
+	Name        string     `json:"name"`
+	Type        string     `json:"type"`
+	Status      string     `json:"status"`
+	LastDDLTime *time.Time `json:"last_ddl_time,omitempty"`
+	Target      string     `json:"target,omitempty"` // synonyms: OWNER.NAME[@DBLINK] they point to
+}
+
+// ListSchemas returns the schemas visible on the named connection whose name matches pattern ("" for all).
+func (p *ExecutorPool) ListSchemas(ctx context.Context, connectionName string, pattern string, opts ExecOptions) ([]SchemaInfo, error) {
+	schemas := make([]SchemaInfo, 0)
+	err := p.introspect(ctx, connectionName, opts, func(ctx context.Context, q queryer) error {
+		rows, err := q.QueryContext(ctx, `SELECT u.username, u.created, NVL(t.cnt, 0),
+       CASE WHEN u.username = SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') THEN 1 ELSE 0 END
+  FROM all_users u
+  LEFT JOIN (SELECT owner, COUNT(*) cnt FROM all_tables GROUP BY owner) t ON t.owner = u.username
+ WHERE UPPER(u.username) LIKE :pattern
+ ORDER BY u.username`, sql.Named("pattern", likePattern(pattern)))
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var s SchemaInfo
+			var current int
+			if err := rows.Scan(&s.Name, &s.Created, &s.Tables, &current); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			s.Current = current == 1
+			schemas = append(schemas, s)
+		}
+		return rows.Err()
+	})
+	return schemas, err
+}
+
+// ListTables returns the tables (and views, when filter.IncludeViews) of one schema, with the optimizer's row
+// estimates. truncated is set when more than the limit matched.
+func (p *ExecutorPool) ListTables(ctx context.Context, connectionName string, filter TableFilter, opts ExecOptions) (tables []TableInfo, truncated bool, err error) {
+	limit := introspectionLimit(filter.Limit)
+	views := 0
+	if filter.IncludeViews {
+		views = 1
+	}
+	tables = make([]TableInfo, 0)
+	err = p.introspect(ctx, connectionName, opts, func(ctx context.Context, q queryer) error {
+		rows, err := q.QueryContext(ctx, `SELECT owner, name, object_type, num_rows, last_analyzed, partitioned, temporary, comments FROM (
+  SELECT t.owner, t.table_name name, 'TABLE' object_type, t.num_rows, t.last_analyzed, t.partitioned, t.temporary, c.comments
+    FROM all_tables t
+    LEFT JOIN all_tab_comments c ON c.owner = t.owner AND c.table_name = t.table_name
+   WHERE t.owner = NVL(:owner, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA'))
+     AND UPPER(t.table_name) LIKE :pattern
+     AND t.nested = 'NO' AND t.secondary = 'N' AND t.dropped = 'NO'
+  UNION ALL
+  SELECT v.owner, v.view_name, 'VIEW', NULL, NULL, 'NO', 'N', c.comments
+    FROM all_views v
+    LEFT JOIN all_tab_comments c ON c.owner = v.owner AND c.table_name = v.view_name
+   WHERE :views = 1
+     AND v.owner = NVL(:owner, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA'))
+     AND UPPER(v.view_name) LIKE :pattern
+)
+ ORDER BY name
+ FETCH FIRST :lim ROWS ONLY`,
+			sql.Named("owner", identifier(filter.Owner)), sql.Named("pattern", likePattern(filter.Pattern)),
+			sql.Named("views", views), sql.Named("lim", limit+1))
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var t TableInfo
+			var numRows sql.NullInt64
+			var analyzed sql.NullTime
+			var partitioned, temporary, comment sql.NullString
+			if err := rows.Scan(&t.Owner, &t.Name, &t.Type, &numRows, &analyzed, &partitioned, &temporary, &comment); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			if numRows.Valid {
+				t.RowsEstimate = &numRows.Int64
+			}
+			if analyzed.Valid {
+				t.LastAnalyzed = &analyzed.Time
+			}
+			t.Partitioned = partitioned.String == "YES"
+			t.Temporary = temporary.String == "Y"
+			t.Comment = comment.String
+			tables = append(tables, t)
+		}
+		return rows.Err()
+	})
+	if len(tables) > limit {
+		tables, truncated = tables[:limit], true
+	}
+	return tables, truncated, err
+}
+
+// DescribeTable returns the columns, keys, indexes and comments of a table or view. name may be qualified
+// (OWNER.NAME); quoted identifiers keep their case. Synonyms are followed, private ones first and then PUBLIC
+// when no owner is given.
+func (p *ExecutorPool) DescribeTable(ctx context.Context, connectionName string, owner string, name string, opts ExecOptions) (*TableDescription, error) {
+	if owner == "" {
+		owner, name = splitQualifiedName(name)
+	}
+	owner, name = identifier(owner), identifier(name)
+	if name == "" {
+		return nil, fmt.Errorf("table name is required")
+	}
+	var d *TableDescription
+	err := p.introspect(ctx, connectionName, opts, func(ctx context.Context, q queryer) error {
+		var err error
+		if d, err = resolveTable(ctx, q, owner, name); err != nil {
+			return err
+		}
+		if err := describeComment(ctx, q, d); err != nil {
+			return err
+		}
+		if err := describeColumns(ctx, q, d); err != nil {
+			return err
+		}
+		if err := describeConstraints(ctx, q, d); err != nil {
+			return err
+		}
+		return describeIndexes(ctx, q, d)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// SearchObjects finds objects by name across the visible schemas. Objects of the current schema come first and
+// PUBLIC synonyms last; truncated is set when more than the limit matched.
+func (p *ExecutorPool) SearchObjects(ctx context.Context, connectionName string, search ObjectSearch, opts ExecOptions) (objects []ObjectInfo, truncated bool, err error) {
+	if strings.TrimSpace(search.Pattern) == "" {
+		return nil, false, fmt.Errorf("a name pattern is required")
+	}
+	types, err := objectTypes(search.Types)
+	if err != nil {
+		return nil, false, err
+	}
+	limit := introspectionLimit(search.Limit)
+	// The types come from SearchableObjectTypes, so they can be spliced in as literals
+	typeList := "'" + strings.Join(types, "', '") + "'"
+	objects = make([]ObjectInfo, 0)
+	err = p.introspect(ctx, connectionName, opts, func(ctx context.Context, q queryer) error {
+		rows, err := q.QueryContext(ctx, `SELECT o.owner, o.object_name, o.object_type, o.status, o.last_ddl_time,
+       s.table_owner, s.table_name, s.db_link
+  FROM all_objects o
+  LEFT JOIN all_synonyms s ON o.object_type = 'SYNONYM' AND s.owner = o.owner AND s.synonym_name = o.object_name
+ WHERE UPPER(o.object_name) LIKE :pattern
+   AND o.object_type IN (`+typeList+`)
+   AND (:owner IS NULL OR o.owner = :owner)
+ ORDER BY CASE WHEN o.owner = SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') THEN 0 WHEN o.owner = 'PUBLIC' THEN 2 ELSE 1 END,
+       o.object_name, o.owner, o.object_type
+ FETCH FIRST :lim ROWS ONLY`,
+			sql.Named("pattern", likePattern(search.Pattern)), sql.Named("owner", identifier(search.Owner)), sql.Named("lim", limit+1))
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var o ObjectInfo
+			var ddl sql.NullTime
+			var targetOwner, targetName, dbLink sql.NullString
+			if err := rows.Scan(&o.Owner, &o.Name, &o.Type, &o.Status, &ddl, &targetOwner, &targetName, &dbLink); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			if ddl.Valid {
+				o.LastDDLTime = &ddl.Time
+			}
+			if targetName.Valid {
+				o.Target = qualifiedName(targetOwner.String, targetName.String)
+				if dbLink.String != "" {
+					o.Target += "@" + dbLink.String
+				}
+			}
+			objects = append(objects, o)
+		}
+		return rows.Err()
+	})
+	if len(objects) > limit {
+		objects, truncated = objects[:limit], true
+	}
+	return objects, truncated, err
+}
+
+// introspect runs fn on the named connection like Execute: with the query timeout, a session slot, and inside the
+// session's open transaction when there is one.
+func (p *ExecutorPool) introspect(ctx context.Context, connectionName string, opts ExecOptions, fn func(ctx context.Context, q queryer) error) error {
+	return p.run(ctx, connectionName, opts, func(ctx context.Context, name string, ex *Executor, opts ExecOptions) error {
+		var q queryer = ex.db
+		if opts.tx != nil {
+			q = opts.tx.tx
+		}
+		return fn(ctx, q)
+	})
+}
+
+// resolveTable finds the table or view owner.name refers to, following synonyms. owner "" is the current schema,
+// falling back to a PUBLIC synonym.
+func resolveTable(ctx context.Context, q queryer, owner, name string) (*TableDescription, error) {
+	d := &TableDescription{}
+	implicitOwner := owner == ""
+	if implicitOwner {
+		if err := q.QueryRowContext(ctx, `SELECT SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') FROM dual`).Scan(&owner); err != nil {
+			return nil, fmt.Errorf("query failed: %w", err)
+		}
+	}
+	requested := qualifiedName(owner, name)
+	for hop := 0; hop <= maxSynonymHops; hop++ {
+		typ, err := tableObjectType(ctx, q, owner, name)
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case "TABLE", "VIEW", "MATERIALIZED VIEW":
+			d.Owner, d.Name, d.Type = owner, name, typ
+			return d, nil
+		case "SYNONYM":
+			var targetOwner, targetName, dbLink sql.NullString
+			err := q.QueryRowContext(ctx, `SELECT table_owner, table_name, db_link FROM all_synonyms WHERE owner = :owner AND synonym_name = :name`,
+				sql.Named("owner", owner), sql.Named("name", name)).Scan(&targetOwner, &targetName, &dbLink)
+			if err != nil {
+				return nil, fmt.Errorf("query failed: %w", err)
+			}
+			d.ResolvedFrom = append(d.ResolvedFrom, qualifiedName(owner, name))
+			if dbLink.String != "" {
+				return nil, fmt.Errorf("%s is a synonym for the remote object %s@%s; describe it on a connection to that database",
+					requested, qualifiedName(targetOwner.String, targetName.String), dbLink.String)
+			}
+			owner, name = targetOwner.String, targetName.String
+		default:
+			if hop == 0 && implicitOwner {
+				owner = "PUBLIC"
+				continue
+			}
+			if len(d.ResolvedFrom) > 0 {
+				return nil, fmt.Errorf("%s resolves to %s, which is not a table or view visible to the connected user", requested, qualifiedName(owner, name))
+			}
+			return nil, fmt.Errorf("table or view %s not found or not visible to the connected user", requested)
+		}
+	}
+	return nil, fmt.Errorf("%s: too many levels of synonyms (%s)", requested, strings.Join(d.ResolvedFrom, " -> "))
+}
+
+// tableObjectType returns TABLE, VIEW, MATERIALIZED VIEW or SYNONYM for owner.name, or "" when there is no such object.
+func tableObjectType(ctx context.Context, q queryer, owner, name string) (string, error) {
+	rows, err := q.QueryContext(ctx, `SELECT object_type FROM all_objects
+ WHERE owner = :owner AND object_name = :name AND object_type IN ('TABLE', 'VIEW', 'MATERIALIZED VIEW', 'SYNONYM')`,
+		sql.Named("owner", owner), sql.Named("name", name))
+	if err != nil {
+		return "", fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+	found := ""
+	for rows.Next() {
+		var typ string
+		if err := rows.Scan(&typ); err != nil {
+			return "", fmt.Errorf("failed to scan row: %w", err)
+		}
+		// A materialized view is listed as both TABLE and MATERIALIZED VIEW; a table shares no namespace with a synonym
+		if found == "" || typ == "MATERIALIZED VIEW" {
+			found = typ
+		}
+	}
+	return found, rows.Err()
+}
+
+// describeComment fills in the comment and, for tables, the optimizer statistics.
+func describeComment(ctx context.Context, q queryer, d *TableDescription) error {
+	var comment sql.NullString
+	var numRows sql.NullInt64
+	var analyzed sql.NullTime
+	err := q.QueryRowContext(ctx, `SELECT c.comments, t.num_rows, t.last_analyzed
+  FROM all_tab_comments c
+  LEFT JOIN all_tables t ON t.owner = c.owner AND t.table_name = c.table_name
+ WHERE c.owner = :owner AND c.table_name = :name`,
+		sql.Named("owner", d.Owner), sql.Named("name", d.Name)).Scan(&comment, &numRows, &analyzed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("query failed: %w", err)
+	}
+	d.Comment = comment.String
+	if numRows.Valid {
+		d.RowsEstimate = &numRows.Int64
+	}
+	if analyzed.Valid {
+		d.LastAnalyzed = &analyzed.Time
+	}
+	return nil
+}
+
+// describeColumns fills in the columns in table order.
+func describeColumns(ctx context.Context, q queryer, d *TableDescription) error {
+	rows, err := q.QueryContext(ctx, `SELECT c.column_name, c.data_type, c.data_length, c.data_precision, c.data_scale,
+       c.char_length, c.char_used, c.nullable, c.data_default, cc.comments
+  FROM all_tab_columns c
+  LEFT JOIN all_col_comments cc ON cc.owner = c.owner AND cc.table_name = c.table_name AND cc.column_name = c.column_name
+ WHERE c.owner = :owner AND c.table_name = :name
+ ORDER BY c.column_id`, sql.Named("owner", d.Owner), sql.Named("name", d.Name))
+	if err != nil {
+		return fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+	d.Columns = make([]ColumnInfo, 0)
+	for rows.Next() {
+		var c ColumnInfo
+		var dataType, nullable string
+		var length, precision, scale, charLength sql.NullInt64
+		var charUsed, dataDefault, comment sql.NullString
+		if err := rows.Scan(&c.Name, &dataType, &length, &precision, &scale, &charLength, &charUsed, &nullable, &dataDefault, &comment); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		c.Type = columnType(dataType, length, precision, scale, charLength.Int64, charUsed.String)
+		c.Nullable = nullable == "Y"
+		c.Default = strings.TrimSpace(dataDefault.String)
+		c.Comment = comment.String
+		d.Columns = append(d.Columns, c)
+	}
+	return rows.Err()
+}
+
+// describeConstraints fills in the primary key, unique keys and foreign keys.
+func describeConstraints(ctx context.Context, q queryer, d *TableDescription) error {
+	rows, err := q.QueryContext(ctx, `SELECT c.constraint_name, c.constraint_type, c.status, c.delete_rule,
+       r.owner, r.table_name, r.constraint_name, cc.column_name, rc.column_name
+  FROM all_constraints c
+  JOIN all_cons_columns cc ON cc.owner = c.owner AND cc.constraint_name = c.constraint_name
+  LEFT JOIN all_constraints r ON r.owner = c.r_owner AND r.constraint_name = c.r_constraint_name
+  LEFT JOIN all_cons_columns rc ON rc.owner = r.owner AND rc.constraint_name = r.constraint_name AND rc.position = cc.position
+ WHERE c.owner = :owner AND c.table_name = :name AND c.constraint_type IN ('P', 'U', 'R')
+ ORDER BY c.constraint_type, c.constraint_name, cc.position`, sql.Named("owner", d.Owner), sql.Named("name", d.Name))
+	if err != nil {
+		return fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+	var current *Constraint
+	var currentType string
+	flush := func() {
+		if current == nil {
+			return
+		}
+		switch currentType {
+		case "P":
+			d.PrimaryKey = current
+		case "U":
+			d.UniqueKeys = append(d.UniqueKeys, *current)
+		case "R":
+			d.ForeignKeys = append(d.ForeignKeys, *current)
+		}
+	}
+	for rows.Next() {
+		var name, typ, status, column string
+		var deleteRule, refOwner, refTable, refConstraint, refColumn sql.NullString
+		if err := rows.Scan(&name, &typ, &status, &deleteRule, &refOwner, &refTable, &refConstraint, &column, &refColumn); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		if current == nil || current.Name != name {
+			flush()
+			current = &Constraint{Name: name, Disabled: status == "DISABLED"}
+			currentType = typ
+			if typ == "R" {
+				current.References = &ConstraintRef{Owner: refOwner.String, Table: refTable.String, Constraint: refConstraint.String}
+				if deleteRule.String != "" && deleteRule.String != "NO ACTION" {
+					current.OnDelete = deleteRule.String
+				}
+			}
+		}
+		current.Columns = append(current.Columns, column)
+		if current.References != nil && refColumn.Valid {
+			current.References.Columns = append(current.References.Columns, refColumn.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	flush()
+	return nil
+}
+
+// describeIndexes fills in the indexes on the table.
+func describeIndexes(ctx context.Context, q queryer, d *TableDescription) error {
+	rows, err := q.QueryContext(ctx, `SELECT i.owner, i.index_name, i.index_type, i.uniqueness, i.status, ic.column_name, ic.descend
+  FROM all_indexes i
+  JOIN all_ind_columns ic ON ic.index_owner = i.owner AND ic.index_name = i.index_name
+ WHERE i.table_owner = :owner AND i.table_name = :name
+ ORDER BY i.index_name, ic.column_position`, sql.Named("owner", d.Owner), sql.Named("name", d.Name))
+	if err != nil {
+		return fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var owner, name, typ, uniqueness, status, column, descend string
+		if err := rows.Scan(&owner, &name, &typ, &uniqueness, &status, &column, &descend); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		n := len(d.Indexes)
+		if n == 0 || d.Indexes[n-1].Owner != owner || d.Indexes[n-1].Name != name {
+			d.Indexes = append(d.Indexes, IndexInfo{Owner: owner, Name: name, Type: typ, Unique: uniqueness == "UNIQUE", Status: status})
+			n++
+		}
+		if descend == "DESC" {
+			column += " DESC"
+		}
+		d.Indexes[n-1].Columns = append(d.Indexes[n-1].Columns, column)
+	}
+	return rows.Err()
+}
+
+// columnType formats a column's type as it would appear in DDL.
+func columnType(dataType string, length, precision, scale sql.NullInt64, charLength int64, charUsed string) string {
+	switch dataType {
+	case "VARCHAR2", "CHAR":
+		if charUsed == "C" {
+			return fmt.Sprintf("%s(%d CHAR)", dataType, charLength)
+		}
+		return fmt.Sprintf("%s(%d BYTE)", dataType, length.Int64)
+	case "NVARCHAR2", "NCHAR":
+		return fmt.Sprintf("%s(%d)", dataType, charLength)
+	case "RAW", "UROWID":
+		return fmt.Sprintf("%s(%d)", dataType, length.Int64)
+	case "FLOAT":
+		if precision.Valid {
+			return fmt.Sprintf("FLOAT(%d)", precision.Int64)
+		}
+	case "NUMBER":
+		switch {
+		case !precision.Valid && scale.Valid && scale.Int64 == 0:
+			return "INTEGER"
+		case !precision.Valid:
+			return "NUMBER"
+		case !scale.Valid || scale.Int64 == 0:
+			return fmt.Sprintf("NUMBER(%d)", precision.Int64)
+		default:
+			return fmt.Sprintf("NUMBER(%d,%d)", precision.Int64, scale.Int64)
+		}
+	}
+	return dataType
+}
+
+// identifier normalizes an Oracle identifier for dictionary lookups: "Quoted" names keep their case, others are
+// upper-cased.
+func identifier(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		return s[1 : len(s)-1]
+	}
+	return strings.ToUpper(s)
+}
+
+// splitQualifiedName splits OWNER.NAME at the first dot outside double quotes; owner is "" for an unqualified name.
+func splitQualifiedName(s string) (owner, name string) {
+	quoted := false
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '.' && !quoted:
+			return s[:i], s[i+1:]
+		}
+	}
+	return "", s
+}
+
+// qualifiedName joins owner and name for messages and results.
+func qualifiedName(owner, name string) string {
+	if owner == "" {
+		return name
+	}
+	return owner + "." + name
+}
+
+// likePattern turns a user's name pattern into the LIKE pattern matched against UPPER(name):
+// "" matches everything and a pattern without % matches anywhere in the name.
+func likePattern(pattern string) string {
+	p := strings.ToUpper(strings.TrimSpace(pattern))
+	if !strings.Contains(p, "%") {
+		p = "%" + p + "%"
+	}
+	return p
+}
+
+// introspectionLimit applies DefaultIntrospectionLimit and MaxIntrospectionLimit.
+func introspectionLimit(n int) int {
+	if n <= 0 {
+		return DefaultIntrospectionLimit
+	}
+	if n > MaxIntrospectionLimit {
+		return MaxIntrospectionLimit
+	}
+	return n
+}
+
+// objectTypes validates and normalizes the object types for SearchObjects.
+func objectTypes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return DefaultSearchObjectTypes, nil
+	}
+	out := make([]string, 0, len(requested))
+	for _, r := range requested {
+		typ := strings.ToUpper(strings.Join(strings.Fields(r), " "))
+		valid := false
+		for _, s := range SearchableObjectTypes {
+			if typ == s {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unsupported object type %q (supported: %s)", r, strings.Join(SearchableObjectTypes, ", "))
+		}
+		out = append(out, typ)
+	}
+	return out, nil
+}
@@ -0,0 +1,74 @@
+package oracle
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestColumnType(t *testing.T) {
+	n := func(v int64) sql.NullInt64 { return sql.NullInt64{Int64: v, Valid: true} }
+	null := sql.NullInt64{}
+	tests := []struct {
+		dataType                 string
+		length, precision, scale sql.NullInt64
+		charLength               int64
+		charUsed                 string
+		want                     string
+	}{
+		{"VARCHAR2", n(120), null, null, 30, "C", "VARCHAR2(30 CHAR)"},
+		{"VARCHAR2", n(30), null, null, 30, "B", "VARCHAR2(30 BYTE)"},
+		{"NVARCHAR2", n(100), null, null, 50, "C", "NVARCHAR2(50)"},
+		{"NUMBER", n(22), null, null, 0, "", "NUMBER"},
+		{"NUMBER", n(22), null, n(0), 0, "", "INTEGER"},
+		{"NUMBER", n(22), n(10), n(0), 0, "", "NUMBER(10)"},
+		{"NUMBER", n(22), n(12), n(2), 0, "", "NUMBER(12,2)"},
+		{"FLOAT", n(22), n(126), null, 0, "", "FLOAT(126)"},
+		{"RAW", n(16), null, null, 0, "", "RAW(16)"},
+		{"TIMESTAMP(6)", n(11), null, n(6), 0, "", "TIMESTAMP(6)"},
+		{"DATE", n(7), null, null, 0, "", "DATE"},
+	}
+	for _, tt := range tests {
+		if got := columnType(tt.dataType, tt.length, tt.precision, tt.scale, tt.charLength, tt.charUsed); got != tt.want {
+			t.Errorf("columnType(%s, ...) = %q, want %q", tt.dataType, got, tt.want)
+		}
+	}
+}
+
+func TestIdentifiersAndPatterns(t *testing.T) {
+	for in, want := range map[string]string{"emp": "EMP", ` hr `: "HR", `"MixedCase"`: "MixedCase", "": ""} {
+		if got := identifier(in); got != want {
+			t.Errorf("identifier(%q) = %q, want %q", in, got, want)
+		}
+	}
+	for in, want := range map[string][2]string{
+		"hr.employees":     {"hr", "employees"},
+		"employees":        {"", "employees"},
+		`"a.b".c`:          {`"a.b"`, "c"},
+		`"odd.name"`:       {"", `"odd.name"`},
+		`scott."Tab.With"`: {"scott", `"Tab.With"`},
+	} {
+		owner, name := splitQualifiedName(in)
+		if owner != want[0] || name != want[1] {
+			t.Errorf("splitQualifiedName(%q) = %q, %q, want %q, %q", in, owner, name, want[0], want[1])
+		}
+	}
+	for in, want := range map[string]string{"": "%%", "emp": "%EMP%", "EMP%": "EMP%", "%_hist": "%_HIST"} {
+		if got := likePattern(in); got != want {
+			t.Errorf("likePattern(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestObjectTypes(t *testing.T) {
+	got, err := objectTypes([]string{"table", " materialized   view", "Package"})
+	if err != nil || !reflect.DeepEqual(got, []string{"TABLE", "MATERIALIZED VIEW", "PACKAGE"}) {
+		t.Errorf("objectTypes = %q, %v", got, err)
+	}
+	if got, _ := objectTypes(nil); !reflect.DeepEqual(got, DefaultSearchObjectTypes) {
+		t.Errorf("objectTypes(nil) = %q, want the defaults", got)
+	}
+	if _, err := objectTypes([]string{"TABLE", "DATABASE LINK"}); err == nil {
+		t.Error("objectTypes accepted DATABASE LINK")
+	}
+}
@@ -0,0 +1,134 @@
+// Package oracle: per-statement results of multi-statement scripts.
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alvin/oracle-mcp-server/internal/sqlanalyzer"
+)
+
+// Script statuses (ScriptStatus.Status).
+const (
+	ScriptCompleted           = "completed"             // every statement succeeded
+	ScriptCompletedWithErrors = "completed_with_errors" // ContinueOnError: some statements failed, all were run
+	ScriptStopped             = "stopped"               // a statement failed and the rest were skipped
+)
+
+// maxStatementExcerpt is the length of the statement text kept in a StatementResult.
+const maxStatementExcerpt = 200
+
+// StatementResult is the outcome of one statement of a multi-statement script.
+type StatementResult struct {
+	Index         int    `json:"index"` // position in the script, from 1
+	Line          int    `json:"line"`  // script line the statement starts on
+	SQL           string `json:"sql"`   // the statement, shortened to maxStatementExcerpt characters
+	StatementType string `json:"statement_type"`
+	Success       bool   `json:"success"`
+	Error         string `json:"error,omitempty"`
+
+	Columns       []string        `json:"columns,omitempty"`
+	Rows          [][]interface{} `json:"rows,omitempty"`
+	Truncated     bool            `json:"truncated,omitempty"` // the last statement's cursor is kept as the result's result_handle
+	RowsFetched   int64           `json:"rows_fetched,omitempty"`
+	RowsAffected  int64           `json:"rows_affected,omitempty"`
+	ExecutionTime int64           `json:"execution_time_ms"`
+
+	DBMSOutput          []string       `json:"dbms_output,omitempty"`
+	DBMSOutputTruncated bool           `json:"dbms_output_truncated,omitempty"`
+	CompileErrors       []CompileError `json:"compile_errors,omitempty"`
+}
+
+// ScriptStatus summarizes a multi-statement script. Skipped counts the statements not run after a failure.
+type ScriptStatus struct {
+	Status    string `json:"status"`
+	Total     int    `json:"total"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+	Skipped   int    `json:"skipped,omitempty"`
+}
+
+// executeScript runs the statements of a script one by one, recording each in result.Statements. A failed statement
+// stops the script unless opts.ContinueOnError is set, like SQL*Plus WHENEVER SQLERROR EXIT / CONTINUE; either way
+// the failure is reported in the result, not as an error. Cancellation, timeouts and lost connections still end the
+// call with an error.
+func (e *Executor) executeScript(ctx context.Context, q queryer, pieces []string, lines []int, result *ExecutionResult, opts ExecOptions) error {
+	status := &ScriptStatus{Status: ScriptCompleted, Total: len(pieces)}
+	result.Script = status
+	for i, st := range pieces {
+		part := &ExecutionResult{}
+		keep := opts.keepCursor && i == len(pieces)-1
+		start := time.Now()
+		err := e.executePiece(ctx, q, st, lines[i], part, opts, keep)
+		if err != nil && (ctx.Err() != nil || isConnectionError(err)) {
+			return err
+		}
+		sr := StatementResult{
+			Index:               i + 1,
+			Line:                lines[i],
+			SQL:                 excerpt(st),
+			StatementType:       sqlanalyzer.GetStatementType(st),
+			Success:             err == nil,
+			Columns:             part.Columns,
+			Rows:                part.Rows,
+			Truncated:           part.Truncated,
+			RowsFetched:         part.RowsFetched,
+			RowsAffected:        part.RowsAffected,
+			ExecutionTime:       time.Since(start).Milliseconds(),
+			DBMSOutput:          part.DBMSOutput,
+			DBMSOutputTruncated: part.DBMSOutputTruncated,
+			CompileErrors:       part.CompileErrors,
+		}
+		result.RowsAffected += part.RowsAffected
+		result.cursor = part.cursor
+		if err != nil {
+			sr.Error = err.Error()
+			status.Failed++
+		} else {
+			status.Succeeded++
+		}
+		result.Statements = append(result.Statements, sr)
+		if err != nil && !opts.ContinueOnError {
+			status.Status = ScriptStopped
+			status.Skipped = len(pieces) - i - 1
+			return nil
+		}
+	}
+	if status.Failed > 0 {
+		status.Status = ScriptCompletedWithErrors
+	}
+	if last := result.Statements[len(result.Statements)-1]; last.Truncated {
+		result.Truncated, result.RowsFetched = true, last.RowsFetched
+	}
+	return nil
+}
+
+// ScriptError describes the failed statements of a script result for the audit log, or returns "" when every
+// statement succeeded (or the SQL was a single statement).
+func (r *ExecutionResult) ScriptError() string {
+	if r.Script == nil || r.Script.Failed == 0 {
+		return ""
+	}
+	var first *StatementResult
+	for i := range r.Statements {
+		if !r.Statements[i].Success {
+			first = &r.Statements[i]
+			break
+		}
+	}
+	if r.Script.Status == ScriptStopped {
+		return fmt.Sprintf("statement %d of %d failed, %d skipped: %s", first.Index, r.Script.Total, r.Script.Skipped, first.Error)
+	}
+	return fmt.Sprintf("%d of %d statements failed; first: statement %d: %s", r.Script.Failed, r.Script.Total, first.Index, first.Error)
+}
+
+// excerpt returns the statement on one line, shortened to maxStatementExcerpt characters.
+func excerpt(st string) string {
+	s := strings.Join(strings.Fields(st), " ")
+	if r := []rune(s); len(r) > maxStatementExcerpt {
+		return string(r[:maxStatementExcerpt-3]) + "..."
+	}
+	return s
+}
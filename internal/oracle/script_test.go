@@ -0,0 +1,83 @@
+package oracle
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScriptError(t *testing.T) {
+	stopped := &ExecutionResult{
+		Script: &ScriptStatus{Status: ScriptStopped, Total: 4, Succeeded: 1, Failed: 1, Skipped: 2},
+		Statements: []StatementResult{
+			{Index: 1, Success: true},
+			{Index: 2, Error: "ORA-00942: table or view does not exist"},
+		},
+	}
+	if got, want := stopped.ScriptError(), "statement 2 of 4 failed, 2 skipped: ORA-00942: table or view does not exist"; got != want {
+		t.Errorf("ScriptError = %q, want %q", got, want)
+	}
+	continued := &ExecutionResult{
+		Script:     &ScriptStatus{Status: ScriptCompletedWithErrors, Total: 3, Succeeded: 1, Failed: 2},
+		Statements: []StatementResult{{Index: 1, Error: "ORA-00001"}, {Index: 2, Success: true}, {Index: 3, Error: "ORA-01400"}},
+	}
+	if got, want := continued.ScriptError(), "2 of 3 statements failed; first: statement 1: ORA-00001"; got != want {
+		t.Errorf("ScriptError = %q, want %q", got, want)
+	}
+	for _, ok := range []*ExecutionResult{{}, {Script: &ScriptStatus{Status: ScriptCompleted, Total: 2, Succeeded: 2}}} {
+		if got := ok.ScriptError(); got != "" {
+			t.Errorf("ScriptError = %q, want empty", got)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	if got := excerpt("INSERT INTO t\n  VALUES (1,\n\t2)"); got != "INSERT INTO t VALUES (1, 2)" {
+		t.Errorf("excerpt = %q", got)
+	}
+	long := "SELECT " + strings.Repeat("é", 300)
+	if got := excerpt(long); len([]rune(got)) != maxStatementExcerpt || !strings.HasSuffix(got, "...") {
+		t.Errorf("excerpt of a long statement = %d runes", len([]rune(got)))
+	}
+}
+
+func TestWriteFiles_Script(t *testing.T) {
+	script := &ExecutionResult{
+		Success:      true,
+		RowsAffected: 3,
+		Script:       &ScriptStatus{Status: ScriptCompleted, Total: 3, Succeeded: 3},
+		Statements: []StatementResult{
+			{Index: 1, Success: true, Columns: []string{"X"}, Rows: [][]interface{}{{1}}},
+			{Index: 2, Success: true, Columns: []string{"ID", "NAME"}, Rows: [][]interface{}{{1, "a,b"}, {2, nil}}},
+			{Index: 3, Success: true, RowsAffected: 3},
+		},
+	}
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "out.csv")
+	if n, err := writeCSVFile(csvPath, script); err != nil || n != 2 {
+		t.Fatalf("writeCSVFile = %d, %v", n, err)
+	}
+	if got, _ := os.ReadFile(csvPath); string(got) != "ID,NAME\n1,\"a,b\"\n2,\n" {
+		t.Errorf("CSV = %q", got)
+	}
+	txtPath := filepath.Join(dir, "out.txt")
+	if n, err := writeTextFile(txtPath, script); err != nil || n != 2 {
+		t.Fatalf("writeTextFile = %d, %v", n, err)
+	}
+	if got, _ := os.ReadFile(txtPath); string(got) != "1\ta,b2\t" {
+		t.Errorf("text = %q", got)
+	}
+
+	failed := &ExecutionResult{
+		Script:     &ScriptStatus{Status: ScriptStopped, Total: 2, Succeeded: 1, Failed: 1},
+		Statements: []StatementResult{{Index: 1, Success: true, Columns: []string{"X"}}, {Index: 2, Error: "ORA-00942"}},
+	}
+	_, err := writeCSVFile(filepath.Join(dir, "failed.csv"), failed)
+	if err == nil || !strings.Contains(err.Error(), "statement 2 of 2 failed") {
+		t.Errorf("writeCSVFile of a failed script = %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, "failed.csv")); !os.IsNotExist(statErr) {
+		t.Errorf("failed script created the file")
+	}
+}
@@ -0,0 +1,57 @@
+// Package oracle: query timeouts, per connection and per call.
+package oracle
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeoutError is returned when a statement ran past its query timeout and godror broke the Oracle call.
+type TimeoutError struct {
+	Connection string
+	Timeout    time.Duration
+	Err        error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("query on %q exceeded the %s timeout and was cancelled: %v", e.Connection, e.Timeout, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// SetQueryTimeouts sets the default query timeout and per-connection overrides (name -> timeout).
+// A timeout <= 0 means no limit.
+func (p *ExecutorPool) SetQueryTimeouts(def time.Duration, perConnection map[string]time.Duration) {
+	timeouts := make(map[string]time.Duration, len(perConnection))
+	for name, d := range perConnection {
+		timeouts[name] = d
+	}
+	p.mu.Lock()
+	p.queryTimeout = def
+	p.queryTimeouts = timeouts
+	p.mu.Unlock()
+}
+
+// QueryTimeout returns the timeout a call on the named connection runs with: requested when > 0, otherwise the
+// connection's configured timeout (0 = none). A requested timeout may shorten the configured one but not exceed it.
+func (p *ExecutorPool) QueryTimeout(connectionName string, requested time.Duration) (time.Duration, error) {
+	name, err := p.resolveName(connectionName)
+	if err != nil {
+		// Reported by the call itself
+		return requested, nil
+	}
+	p.mu.RLock()
+	limit, ok := p.queryTimeouts[name]
+	if !ok {
+		limit = p.queryTimeout
+	}
+	p.mu.RUnlock()
+	if requested <= 0 {
+		return limit, nil
+	}
+	if limit > 0 && requested > limit {
+		return 0, fmt.Errorf("timeout_seconds %d exceeds the %d second query timeout configured for %q",
+			int(requested/time.Second), int(limit/time.Second), name)
+	}
+	return requested, nil
+}
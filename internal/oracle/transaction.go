@@ -0,0 +1,356 @@
+// Package oracle: explicit transactions (begin_transaction / commit / rollback / savepoint) pinned to a dedicated connection.
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTransactionIdleTimeout is how long an open transaction may sit unused before it is rolled back.
+const DefaultTransactionIdleTimeout = 5 * time.Minute
+
+// queryer is what Executor runs statements on: the pooled *sql.DB (autocommit) or an open *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// savepointName accepts unquoted Oracle identifiers, so names can be spliced into SAVEPOINT statements safely.
+var savepointName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_$#]{0,127}$`)
+
+// txKey identifies an open transaction: the client session that began it ("" for stdio) and the connection name.
+// Each session has its own transactions, so one client's statements never run in another's transaction.
+type txKey struct {
+	session    string
+	connection string
+}
+
+// transaction is an open transaction on one *sql.Conn taken out of the connection's pool.
+// mu serializes statements, commit/rollback and the idle timer; closed is set once the transaction has ended.
+type transaction struct {
+	key        txKey
+	slot       *poolSlot // the pool slot of conn, held until the transaction ends
+	conn       *sql.Conn
+	tx         *sql.Tx
+	started    time.Time
+	lastUsed   time.Time
+	savepoints []string
+	idle       *time.Timer
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// TransactionInfo describes an open transaction for tool results and the review dialog.
+type TransactionInfo struct {
+	Connection string    `json:"connection"`
+	Started    time.Time `json:"started"`
+	Savepoints []string  `json:"savepoints,omitempty"`
+}
+
+// info returns a snapshot of t; the caller holds t.mu.
+func (t *transaction) info(name string) TransactionInfo {
+	sp := make([]string, len(t.savepoints))
+	copy(sp, t.savepoints)
+	return TransactionInfo{Connection: name, Started: t.started, Savepoints: sp}
+}
+
+// end commits or rolls back t and returns its connection to the pool; the caller holds t.mu and has closed
+// t's cursors (database/sql waits for open rows before it ends a transaction).
+func (t *transaction) end(commit bool) error {
+	t.closed = true
+	t.idle.Stop()
+	var err error
+	if commit {
+		err = t.tx.Commit()
+	} else {
+		err = t.tx.Rollback()
+	}
+	t.conn.Close()
+	t.slot.release()
+	return err
+}
+
+// SetTransactionIdleTimeout sets how long a transaction may stay unused before it is rolled back automatically.
+// d <= 0 uses DefaultTransactionIdleTimeout. Applies to transactions begun afterwards.
+func (p *ExecutorPool) SetTransactionIdleTimeout(d time.Duration) {
+	if d <= 0 {
+		d = DefaultTransactionIdleTimeout
+	}
+	p.mu.Lock()
+	p.txIdleTimeout = d
+	p.mu.Unlock()
+}
+
+// BeginTransaction opens a transaction on the named connection for session. Until Commit or Rollback, Execute and
+// the query-to-file helpers called with that session (ExecOptions.Session) run on the transaction's dedicated
+// connection instead of the autocommit pool.
+func (p *ExecutorPool) BeginTransaction(ctx context.Context, session string, connectionName string) (TransactionInfo, error) {
+	name, ex, err := p.executorByName(connectionName)
+	if err != nil {
+		return TransactionInfo{}, err
+	}
+	key := txKey{session, name}
+	p.mu.RLock()
+	_, open := p.txs[key]
+	timeout := p.txIdleTimeout
+	p.mu.RUnlock()
+	if open {
+		return TransactionInfo{}, fmt.Errorf("a transaction is already open on %q; commit or rollback first", name)
+	}
+
+	// The transaction's session counts against the connection's slots for as long as it is open
+	slot, err := p.acquireSlot(ctx, name)
+	if err != nil {
+		return TransactionInfo{}, err
+	}
+	conn, err := ex.db.Conn(ctx)
+	if err != nil {
+		slot.release()
+		if isConnectionError(err) {
+			p.markConnectionFailed(name, ex)
+		}
+		return TransactionInfo{}, fmt.Errorf("failed to reserve a connection: %w", err)
+	}
+	// The transaction outlives this call, so it must not be tied to ctx (database/sql rolls back when ctx ends).
+	// On a read-only connection it is a read-only transaction.
+	tx, err := conn.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: p.ReadOnly(name)})
+	if err != nil {
+		conn.Close()
+		slot.release()
+		return TransactionInfo{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	now := time.Now()
+	t := &transaction{key: key, slot: slot, conn: conn, tx: tx, started: now, lastUsed: now}
+	t.idle = time.AfterFunc(timeout, func() { p.rollbackIdle(t, timeout) })
+
+	p.mu.Lock()
+	if _, open := p.txs[key]; open || p.txs == nil {
+		p.mu.Unlock()
+		t.end(false)
+		return TransactionInfo{}, fmt.Errorf("a transaction is already open on %q; commit or rollback first", name)
+	}
+	p.txs[key] = t
+	p.mu.Unlock()
+	return t.info(name), nil
+}
+
+// Commit commits session's open transaction on the named connection and releases its connection.
+func (p *ExecutorPool) Commit(session string, connectionName string) (TransactionInfo, error) {
+	return p.finishTransaction(session, connectionName, true)
+}
+
+// Rollback rolls back session's open transaction on the named connection. With a savepoint, only the work after
+// that savepoint is undone and the transaction stays open; otherwise the transaction ends.
+func (p *ExecutorPool) Rollback(ctx context.Context, session string, connectionName string, savepoint string) (TransactionInfo, error) {
+	if savepoint == "" {
+		return p.finishTransaction(session, connectionName, false)
+	}
+	name, t, err := p.acquireTransaction(session, connectionName)
+	if err != nil {
+		return TransactionInfo{}, err
+	}
+	defer p.releaseTransaction(t)
+
+	sp := strings.ToUpper(savepoint)
+	idx := -1
+	for i, s := range t.savepoints {
+		if s == sp {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return t.info(name), fmt.Errorf("unknown savepoint %q on %q (savepoints: %s)", savepoint, name, strings.Join(t.savepoints, ", "))
+	}
+	if _, err := t.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+sp); err != nil {
+		return t.info(name), fmt.Errorf("rollback to savepoint failed: %w", err)
+	}
+	// Oracle keeps the target savepoint and erases the ones set after it
+	t.savepoints = t.savepoints[:idx+1]
+	return t.info(name), nil
+}
+
+// Savepoint sets a savepoint in session's open transaction on the named connection.
+// Setting an existing name again moves it to the current point, as in Oracle.
+func (p *ExecutorPool) Savepoint(ctx context.Context, session string, connectionName string, savepoint string) (TransactionInfo, error) {
+	if !savepointName.MatchString(savepoint) {
+		return TransactionInfo{}, fmt.Errorf("invalid savepoint name %q (letters, digits, _, $ and #, starting with a letter)", savepoint)
+	}
+	name, t, err := p.acquireTransaction(session, connectionName)
+	if err != nil {
+		return TransactionInfo{}, err
+	}
+	defer p.releaseTransaction(t)
+
+	sp := strings.ToUpper(savepoint)
+	if _, err := t.tx.ExecContext(ctx, "SAVEPOINT "+sp); err != nil {
+		return t.info(name), fmt.Errorf("savepoint failed: %w", err)
+	}
+	kept := t.savepoints[:0]
+	for _, s := range t.savepoints {
+		if s != sp {
+			kept = append(kept, s)
+		}
+	}
+	t.savepoints = append(kept, sp)
+	return t.info(name), nil
+}
+
+// Transaction reports session's open transaction on the named connection, if any.
+func (p *ExecutorPool) Transaction(session string, connectionName string) (TransactionInfo, bool) {
+	name, err := p.resolveName(connectionName)
+	if err != nil {
+		return TransactionInfo{}, false
+	}
+	p.mu.RLock()
+	t := p.txs[txKey{session, name}]
+	p.mu.RUnlock()
+	if t == nil {
+		return TransactionInfo{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return TransactionInfo{}, false
+	}
+	return t.info(name), true
+}
+
+// finishTransaction commits or rolls back session's open transaction on the named connection.
+func (p *ExecutorPool) finishTransaction(session string, connectionName string, commit bool) (TransactionInfo, error) {
+	name, t, err := p.acquireTransaction(session, connectionName)
+	if err != nil {
+		return TransactionInfo{}, err
+	}
+	defer t.mu.Unlock()
+	info := t.info(name)
+	p.forgetTransaction(t)
+	p.closeTransactionCursors(t)
+	if err := t.end(commit); err != nil {
+		if commit {
+			return info, fmt.Errorf("commit failed: %w", err)
+		}
+		return info, fmt.Errorf("rollback failed: %w", err)
+	}
+	return info, nil
+}
+
+// acquireTransaction returns session's open transaction on the named connection, locked and with its idle timer
+// stopped. Release it with releaseTransaction.
+func (p *ExecutorPool) acquireTransaction(session string, connectionName string) (string, *transaction, error) {
+	name, err := p.resolveName(connectionName)
+	if err != nil {
+		return "", nil, err
+	}
+	t, err := p.lockTransaction(txKey{session, name})
+	if err != nil {
+		return "", nil, err
+	}
+	if t == nil {
+		return "", nil, fmt.Errorf("no transaction is open on %q; call begin_transaction first", name)
+	}
+	return name, t, nil
+}
+
+// lockTransaction returns the open transaction for key (nil when none), locked and with its idle timer stopped.
+func (p *ExecutorPool) lockTransaction(key txKey) (*transaction, error) {
+	p.mu.RLock()
+	t := p.txs[key]
+	p.mu.RUnlock()
+	if t == nil {
+		return nil, nil
+	}
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("the transaction on %q ended (idle timeout, commit or rollback) while this call was waiting; begin a new transaction", key.connection)
+	}
+	t.idle.Stop()
+	return t, nil
+}
+
+// releaseTransaction marks t as used now, restarts its idle timer and unlocks it.
+func (p *ExecutorPool) releaseTransaction(t *transaction) {
+	if !t.closed {
+		t.lastUsed = time.Now()
+		p.mu.RLock()
+		timeout := p.txIdleTimeout
+		p.mu.RUnlock()
+		t.idle.Reset(timeout)
+	}
+	t.mu.Unlock()
+}
+
+// rollbackIdle is the idle timer callback: it rolls back t unless it was used again in the meantime.
+func (p *ExecutorPool) rollbackIdle(t *transaction, timeout time.Duration) {
+	name := t.key.connection
+	t.mu.Lock()
+	if t.closed || time.Since(t.lastUsed) < timeout {
+		t.mu.Unlock()
+		return
+	}
+	p.forgetTransaction(t)
+	p.mu.RLock()
+	onIdle := p.OnIdleRollback
+	p.mu.RUnlock()
+	p.closeTransactionCursors(t)
+	if err := t.end(false); err != nil {
+		log.Printf("oracle-mcp: idle transaction on %q: rollback failed: %v", name, err)
+	}
+	t.mu.Unlock()
+	log.Printf("oracle-mcp: transaction on %q rolled back after %s idle", name, timeout)
+	if onIdle != nil {
+		onIdle(t.key.session, name)
+	}
+}
+
+// dropTransaction rolls back and forgets t (e.g. when its connection failed); the caller holds t.mu.
+func (p *ExecutorPool) dropTransaction(t *transaction) {
+	p.forgetTransaction(t)
+	p.closeTransactionCursors(t)
+	t.end(false)
+}
+
+// EndSession closes the result handles and rolls back the transactions a client session left open; called when
+// the session closes.
+func (p *ExecutorPool) EndSession(session string) {
+	p.closeCursors(func(c *cursor) bool { return c.session == session })
+	p.mu.RLock()
+	var open []*transaction
+	for key, t := range p.txs {
+		if key.session == session {
+			open = append(open, t)
+		}
+	}
+	p.mu.RUnlock()
+	for _, t := range open {
+		t.mu.Lock()
+		if !t.closed {
+			p.dropTransaction(t)
+			log.Printf("oracle-mcp: open transaction on %q rolled back when its session ended", t.key.connection)
+		}
+		t.mu.Unlock()
+	}
+}
+
+// forgetTransaction removes t from the open transactions.
+func (p *ExecutorPool) forgetTransaction(t *transaction) {
+	p.mu.Lock()
+	if p.txs[t.key] == t {
+		delete(p.txs, t.key)
+	}
+	p.mu.Unlock()
+}
+
+// closeTransactionCursors closes the result handles opened inside t.
+func (p *ExecutorPool) closeTransactionCursors(t *transaction) {
+	p.closeCursors(func(c *cursor) bool { return c.tx == t })
+}
@@ -0,0 +1,230 @@
+// Package policy decides whether SQL may run: without review, after review in the confirm dialog, or not at all.
+// Rules from security.rules are checked for each statement in order and the first one that matches decides;
+// danger_keywords, require_confirm_for_ddl and confirm_unbounded_dml follow them as built-in confirm rules, so
+// configurations without rules behave as before (apart from the review of unbounded UPDATE/DELETE/MERGE).
+package policy
+
+import (
+	"path"
+	"strings"
+
+	"github.com/alvin/oracle-mcp-server/internal/config"
+	"github.com/alvin/oracle-mcp-server/internal/sqlanalyzer"
+)
+
+// Action is what a rule does with a statement it matches.
+type Action string
+
+// Actions, from least to most restrictive.
+const (
+	Allow   Action = "allow"
+	Confirm Action = "confirm"
+	Deny    Action = "deny"
+)
+
+// Names of the built-in rules that stand for the legacy settings.
+const (
+	RuleDangerKeywords = "danger_keywords"
+	RuleConfirmDDL     = "require_confirm_for_ddl"
+	RuleUnboundedDML   = "confirm_unbounded_dml"
+)
+
+// rank orders the actions by restrictiveness.
+var rank = map[Action]int{Allow: 0, Confirm: 1, Deny: 2}
+
+// statementModifiers are skipped after the first keyword when matching statement types, so that "CREATE VIEW"
+// also matches CREATE OR REPLACE FORCE VIEW and "DROP SYNONYM" matches DROP PUBLIC SYNONYM.
+var statementModifiers = map[string]bool{
+	"or": true, "replace": true, "editionable": true, "noneditionable": true, "editioning": true, "force": true,
+	"noforce": true, "unique": true, "bitmap": true, "global": true, "private": true, "temporary": true, "public": true,
+}
+
+// Decision is the outcome of Evaluate for a whole SQL text: the most restrictive decision among its statements
+// (the first one on a tie).
+type Decision struct {
+	Action Action
+	// Rule names the rule that decided; "" when no rule matched and the SQL is allowed.
+	Rule string
+	// Builtin is set when Rule is RuleDangerKeywords, RuleConfirmDDL or RuleUnboundedDML rather than a configured rule.
+	Builtin bool
+	// Message is the deny message of the rule.
+	Message string
+	// Statement is the position (from 1) of the statement the decision is about; 0 when no rule matched.
+	Statement int
+}
+
+// rule is a configured rule prepared for matching.
+type rule struct {
+	config.PolicyRule
+	types  [][]string            // statement types, lower-cased words
+	tokens *sqlanalyzer.Analyzer // Tokens in tokens mode; nil when the rule has none
+}
+
+// Engine evaluates SQL against the rules. It is safe for concurrent use.
+type Engine struct {
+	rules            []rule
+	keywords         *sqlanalyzer.Analyzer
+	confirmDDL       bool
+	confirmUnbounded bool
+	// defaultSchema returns the schema of unqualified names on a connection ("" when unknown).
+	defaultSchema func(connection string) string
+}
+
+// New returns an engine for the security settings. defaultSchema gives the schema unqualified names resolve to on
+// a connection (e.g. oracle.ExecutorPool.DefaultSchema); it may be nil.
+func New(sec config.SecurityConfig, defaultSchema func(connection string) string) *Engine {
+	e := &Engine{
+		keywords:         sqlanalyzer.NewAnalyzer(sec.DangerKeywords, sec.DangerKeywordMatch),
+		confirmDDL:       sec.RequireConfirmForDDL,
+		confirmUnbounded: sec.ConfirmUnboundedDML,
+		defaultSchema:    defaultSchema,
+	}
+	for _, pr := range sec.Rules {
+		r := rule{PolicyRule: pr}
+		for _, st := range pr.StatementTypes {
+			if words := strings.Fields(strings.ToLower(st)); len(words) > 0 {
+				r.types = append(r.types, words)
+			}
+		}
+		if len(pr.Tokens) > 0 {
+			r.tokens = sqlanalyzer.NewAnalyzer(pr.Tokens, "tokens")
+		}
+		e.rules = append(e.rules, r)
+	}
+	return e
+}
+
+// Evaluate decides on sql run on the named connection, checking the configured rules and then the built-in
+// danger_keywords, require_confirm_for_ddl and confirm_unbounded_dml rules for each statement.
+func (e *Engine) Evaluate(sql, connection string) Decision {
+	return e.evaluate(sql, connection, true)
+}
+
+// EvaluateRules is Evaluate without the built-in rules, for tools that never asked for review (the query-to-file
+// exports): only configured rules can make them confirm or deny.
+func (e *Engine) EvaluateRules(sql, connection string) Decision {
+	return e.evaluate(sql, connection, false)
+}
+
+func (e *Engine) evaluate(sql, connection string, builtins bool) Decision {
+	decision := Decision{Action: Allow}
+	for i, st := range sqlanalyzer.SplitStatements(sql) {
+		d := e.statement(st.Text, connection, builtins)
+		if rank[d.Action] > rank[decision.Action] || (decision.Rule == "" && d.Rule != "") {
+			d.Statement = i + 1
+			decision = d
+		}
+	}
+	return decision
+}
+
+// statement decides on one statement: the first matching configured rule, else the built-in rules, else allow.
+func (e *Engine) statement(text, connection string, builtins bool) Decision {
+	var objects []sqlanalyzer.ObjectRef
+	objectsRead := false
+	for _, r := range e.rules {
+		if (len(r.Objects) > 0 || len(r.Schemas) > 0) && !objectsRead {
+			objects, objectsRead = sqlanalyzer.StatementObjects(text), true
+		}
+		if e.matches(r, text, connection, objects) {
+			return Decision{Action: Action(r.Action), Rule: r.Name, Message: r.Message}
+		}
+	}
+	if !builtins {
+		return Decision{Action: Allow}
+	}
+	analysis := e.keywords.Analyze(text)
+	if analysis.IsDangerous {
+		return Decision{Action: Confirm, Rule: RuleDangerKeywords, Builtin: true}
+	}
+	if e.confirmDDL && analysis.IsDDL {
+		return Decision{Action: Confirm, Rule: RuleConfirmDDL, Builtin: true}
+	}
+	if e.confirmUnbounded && len(analysis.UnboundedDML) > 0 {
+		return Decision{Action: Confirm, Rule: RuleUnboundedDML, Builtin: true}
+	}
+	return Decision{Action: Allow}
+}
+
+// matches reports whether every criterion of r matches the statement.
+func (e *Engine) matches(r rule, text, connection string, objects []sqlanalyzer.ObjectRef) bool {
+	if len(r.Connections) > 0 && !matchAny(r.Connections, connection) {
+		return false
+	}
+	if len(r.types) > 0 && !matchType(r.types, text) {
+		return false
+	}
+	if r.tokens != nil && !r.tokens.Analyze(text).IsDangerous {
+		return false
+	}
+	if len(r.Objects) > 0 || len(r.Schemas) > 0 {
+		schema := ""
+		if e.defaultSchema != nil {
+			schema = e.defaultSchema(connection)
+		}
+		found := false
+		for _, obj := range objects {
+			if obj.Schema == "" {
+				obj.Schema = schema
+			}
+			if (len(r.Objects) == 0 || matchObject(r.Objects, obj)) && (len(r.Schemas) == 0 || matchAny(r.Schemas, obj.Schema)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// matchType reports whether the statement starts with one of the types (lower-cased word lists).
+func matchType(types [][]string, text string) bool {
+	var words []string
+	for _, t := range sqlanalyzer.Lex(text) {
+		if len(words) == 8 {
+			break
+		}
+		if t.Kind != sqlanalyzer.TokenWord {
+			continue
+		}
+		w := strings.ToLower(t.Text)
+		if len(words) > 0 && statementModifiers[w] {
+			continue
+		}
+		words = append(words, w)
+	}
+	for _, typ := range types {
+		if len(typ) <= len(words) && strings.Join(words[:len(typ)], " ") == strings.Join(typ, " ") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchObject reports whether obj matches one of the patterns; a pattern with a dot is matched against
+// SCHEMA.NAME, one without against the name only.
+func matchObject(patterns []string, obj sqlanalyzer.ObjectRef) bool {
+	for _, p := range patterns {
+		name := obj.Name
+		if strings.Contains(p, ".") {
+			name = obj.Schema + "." + obj.Name
+		}
+		if matchAny([]string{p}, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny reports whether s matches one of the glob patterns, ignoring letter case.
+func matchAny(patterns []string, s string) bool {
+	s = strings.ToUpper(s)
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToUpper(p), s); ok {
+			return true
+		}
+	}
+	return false
+}
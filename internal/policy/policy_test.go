@@ -0,0 +1,77 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/alvin/oracle-mcp-server/internal/config"
+)
+
+func testEngine(rules ...config.PolicyRule) *Engine {
+	sec := config.DefaultConfig().Security
+	sec.Rules = rules
+	return New(sec, func(connection string) string {
+		if connection == "prod" {
+			return "HR"
+		}
+		return ""
+	})
+}
+
+func TestEvaluate_Builtins(t *testing.T) {
+	e := testEngine()
+	tests := []struct {
+		sql  string
+		want Decision
+	}{
+		{"SELECT * FROM emp", Decision{Action: Allow}},
+		{"UPDATE emp SET sal = 0 WHERE empno = 7369", Decision{Action: Allow}},
+		{"UPDATE emp SET sal = 0 WHERE 1 = 1", Decision{Action: Confirm, Rule: RuleUnboundedDML, Builtin: true, Statement: 1}},
+		{"DELETE FROM emp", Decision{Action: Confirm, Rule: RuleDangerKeywords, Builtin: true, Statement: 1}},
+		{"CREATE TABLE t (id NUMBER)", Decision{Action: Confirm, Rule: RuleConfirmDDL, Builtin: true, Statement: 1}},
+		{"SELECT 1 FROM dual; CREATE TABLE t (id NUMBER)", Decision{Action: Confirm, Rule: RuleConfirmDDL, Builtin: true, Statement: 2}},
+	}
+	for _, tt := range tests {
+		if got := e.Evaluate(tt.sql, "dev"); got != tt.want {
+			t.Errorf("Evaluate(%q) = %+v, want %+v", tt.sql, got, tt.want)
+		}
+	}
+	if got := e.EvaluateRules("DELETE FROM emp", "dev"); got.Action != Allow {
+		t.Errorf("EvaluateRules should skip the built-in rules, got %+v", got)
+	}
+}
+
+func TestEvaluate_Rules(t *testing.T) {
+	e := testEngine(
+		config.PolicyRule{Name: "no-drop-user", Action: "deny", StatementTypes: []string{"DROP USER"}, Message: "ask a DBA"},
+		config.PolicyRule{Name: "hr-prod", Action: "confirm", Connections: []string{"prod*"}, Schemas: []string{"HR"}},
+		config.PolicyRule{Name: "dev-dml", Action: "allow", Connections: []string{"dev"}, StatementTypes: []string{"delete", "truncate table"}},
+		config.PolicyRule{Name: "no-dba", Action: "deny", Tokens: []string{"grant dba"}},
+		config.PolicyRule{Name: "views", Action: "allow", StatementTypes: []string{"CREATE VIEW"}, Objects: []string{"*.V_*"}},
+	)
+	tests := []struct {
+		sql, connection string
+		want            Decision
+	}{
+		{"drop user scott cascade", "dev", Decision{Action: Deny, Rule: "no-drop-user", Message: "ask a DBA", Statement: 1}},
+		{"DROP TABLE scott.t", "dev", Decision{Action: Confirm, Rule: RuleDangerKeywords, Builtin: true, Statement: 1}},
+		// unqualified names are in the connection user's schema
+		{"SELECT * FROM emp", "prod", Decision{Action: Confirm, Rule: "hr-prod", Statement: 1}},
+		{"SELECT * FROM scott.emp", "prod", Decision{Action: Allow}},
+		{"SELECT * FROM hr.emp", "dev", Decision{Action: Allow}},
+		// a configured allow rule takes precedence over danger_keywords
+		{"DELETE FROM emp", "dev", Decision{Action: Allow, Rule: "dev-dml", Statement: 1}},
+		{"TRUNCATE TABLE emp", "dev", Decision{Action: Allow, Rule: "dev-dml", Statement: 1}},
+		{"DELETE FROM emp", "test", Decision{Action: Confirm, Rule: RuleDangerKeywords, Builtin: true, Statement: 1}},
+		// the most restrictive statement of a script decides
+		{"DELETE FROM emp;\nGRANT DBA TO app;", "dev", Decision{Action: Deny, Rule: "no-dba", Statement: 2}},
+		// token patterns skip string literals; the default whole_text danger_keywords do not
+		{"SELECT 'grant dba' FROM dual", "dev", Decision{Action: Confirm, Rule: RuleDangerKeywords, Builtin: true, Statement: 1}},
+		{"CREATE OR REPLACE VIEW app.v_emp AS SELECT * FROM emp", "dev", Decision{Action: Allow, Rule: "views", Statement: 1}},
+		{"CREATE OR REPLACE VIEW app.emp_v AS SELECT * FROM emp", "dev", Decision{Action: Confirm, Rule: RuleConfirmDDL, Builtin: true, Statement: 1}},
+	}
+	for _, tt := range tests {
+		if got := e.Evaluate(tt.sql, tt.connection); got != tt.want {
+			t.Errorf("Evaluate(%q, %q) = %+v, want %+v", tt.sql, tt.connection, got, tt.want)
+		}
+	}
+}
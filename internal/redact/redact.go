@@ -0,0 +1,169 @@
+// Package redact masks secrets and personal data in SQL before it is written to the audit log, sent in
+// notifications or shown for review. The SQL sent to Oracle is never changed.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces each masked value.
+const Mask = "***"
+
+// literal matches the contents of a quoted string literal, where two quotes stand for one.
+const literal = `'((?:[^']|'')*)'`
+
+// pattern masks its capturing groups in a match, or the whole match when it has none. A pattern with inLiterals
+// only looks inside string literals, and valid, when set, rejects matches that only look like the value.
+type pattern struct {
+	re         *regexp.Regexp
+	inLiterals bool
+	valid      func(string) bool
+}
+
+// builtinPatterns are always applied by a Redactor, before the configured ones.
+var builtinPatterns = []pattern{
+	// CREATE/ALTER USER, ALTER ROLE and CREATE DATABASE LINK passwords, with the old password of REPLACE
+	{re: regexp.MustCompile(`(?i)\bIDENTIFIED\s+BY\s+(?:VALUES\s+)?(?:` + literal + `|"([^"]*)"|([^\s;'"]+))` +
+		`(?:\s+REPLACE\s+(?:"([^"]*)"|([^\s;'"]+)))?`)},
+	// Named secrets, as in DBMS_CREDENTIAL.CREATE_CREDENTIAL(..., password => '...')
+	{re: regexp.MustCompile(`(?i)\b(?:password|private_key|secret)\s*=>\s*` + literal)},
+	// Positional DBMS_CREDENTIAL / DBMS_CLOUD.CREATE_CREDENTIAL('name', 'user', 'password')
+	{re: regexp.MustCompile(`(?i)\bDBMS_(?:CREDENTIAL|CLOUD)\.CREATE_CREDENTIAL\s*\(\s*'(?:[^']|'')*'\s*,\s*'(?:[^']|'')*'\s*,\s*` + literal)},
+	// The value of UPDATE_CREDENTIAL, positional or named
+	{re: regexp.MustCompile(`(?i)\bDBMS_(?:CREDENTIAL|CLOUD)\.UPDATE_CREDENTIAL\s*\(\s*(?:'(?:[^']|'')*'\s*,\s*'(?:[^']|'')*'\s*,\s*` + literal +
+		`|[^;]*?\bvalue\s*=>\s*` + literal + `)`)},
+	// String binds whose name says they hold a secret, as formatted for review: ":new_password = '...' (string)"
+	{re: regexp.MustCompile(`(?im)^:\w*(?:password|passwd|pwd|secret|token)\w* = ` + literal)},
+	// E-mail addresses
+	{re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`), inLiterals: true},
+	// Payment card numbers, digits optionally grouped by spaces or dashes
+	{re: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), inLiterals: true, valid: luhn},
+	// US social security numbers
+	{re: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), inLiterals: true},
+}
+
+// literalRe finds the string literals inLiterals patterns look into.
+var literalRe = regexp.MustCompile(literal)
+
+// Redactor masks the values matched by the built-in patterns and by configured regular expressions.
+// A nil *Redactor masks nothing.
+type Redactor struct {
+	patterns []pattern
+}
+
+// New returns a Redactor with the built-in patterns and extra, regular expressions in Go syntax (security.redact_patterns).
+func New(extra []string) (*Redactor, error) {
+	r := &Redactor{patterns: append([]pattern(nil), builtinPatterns...)}
+	for i, expr := range extra {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("redact pattern %d: %w", i+1, err)
+		}
+		r.patterns = append(r.patterns, pattern{re: re})
+	}
+	return r, nil
+}
+
+// Redact returns s with every matched value replaced by Mask.
+func (r *Redactor) Redact(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, p := range r.patterns {
+		if !p.inLiterals {
+			s = p.mask(s)
+			continue
+		}
+		s = replaceGroups(s, literalRe.FindAllStringSubmatchIndex(s, -1), func(contents string) string {
+			return p.mask(contents)
+		})
+	}
+	return s
+}
+
+// RedactAll returns Redact of each line; lines is returned as is when nothing was masked.
+func (r *Redactor) RedactAll(lines []string) []string {
+	if r == nil {
+		return lines
+	}
+	var out []string
+	for i, line := range lines {
+		masked := r.Redact(line)
+		if masked != line && out == nil {
+			out = append(make([]string, 0, len(lines)), lines[:i]...)
+		}
+		if out != nil {
+			out = append(out, masked)
+		}
+	}
+	if out == nil {
+		return lines
+	}
+	return out
+}
+
+// mask replaces the values p matches in s.
+func (p pattern) mask(s string) string {
+	matches := p.re.FindAllStringSubmatchIndex(s, -1)
+	if p.valid != nil {
+		kept := matches[:0]
+		for _, m := range matches {
+			if p.valid(s[m[0]:m[1]]) {
+				kept = append(kept, m)
+			}
+		}
+		matches = kept
+	}
+	return replaceGroups(s, matches, func(string) string { return Mask })
+}
+
+// replaceGroups replaces, in each match of matches (as from FindAllStringSubmatchIndex), the capturing groups
+// that took part in it, or the whole match when the expression has no groups, by replace of their text.
+// Overlapping groups are replaced once.
+func replaceGroups(s string, matches [][]int, replace func(string) string) string {
+	if len(matches) == 0 {
+		return s
+	}
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		spans := m[2:]
+		if len(spans) == 0 {
+			spans = m[:2]
+		}
+		for i := 0; i < len(spans); i += 2 {
+			start, end := spans[i], spans[i+1]
+			if start < 0 || start < last || start == end {
+				continue
+			}
+			sb.WriteString(s[last:start])
+			sb.WriteString(replace(s[start:end]))
+			last = end
+		}
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+// luhn reports whether the digits of s pass the Luhn check of payment card numbers.
+func luhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
@@ -0,0 +1,51 @@
+package redact
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	r, err := New([]string{`(?i)api_key\s*=\s*'([^']*)'`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"create user", "CREATE USER app IDENTIFIED BY S3cret#1 DEFAULT TABLESPACE users",
+			"CREATE USER app IDENTIFIED BY *** DEFAULT TABLESPACE users"},
+		{"quoted password", `ALTER USER app IDENTIFIED BY "p@ss word";`, `ALTER USER app IDENTIFIED BY "***";`},
+		{"replace", "alter user app identified by new_pw replace old_pw", "alter user app identified by *** replace ***"},
+		{"values", "ALTER USER app IDENTIFIED BY VALUES 'S:ABCDEF'", "ALTER USER app IDENTIFIED BY VALUES '***'"},
+		{"database link", "CREATE DATABASE LINK remote CONNECT TO scott IDENTIFIED BY tiger USING 'orcl'",
+			"CREATE DATABASE LINK remote CONNECT TO scott IDENTIFIED BY *** USING 'orcl'"},
+		{"credential named", "BEGIN DBMS_CREDENTIAL.CREATE_CREDENTIAL(credential_name => 'C', username => 'u', password => 'it''s'); END;",
+			"BEGIN DBMS_CREDENTIAL.CREATE_CREDENTIAL(credential_name => 'C', username => 'u', password => '***'); END;"},
+		{"credential positional", "BEGIN DBMS_CLOUD.CREATE_CREDENTIAL('C', 'u', 'token'); END;",
+			"BEGIN DBMS_CLOUD.CREATE_CREDENTIAL('C', 'u', '***'); END;"},
+		{"update credential", "exec dbms_credential.update_credential(credential_name => 'C', attribute => 'PASSWORD', value => 'new')",
+			"exec dbms_credential.update_credential(credential_name => 'C', attribute => 'PASSWORD', value => '***')"},
+		{"secret bind", ":new_password = 'hunter2' (string)", ":new_password = '***' (string)"},
+		{"email literal", "SELECT * FROM emp WHERE email = 'jane.doe@example.com'", "SELECT * FROM emp WHERE email = '***'"},
+		{"db link is not an email", "SELECT * FROM emp@sales.example.com", "SELECT * FROM emp@sales.example.com"},
+		{"card", "INSERT INTO pay VALUES (1, '4111 1111 1111 1111', 'ssn 123-45-6789')", "INSERT INTO pay VALUES (1, '***', 'ssn ***')"},
+		{"not a card", "UPDATE t SET ref = '1234567890123' WHERE id = 4111111111111111", "UPDATE t SET ref = '1234567890123' WHERE id = 4111111111111111"},
+		{"configured", "UPDATE cfg SET api_key = 'abc'", "UPDATE cfg SET api_key = '***'"},
+		{"plain", "SELECT password FROM dual", "SELECT password FROM dual"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Redact(tt.in); got != tt.want {
+				t.Errorf("Redact(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	var none *Redactor
+	if got := none.Redact("CREATE USER a IDENTIFIED BY b"); got != "CREATE USER a IDENTIFIED BY b" {
+		t.Errorf("nil Redactor changed the SQL: %q", got)
+	}
+	if _, err := New([]string{"("}); err == nil {
+		t.Error("New accepted an invalid pattern")
+	}
+}
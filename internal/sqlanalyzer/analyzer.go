@@ -1,11 +1,10 @@
 // Package sqlanalyzer provides SQL safety analysis functionality.
-// It handles comment/string removal and keyword matching for dangerous SQL detection.
+// It lexes Oracle SQL and PL/SQL (Lex), splits scripts into statements (SplitStatements) and matches danger
+// keywords outside comments and string literals.
 package sqlanalyzer
 
 import (
-	"regexp"
 	"strings"
-	"unicode"
 )
 
 // AnalysisResult contains the result of SQL analysis.
@@ -28,6 +27,19 @@ type AnalysisResult struct {
 	ContainsPLSQL bool
 	// IsPLSQLCreationDDL is true when the SQL is a single CREATE PROCEDURE/FUNCTION/PACKAGE ... END; (allowed to run).
 	IsPLSQLCreationDDL bool
+	// IsExplainPlan is true for a single EXPLAIN PLAN statement. It only writes the plan table, so it is read-only:
+	// danger keywords are not matched, even in the explained statement (EXPLAIN PLAN FOR DELETE ...).
+	IsExplainPlan bool
+	// IsQuery is true when every statement is a query (SELECT or WITH) without FOR UPDATE and without PL/SQL
+	// declarations (WITH FUNCTION); read_only connections run nothing else.
+	IsQuery bool
+	// NonQuery is the first statement that is not such a query; set when IsQuery is false.
+	NonQuery Statement
+	// DML lists the scope of every UPDATE, DELETE and MERGE statement outside PL/SQL blocks.
+	DML []DMLScope
+	// UnboundedDML lists the UPDATE, DELETE and MERGE statements that nothing limits: no WHERE clause, or one that
+	// is always true (see DMLScope).
+	UnboundedDML []DMLScope
 }
 
 // Analyzer performs SQL safety analysis.
@@ -73,171 +85,121 @@ func (a *Analyzer) Analyze(sql string) *AnalysisResult {
 		OriginalSQL: sql,
 	}
 
-	// Step 1: Remove comments
-	noComments := removeComments(sql)
+	// Step 1: Lex, then drop comments, hints and string literals
+	tokens := Lex(sql)
+	result.NormalizedSQL = normalize(tokens)
 
-	// Step 2: Remove string literals
-	noStrings := removeStringLiterals(noComments)
+	// Step 2: Split into statements; check for multiple statements and PL/SQL creation DDL
+	statements := SplitStatements(sql)
+	result.IsPLSQLCreationDDL = len(statements) == 1 && isCreationUnit(statements[0])
+	result.IsMultiStatement = len(statements) > 1
 
-	result.NormalizedSQL = noStrings
-
-	// Step 3: Check for multiple statements and PL/SQL creation DDL
-	result.IsPLSQLCreationDDL = isPLSQLCreationDDL(noStrings)
-	result.IsMultiStatement = isMultiStatement(noStrings)
-
-	// Step 4: Check for PL/SQL blocks (unless it's a CREATE PROCEDURE/FUNCTION/PACKAGE)
-	result.ContainsPLSQL = !result.IsPLSQLCreationDDL && containsPLSQL(noStrings)
+	// Step 3: Check for PL/SQL blocks (unless it's a CREATE PROCEDURE/FUNCTION/PACKAGE)
+	for _, st := range statements {
+		if st.PLSQL && !result.IsPLSQLCreationDDL {
+			result.ContainsPLSQL = true
+		}
+	}
 
-	// Step 5: Tokenize
-	result.Tokens = tokenize(noStrings)
+	// Step 4: Words and numbers, lower-cased (quoted identifiers are names, never keywords)
+	result.Tokens = words(tokens)
 
-	// Step 6: Check for DDL
+	// Step 5: Check for DDL
 	result.IsDDL = a.isDDL(result.Tokens)
 
-	// Step 7: Match danger keywords (by full SQL or by tokens depending on mode)
-	if a.matchMode == "whole_text" {
+	// Step 6: Match danger keywords (by full SQL or by tokens depending on mode); EXPLAIN PLAN never runs the statement
+	result.IsExplainPlan = isExplainPlan(result.Tokens) && !result.IsMultiStatement && !result.ContainsPLSQL
+	if result.IsExplainPlan {
+		result.MatchedKeywords = nil
+	} else if a.matchMode == "whole_text" {
 		result.MatchedKeywords = a.matchKeywordsWholeText(sql)
 	} else {
 		result.MatchedKeywords = a.matchKeywords(result.Tokens)
 	}
 	result.IsDangerous = len(result.MatchedKeywords) > 0
 
-	return result
-}
-
-// removeComments removes SQL comments (-- and /* */).
-func removeComments(sql string) string {
-	// Remove single-line comments (-- comment)
-	singleLinePattern := regexp.MustCompile(`--[^\r\n]*`)
-	sql = singleLinePattern.ReplaceAllString(sql, " ")
-
-	// Remove multi-line comments (/* comment */)
-	multiLinePattern := regexp.MustCompile(`/\*[\s\S]*?\*/`)
-	sql = multiLinePattern.ReplaceAllString(sql, " ")
-
-	return sql
-}
+	// Step 7: Check whether the SQL only reads
+	result.IsQuery = true
+	for _, st := range statements {
+		if !isQuery(st) {
+			result.IsQuery = false
+			result.NonQuery = st
+			break
+		}
+	}
 
-// removeStringLiterals removes string literals ('string') to prevent false positives.
-// Example: SELECT 'drop table' FROM dual; should not match "drop table"
-func removeStringLiterals(sql string) string {
-	var result strings.Builder
-	inString := false
-	prevChar := rune(0)
-
-	for _, char := range sql {
-		if char == '\'' && prevChar != '\'' {
-			if inString {
-				// Check for escaped quote ('')
-				inString = false
-			} else {
-				inString = true
-			}
-			result.WriteRune(' ')
-		} else if inString {
-			// Skip characters inside string literals
-			result.WriteRune(' ')
-		} else {
-			result.WriteRune(char)
+	// Step 8: Flag UPDATE, DELETE and MERGE statements without a limiting WHERE clause
+	result.DML = DMLScopes(sql)
+	for _, scope := range result.DML {
+		if scope.Unbounded {
+			result.UnboundedDML = append(result.UnboundedDML, scope)
 		}
-		prevChar = char
 	}
 
-	return result.String()
+	return result
 }
 
-// IsSingleStatementBlock reports whether the entire SQL should be executed as one (no split).
-// True for CREATE PROCEDURE/FUNCTION/PACKAGE ... END; or BEGIN...END; / DECLARE...END;
-func IsSingleStatementBlock(sql string) bool {
-	return isPLSQLCreationDDL(sql) || isAnonymousBlock(sql)
+// normalize rebuilds the SQL with each comment, hint and string literal replaced by a space.
+func normalize(tokens []Token) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		switch t.Kind {
+		case TokenComment, TokenHint, TokenString:
+			b.WriteByte(' ')
+		default:
+			b.WriteString(t.Text)
+		}
+	}
+	return b.String()
 }
 
-// trimLeadingLineComments removes leading blank lines and full lines that are only -- line comments.
-// Used so BEGIN...END; blocks in SQL*Plus-style segments (after splitting on "/") are recognized
-// even when the segment starts with header comments.
-func trimLeadingLineComments(sql string) string {
-	s := strings.ReplaceAll(strings.TrimSpace(sql), "\r\n", "\n")
-	s = strings.ReplaceAll(s, "\r", "\n")
-	for s != "" {
-		idx := strings.IndexByte(s, '\n')
-		var line string
-		if idx < 0 {
-			line = s
-			s = ""
+// removeComments removes SQL comments (-- and /* */, hints included), replacing each with a space.
+// Comment markers inside string literals and quoted identifiers are left alone.
+func removeComments(sql string) string {
+	var b strings.Builder
+	for _, t := range Lex(sql) {
+		if t.Kind == TokenComment || t.Kind == TokenHint {
+			b.WriteByte(' ')
 		} else {
-			line = s[:idx]
-			s = s[idx+1:]
-		}
-		t := strings.TrimSpace(line)
-		if t == "" {
-			continue
-		}
-		if strings.HasPrefix(t, "--") {
-			continue
+			b.WriteString(t.Text)
 		}
-		// First substantive line: keep it and the rest of the script
-		if idx < 0 {
-			return strings.TrimSpace(line)
-		}
-		return strings.TrimSpace(line + "\n" + s)
 	}
-	return ""
+	return b.String()
 }
 
-// lastNonEmptyLine returns the last non-empty line (trimmed) in lower-case text.
-func lastNonEmptyLine(lower string) string {
-	lines := strings.Split(lower, "\n")
-	for i := len(lines) - 1; i >= 0; i-- {
-		t := strings.TrimSpace(lines[i])
-		if t != "" {
-			return t
+// removeStringLiterals removes string literals ('string', N'string', q'[string]') to prevent false positives.
+// Example: SELECT 'drop table' FROM dual; should not match "drop table"
+func removeStringLiterals(sql string) string {
+	var b strings.Builder
+	for _, t := range Lex(sql) {
+		if t.Kind == TokenString {
+			b.WriteByte(' ')
+		} else {
+			b.WriteString(t.Text)
 		}
 	}
-	return ""
+	return b.String()
 }
 
-// looksLikePlsqlBlockEnd is true when the last non-empty line is the block-closing END (or END label),
-// but not END IF / END LOOP / END CASE.
-func looksLikePlsqlBlockEnd(lower string) bool {
-	last := lastNonEmptyLine(lower)
-	if last == "" {
-		return false
-	}
-	if last == "end" {
-		return true
-	}
-	if !strings.HasPrefix(last, "end ") {
-		return false
-	}
-	rest := strings.TrimSpace(strings.TrimPrefix(last, "end"))
-	if rest == "" {
-		return true
-	}
-	first := strings.Fields(rest)[0]
-	switch first {
-	case "if", "loop", "case":
-		return false
-	default:
-		return true // END some_label
-	}
+// IsSingleStatementBlock reports whether the entire SQL should be executed as one (no split).
+// True for a single CREATE PROCEDURE/FUNCTION/PACKAGE/TRIGGER/TYPE, BEGIN...END; / DECLARE...END; or
+// WITH FUNCTION query.
+func IsSingleStatementBlock(sql string) bool {
+	statements := SplitStatements(sql)
+	return len(statements) == 1 && statements[0].PLSQL
 }
 
+// isAnonymousBlock reports whether the SQL is a single BEGIN...END; or DECLARE...END; block.
 func isAnonymousBlock(sql string) bool {
-	trimmed := strings.TrimSpace(trimLeadingLineComments(sql))
-	if trimmed == "" {
+	statements := SplitStatements(sql)
+	if len(statements) != 1 || !statements[0].PLSQL {
 		return false
 	}
-	if strings.HasSuffix(trimmed, ";") {
-		trimmed = strings.TrimSuffix(trimmed, ";")
-	}
-	lower := strings.ToLower(trimmed)
-	hasEnd := strings.Contains(lower, " end ") ||
-		strings.HasSuffix(lower, " end") ||
-		looksLikePlsqlBlockEnd(lower)
-	return (strings.HasPrefix(lower, "begin") || strings.HasPrefix(lower, "declare")) && hasEnd
+	first := significantWords(Lex(statements[0].Text), 1)
+	return first[0] == "begin" || first[0] == "declare" || first[0] == "<<"
 }
 
-// IsPLSQLCreationStatement reports whether the SQL is a CREATE PROCEDURE/FUNCTION/PACKAGE ... END; block.
+// IsPLSQLCreationStatement reports whether the SQL is a single CREATE PROCEDURE/FUNCTION/PACKAGE/TRIGGER/TYPE.
 // When true, the executor should not strip the trailing semicolon (Oracle requires it for PL/SQL compilation).
 func IsPLSQLCreationStatement(sql string) bool {
 	return isPLSQLCreationDDL(sql)
@@ -249,112 +211,54 @@ func KeepTrailingSemicolon(sql string) bool {
 	return isPLSQLCreationDDL(sql) || isAnonymousBlock(sql)
 }
 
-// isPLSQLCreationDDL reports whether the SQL is a single CREATE PROCEDURE/FUNCTION/PACKAGE ... END; block.
+// isPLSQLCreationDDL reports whether the SQL is a single CREATE PROCEDURE/FUNCTION/PACKAGE/TRIGGER/TYPE.
 // Leading comments (-- or /* */) and blank lines are ignored so that files starting with comments are still detected.
 func isPLSQLCreationDDL(sql string) bool {
-	trimmed := strings.TrimSpace(sql)
-	if trimmed == "" {
-		return false
-	}
-	lower := strings.ToLower(trimmed)
-	// Skip BOM
-	if strings.HasPrefix(lower, "\ufeff") {
-		lower = lower[1:]
-	}
-	// Find first "create" (start of statement after leading comments)
-	idx := strings.Index(lower, "create")
-	if idx == -1 {
-		return false
-	}
-	// From first "create" onward, must look like CREATE [OR REPLACE] PROCEDURE/FUNCTION/PACKAGE ... END
-	stmt := lower[idx:]
-	if !strings.HasPrefix(stmt, "create") {
-		return false
-	}
-	hasPlsql := strings.Contains(stmt, "procedure") || strings.Contains(stmt, " function ") || strings.Contains(stmt, " package ")
-	hasEnd := strings.Contains(stmt, " end ") ||
-		strings.Contains(stmt, " end;") ||
-		strings.Contains(stmt, "\nend ") ||
-		strings.Contains(stmt, "\nend;") ||
-		strings.HasSuffix(stmt, " end") ||
-		strings.HasSuffix(stmt, " end;")
-	return hasPlsql && hasEnd
+	statements := SplitStatements(sql)
+	return len(statements) == 1 && isCreationUnit(statements[0])
 }
 
-// isMultiStatement checks if the SQL contains multiple statements.
-func isMultiStatement(sql string) bool {
-	trimmed := strings.TrimSpace(sql)
-	if trimmed == "" {
-		return false
-	}
-	if strings.HasSuffix(trimmed, ";") {
-		trimmed = strings.TrimSuffix(trimmed, ";")
-	}
-	if !strings.Contains(trimmed, ";") {
-		return false
-	}
-	// Single CREATE PROCEDURE/FUNCTION/PACKAGE ... END; is one statement (PL/SQL body has semicolons)
-	if isPLSQLCreationDDL(sql) {
-		return false
-	}
-	// Anonymous PL/SQL block BEGIN ... END; or DECLARE ... BEGIN ... END; is one statement
-	lower := strings.ToLower(trimmed)
-	hasEnd := strings.Contains(lower, " end ") || strings.HasSuffix(lower, " end")
-	if (strings.HasPrefix(lower, "begin") || strings.HasPrefix(lower, "declare")) && hasEnd {
-		return false
-	}
-	return true
+// isCreationUnit reports whether the statement creates a PL/SQL unit.
+func isCreationUnit(st Statement) bool {
+	return st.PLSQL && significantWords(Lex(st.Text), 1)[0] == "create"
 }
 
-// containsPLSQL checks if the SQL contains PL/SQL blocks.
-func containsPLSQL(sql string) bool {
-	lower := strings.ToLower(sql)
-	tokens := tokenize(lower)
-
-	// Check for anonymous blocks
-	for i, token := range tokens {
-		if token == "begin" {
-			// Check if there's a matching END
-			for j := i + 1; j < len(tokens); j++ {
-				if tokens[j] == "end" {
-					return true
-				}
-			}
-		}
-		if token == "declare" {
-			return true
+// words returns the words and numbers of the tokens, lower-cased.
+func words(tokens []Token) []string {
+	var out []string
+	for _, t := range tokens {
+		if t.Kind == TokenWord || t.Kind == TokenNumber {
+			out = append(out, strings.ToLower(t.Text))
 		}
 	}
-
-	return false
+	return out
 }
 
-// tokenize splits the SQL into tokens.
+// tokenize returns the words and numbers of the SQL, lower-cased, ignoring comments and string literals.
 func tokenize(sql string) []string {
-	// Convert to lowercase
-	lower := strings.ToLower(sql)
+	return words(Lex(sql))
+}
 
-	// Split by non-alphanumeric characters (except underscore)
-	var tokens []string
-	var currentToken strings.Builder
+// isExplainPlan checks if the tokens start an EXPLAIN PLAN statement.
+func isExplainPlan(tokens []string) bool {
+	return len(tokens) >= 2 && tokens[0] == "explain" && tokens[1] == "plan"
+}
 
-	for _, char := range lower {
-		if unicode.IsLetter(char) || unicode.IsDigit(char) || char == '_' {
-			currentToken.WriteRune(char)
-		} else {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-		}
+// isQuery reports whether st is a SELECT or WITH query that takes no row locks (FOR UPDATE) and declares no PL/SQL.
+func isQuery(st Statement) bool {
+	if st.PLSQL {
+		return false
 	}
-
-	// Don't forget the last token
-	if currentToken.Len() > 0 {
-		tokens = append(tokens, currentToken.String())
+	tokens := tokenize(st.Text)
+	if len(tokens) == 0 || (tokens[0] != "select" && tokens[0] != "with") {
+		return false
 	}
-
-	return tokens
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i] == "for" && tokens[i+1] == "update" {
+			return false
+		}
+	}
+	return true
 }
 
 // isDDL checks if the SQL is a DDL statement.
@@ -445,42 +349,19 @@ func matchConsecutiveTokens(tokens, kwTokens []string) bool {
 	return false
 }
 
-// GetStatementType returns the type of SQL statement.
+// GetStatementType returns the type of SQL statement: its first word upper-cased (SELECT, INSERT, CREATE, ...),
+// or UNKNOWN when it has none. A <<label>> before a PL/SQL block is skipped.
 func GetStatementType(sql string) string {
-	noComments := removeComments(sql)
-	noStrings := removeStringLiterals(noComments)
-	tokens := tokenize(noStrings)
-
-	if len(tokens) == 0 {
-		return "UNKNOWN"
-	}
-
-	switch tokens[0] {
-	case "select":
-		return "SELECT"
-	case "insert":
-		return "INSERT"
-	case "update":
-		return "UPDATE"
-	case "delete":
-		return "DELETE"
-	case "create":
-		return "CREATE"
-	case "drop":
-		return "DROP"
-	case "alter":
-		return "ALTER"
-	case "truncate":
-		return "TRUNCATE"
-	case "grant":
-		return "GRANT"
-	case "revoke":
-		return "REVOKE"
-	case "rename":
-		return "RENAME"
-	case "comment":
-		return "COMMENT"
-	default:
-		return strings.ToUpper(tokens[0])
+	label := false
+	for _, t := range Lex(sql) {
+		switch {
+		case t.Kind == TokenOperator && t.Text == "<<":
+			label = true
+		case t.Kind == TokenOperator && t.Text == ">>":
+			label = false
+		case !label && (t.Kind == TokenWord || t.Kind == TokenNumber):
+			return strings.ToUpper(t.Text)
+		}
 	}
+	return "UNKNOWN"
 }
@@ -264,3 +264,55 @@ func TestGetStatementType(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzer_ExplainPlanIsReadOnly(t *testing.T) {
+	for _, mode := range []string{"whole_text", "tokens"} {
+		analyzer := NewAnalyzer([]string{"delete", "drop", "update"}, mode)
+		tests := []struct {
+			name        string
+			sql         string
+			wantExplain bool
+		}{
+			{"explain delete", "EXPLAIN PLAN FOR DELETE FROM orders WHERE id = 1", true},
+			{"explain with statement id", "explain plan set statement_id = 'x' for update t set a = 1;", true},
+			{"explain then drop", "EXPLAIN PLAN FOR SELECT 1 FROM dual;\nDROP TABLE t;", false},
+			{"plain delete", "DELETE FROM orders", false},
+		}
+		for _, tt := range tests {
+			t.Run(mode+"/"+tt.name, func(t *testing.T) {
+				result := analyzer.Analyze(tt.sql)
+				if result.IsExplainPlan != tt.wantExplain || result.IsDangerous == tt.wantExplain {
+					t.Errorf("IsExplainPlan = %v, IsDangerous = %v (keywords %v), want explain %v", result.IsExplainPlan, result.IsDangerous, result.MatchedKeywords, tt.wantExplain)
+				}
+			})
+		}
+	}
+}
+
+func TestAnalyzer_IsQuery(t *testing.T) {
+	analyzer := NewAnalyzer(nil, "tokens")
+	tests := []struct {
+		sql          string
+		wantQuery    bool
+		wantNonQuery string
+	}{
+		{"SELECT * FROM emp", true, ""},
+		{"with t as (select 1 x from dual) select x from t", true, ""},
+		{"(SELECT 1 FROM dual) UNION ALL (SELECT 2 FROM dual)", true, ""},
+		{"SELECT 'delete from emp' FROM dual; SELECT 2 FROM dual;", true, ""},
+		{"SELECT * FROM emp WHERE id = 1 FOR UPDATE", false, "SELECT * FROM emp WHERE id = 1 FOR UPDATE"},
+		{"SELECT 1 FROM dual;\nUPDATE emp SET sal = 0;", false, "UPDATE emp SET sal = 0"},
+		{"WITH FUNCTION f RETURN NUMBER IS BEGIN RETURN 1; END;\nSELECT f FROM dual\n/", false, ""},
+		{"BEGIN NULL; END;", false, "BEGIN NULL; END;"},
+		{"EXPLAIN PLAN FOR SELECT 1 FROM dual", false, "EXPLAIN PLAN FOR SELECT 1 FROM dual"},
+	}
+	for _, tt := range tests {
+		result := analyzer.Analyze(tt.sql)
+		if result.IsQuery != tt.wantQuery {
+			t.Errorf("Analyze(%q).IsQuery = %v, want %v", tt.sql, result.IsQuery, tt.wantQuery)
+		}
+		if tt.wantNonQuery != "" && result.NonQuery.Text != tt.wantNonQuery {
+			t.Errorf("Analyze(%q).NonQuery = %q, want %q", tt.sql, result.NonQuery.Text, tt.wantNonQuery)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package sqlanalyzer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DMLScope is the row scope of an UPDATE, DELETE or MERGE statement: its target and the condition that limits the
+// rows it changes.
+type DMLScope struct {
+	Statement int    // position of the statement in the SQL, from 1
+	Line      int    // line the statement starts on
+	Type      string // UPDATE, DELETE or MERGE
+	Target    string // target as written, with its alias: "hr.emp e"
+	// Where is the WHERE condition (the ON condition for MERGE) as written; "" when there is none.
+	Where string
+	// Unbounded is set when nothing limits the rows: there is no WHERE clause, or it is always true (1 = 1,
+	// 'a' = 'a', id = id, TRUE, NULL IS NULL, ...).
+	Unbounded bool
+	// Countable is set when CountSQL calls no functions and has no bind variables, so it can run on its own before
+	// review without side effects.
+	Countable bool
+}
+
+// Reason says why the statement is unbounded, for messages.
+func (d DMLScope) Reason() string {
+	if d.Where == "" {
+		return "no WHERE clause"
+	}
+	if d.Type == "MERGE" {
+		return "ON condition is always true"
+	}
+	return "WHERE condition is always true"
+}
+
+// CountSQL returns a query that counts the rows the statement works on: SELECT COUNT(*) FROM target WHERE condition.
+// For MERGE it counts the rows of the target, the most it can update or delete.
+func (d DMLScope) CountSQL() string {
+	q := "SELECT COUNT(*) FROM " + d.Target
+	if d.Where != "" && d.Type != "MERGE" {
+		q += " WHERE " + d.Where
+	}
+	return q
+}
+
+// DMLScopes returns the scope of each UPDATE, DELETE and MERGE statement in sql (best effort; DML inside PL/SQL
+// blocks is not looked at).
+func DMLScopes(sql string) []DMLScope {
+	var out []DMLScope
+	for i, st := range SplitStatements(sql) {
+		if st.PLSQL {
+			continue
+		}
+		var sig []Token
+		for _, t := range Lex(st.Text) {
+			if t.Significant() {
+				sig = append(sig, t)
+			}
+		}
+		if len(sig) < 2 {
+			continue
+		}
+		scope := DMLScope{Statement: i + 1, Line: st.Line, Type: strings.ToUpper(sig[0].Text)}
+		var target, where []Token
+		switch {
+		case sig[0].IsWord("update"):
+			set := topLevelWord(sig, 1, "set")
+			if set < 0 {
+				continue
+			}
+			target = sig[1:set]
+			where = clause(sig, set+1, "where")
+		case sig[0].IsWord("delete"):
+			start := 1
+			if sig[1].IsWord("from") {
+				start = 2
+			}
+			end := len(sig)
+			for _, w := range []string{"where", "returning", "return", "log"} {
+				if j := topLevelWord(sig, start, w); j >= 0 && j < end {
+					end = j
+				}
+			}
+			target = sig[start:end]
+			where = clause(sig, start, "where")
+		case sig[0].IsWord("merge"):
+			into := topLevelWord(sig, 1, "into")
+			using := topLevelWord(sig, 1, "using")
+			if into < 0 || using < into {
+				continue
+			}
+			target = sig[into+1 : using]
+			on := topLevelWord(sig, using, "on")
+			if on < 0 || on+1 >= len(sig) || sig[on+1].Text != "(" {
+				continue
+			}
+			where = group(sig, on+1)
+		default:
+			continue
+		}
+		if len(target) == 0 {
+			continue
+		}
+		scope.Target = tokenSpan(st.Text, target)
+		scope.Where = tokenSpan(st.Text, where)
+		scope.Unbounded = len(where) == 0 || alwaysTrue(where)
+		scope.Countable = !callsFunction(target) && !callsFunction(where) && !hasBind(target) && !hasBind(where)
+		out = append(out, scope)
+	}
+	return out
+}
+
+// topLevelWord returns the index of the first word w at parenthesis depth 0 from sig[from], or -1.
+func topLevelWord(sig []Token, from int, w string) int {
+	depth := 0
+	for i := from; i < len(sig); i++ {
+		switch {
+		case sig[i].Text == "(":
+			depth++
+		case sig[i].Text == ")":
+			depth--
+		case depth == 0 && sig[i].IsWord(w):
+			return i
+		}
+	}
+	return -1
+}
+
+// clause returns the tokens of the top-level clause started by word (e.g. "where") from sig[from], up to a
+// RETURNING or LOG ERRORS clause or the end; nil when there is no such clause.
+func clause(sig []Token, from int, word string) []Token {
+	start := topLevelWord(sig, from, word)
+	if start < 0 {
+		return nil
+	}
+	end := len(sig)
+	for _, w := range []string{"returning", "return", "log"} {
+		if j := topLevelWord(sig, start+1, w); j >= 0 && j < end {
+			end = j
+		}
+	}
+	return sig[start+1 : end]
+}
+
+// group returns the tokens inside the parentheses opened at sig[open].
+func group(sig []Token, open int) []Token {
+	depth := 0
+	for i := open; i < len(sig); i++ {
+		switch sig[i].Text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return sig[open+1 : i]
+			}
+		}
+	}
+	return sig[open+1:]
+}
+
+// tokenSpan returns the text of text from the first to the last of the tokens, comments between them included.
+func tokenSpan(text string, tokens []Token) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	last := tokens[len(tokens)-1]
+	return text[tokens[0].Pos : last.Pos+len(last.Text)]
+}
+
+// conditionWords are the keywords that may stand before "(" in a condition or subquery without calling anything:
+// IN (1, 2), EXISTS (SELECT ...), = ANY (...), AND (a OR b), CASE WHEN (...), FROM (SELECT ...), ...
+var conditionWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`and or not in exists any all some case when then else end between like escape is
+		select from where on as by group having order union intersect minus distinct prior connect start with join
+		using`) {
+		conditionWords[w] = true
+	}
+}
+
+// callsFunction reports whether the tokens contain a name followed by "(" (a function call, or a PARTITION clause).
+// Keywords such as IN, EXISTS or AND before "(" are not calls (see conditionWords).
+func callsFunction(tokens []Token) bool {
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i+1].Text != "(" {
+			continue
+		}
+		if tokens[i].Kind == TokenQuotedIdent || (tokens[i].Kind == TokenWord && !conditionWords[strings.ToLower(tokens[i].Text)]) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasBind reports whether the tokens contain a bind variable.
+func hasBind(tokens []Token) bool {
+	for _, t := range tokens {
+		if t.Kind == TokenBind {
+			return true
+		}
+	}
+	return false
+}
+
+// alwaysTrue reports whether a condition holds for every row: an OR with a true branch, an AND of true conditions,
+// or one trivially true comparison.
+func alwaysTrue(cond []Token) bool {
+	cond = stripParens(cond)
+	if len(cond) == 0 {
+		return false
+	}
+	if branches := splitTopLevel(cond, "or"); len(branches) > 1 {
+		for _, b := range branches {
+			if alwaysTrue(b) {
+				return true
+			}
+		}
+		return false
+	}
+	if terms := splitTopLevel(cond, "and"); len(terms) > 1 {
+		for _, t := range terms {
+			if !alwaysTrue(t) {
+				return false
+			}
+		}
+		return true
+	}
+	return trueComparison(cond)
+}
+
+// stripParens removes parentheses around the whole condition.
+func stripParens(cond []Token) []Token {
+	for len(cond) >= 2 && cond[0].Text == "(" && cond[len(cond)-1].Text == ")" && len(group(cond, 0)) == len(cond)-2 {
+		cond = cond[1 : len(cond)-1]
+	}
+	return cond
+}
+
+// splitTopLevel splits the condition at the word w outside parentheses.
+func splitTopLevel(cond []Token, w string) [][]Token {
+	var parts [][]Token
+	start, depth := 0, 0
+	for i, t := range cond {
+		switch {
+		case t.Text == "(":
+			depth++
+		case t.Text == ")":
+			depth--
+		case depth == 0 && t.IsWord(w):
+			parts = append(parts, cond[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, cond[start:])
+}
+
+// comparisons are the operators trueComparison understands.
+var comparisons = map[string]bool{"=": true, "<>": true, "!=": true, "^=": true, "~=": true, "<": true, ">": true, "<=": true, ">=": true}
+
+// trueComparison reports whether cond is TRUE, NULL IS NULL, a literal IS NOT NULL, a comparison of two literals
+// that holds, or an equality of two identical operands (a column compared with itself, e.g. id = id).
+func trueComparison(cond []Token) bool {
+	if len(cond) == 1 {
+		return cond[0].IsWord("true")
+	}
+	if n := len(cond); n >= 3 && cond[n-1].IsWord("null") && cond[n-2].IsWord("is") {
+		if n == 3 && cond[0].IsWord("null") {
+			return true
+		}
+		return n == 4 && cond[1].IsWord("not") && (cond[0].Kind == TokenNumber || (cond[0].Kind == TokenString && cond[0].Text != "''"))
+	}
+	op := -1
+	for i, t := range cond {
+		if t.Kind == TokenOperator && comparisons[t.Text] {
+			op = i
+			break
+		}
+	}
+	if op <= 0 || op == len(cond)-1 {
+		return false
+	}
+	left, right, operator := cond[:op], cond[op+1:], cond[op].Text
+	if sameOperand(left, right) {
+		return operator == "=" || operator == "<=" || operator == ">="
+	}
+	if len(left) != 1 || len(right) != 1 || left[0].Kind != right[0].Kind {
+		return false
+	}
+	var c int
+	switch left[0].Kind {
+	case TokenNumber:
+		a, errA := strconv.ParseFloat(strings.TrimRight(left[0].Text, "fFdD"), 64)
+		b, errB := strconv.ParseFloat(strings.TrimRight(right[0].Text, "fFdD"), 64)
+		if errA != nil || errB != nil {
+			return false
+		}
+		switch {
+		case a < b:
+			c = -1
+		case a > b:
+			c = 1
+		}
+	case TokenString:
+		if left[0].Text == "''" || right[0].Text == "''" { // '' is NULL
+			return false
+		}
+		c = strings.Compare(left[0].Text, right[0].Text)
+	default:
+		return false
+	}
+	switch operator {
+	case "=":
+		return c == 0
+	case "<":
+		return c < 0
+	case ">":
+		return c > 0
+	case "<=":
+		return c <= 0
+	case ">=":
+		return c >= 0
+	default:
+		return c != 0
+	}
+}
+
+// sameOperand reports whether two operands are the same literal or column reference ([alias.]name). Operands with
+// function calls, bind variables or keywords (NULL, SYSDATE, ...) are never the same, and neither is the empty
+// string, which is NULL.
+func sameOperand(a, b []Token) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Kind != b[i].Kind {
+			return false
+		}
+		switch a[i].Kind {
+		case TokenWord:
+			if notNames[strings.ToLower(a[i].Text)] || !strings.EqualFold(a[i].Text, b[i].Text) {
+				return false
+			}
+		case TokenQuotedIdent, TokenNumber, TokenString:
+			if a[i].Text != b[i].Text || a[i].Text == "''" {
+				return false
+			}
+		case TokenOperator:
+			if a[i].Text != "." || b[i].Text != "." {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
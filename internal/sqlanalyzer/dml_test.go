@@ -0,0 +1,55 @@
+package sqlanalyzer
+
+import "testing"
+
+func TestDMLScopes(t *testing.T) {
+	tests := []struct {
+		sql      string
+		want     []DMLScope
+		countSQL string
+	}{
+		{"SELECT * FROM emp", nil, ""},
+		{"UPDATE hr.emp e SET sal = 0", []DMLScope{{Statement: 1, Line: 1, Type: "UPDATE", Target: "hr.emp e", Unbounded: true, Countable: true}},
+			"SELECT COUNT(*) FROM hr.emp e"},
+		{"update emp set sal = sal * 1.1 where deptno = 10 returning sal into :s", []DMLScope{{Statement: 1, Line: 1, Type: "UPDATE", Target: "emp", Where: "deptno = 10", Countable: true}},
+			"SELECT COUNT(*) FROM emp WHERE deptno = 10"},
+		{"UPDATE emp SET sal = (SELECT MAX(sal) FROM emp WHERE 1=1) WHERE 1 = 1", []DMLScope{{Statement: 1, Line: 1, Type: "UPDATE", Target: "emp", Where: "1 = 1", Unbounded: true, Countable: true}},
+			"SELECT COUNT(*) FROM emp WHERE 1 = 1"},
+		{"DELETE FROM emp", []DMLScope{{Statement: 1, Line: 1, Type: "DELETE", Target: "emp", Unbounded: true, Countable: true}}, "SELECT COUNT(*) FROM emp"},
+		{"delete /*+ parallel */ emp x where x.id = x.id", []DMLScope{{Statement: 1, Line: 1, Type: "DELETE", Target: "emp x", Where: "x.id = x.id", Unbounded: true, Countable: true}}, ""},
+		{"DELETE FROM emp WHERE id = :id OR 'a' = 'a'", []DMLScope{{Statement: 1, Line: 1, Type: "DELETE", Target: "emp", Where: "id = :id OR 'a' = 'a'", Unbounded: true}}, ""},
+		{"DELETE FROM emp WHERE (1=1 AND 2>1) AND TRUE", []DMLScope{{Statement: 1, Line: 1, Type: "DELETE", Target: "emp", Where: "(1=1 AND 2>1) AND TRUE", Unbounded: true, Countable: true}}, ""},
+		{"DELETE FROM emp WHERE 1=1 AND id = 5", []DMLScope{{Statement: 1, Line: 1, Type: "DELETE", Target: "emp", Where: "1=1 AND id = 5", Countable: true}}, ""},
+		{"DELETE FROM emp WHERE NULL IS NULL", []DMLScope{{Statement: 1, Line: 1, Type: "DELETE", Target: "emp", Where: "NULL IS NULL", Unbounded: true, Countable: true}}, ""},
+		{"DELETE FROM emp WHERE '' = ''", []DMLScope{{Statement: 1, Line: 1, Type: "DELETE", Target: "emp", Where: "'' = ''", Countable: true}}, ""},
+		{"DELETE FROM emp WHERE id IN (1, 2)", []DMLScope{{Statement: 1, Line: 1, Type: "DELETE", Target: "emp", Where: "id IN (1, 2)", Countable: true}},
+			"SELECT COUNT(*) FROM emp WHERE id IN (1, 2)"},
+		{"DELETE FROM emp e WHERE NOT EXISTS (SELECT 1 FROM dept d WHERE d.id = e.dept_id)",
+			[]DMLScope{{Statement: 1, Line: 1, Type: "DELETE", Target: "emp e", Where: "NOT EXISTS (SELECT 1 FROM dept d WHERE d.id = e.dept_id)", Countable: true}}, ""},
+		{"UPDATE emp SET sal = 0 WHERE sal > ALL (SELECT sal FROM mgr) AND (deptno = 10 OR deptno = 20)",
+			[]DMLScope{{Statement: 1, Line: 1, Type: "UPDATE", Target: "emp", Where: "sal > ALL (SELECT sal FROM mgr) AND (deptno = 10 OR deptno = 20)", Countable: true}}, ""},
+		{"DELETE FROM emp WHERE id IN (SELECT f(id) FROM old)", []DMLScope{{Statement: 1, Line: 1, Type: "DELETE", Target: "emp", Where: "id IN (SELECT f(id) FROM old)"}}, ""},
+		{"DELETE FROM emp WHERE f(id) = f(id)", []DMLScope{{Statement: 1, Line: 1, Type: "DELETE", Target: "emp", Where: "f(id) = f(id)"}}, ""},
+		{"DELETE FROM emp PARTITION (p1)", []DMLScope{{Statement: 1, Line: 1, Type: "DELETE", Target: "emp PARTITION (p1)", Unbounded: true}}, ""},
+		{"MERGE INTO emp e USING new_emp n ON (1 = 1) WHEN MATCHED THEN UPDATE SET e.sal = n.sal",
+			[]DMLScope{{Statement: 1, Line: 1, Type: "MERGE", Target: "emp e", Where: "1 = 1", Unbounded: true, Countable: true}}, "SELECT COUNT(*) FROM emp e"},
+		{"MERGE INTO emp e USING new_emp n ON (e.id = n.id) WHEN MATCHED THEN UPDATE SET e.sal = n.sal",
+			[]DMLScope{{Statement: 1, Line: 1, Type: "MERGE", Target: "emp e", Where: "e.id = n.id", Countable: true}}, ""},
+		{"SELECT 1 FROM dual;\nDELETE FROM t;\nBEGIN DELETE FROM u; END;", []DMLScope{{Statement: 2, Line: 2, Type: "DELETE", Target: "t", Unbounded: true, Countable: true}}, ""},
+	}
+	for _, tt := range tests {
+		got := DMLScopes(tt.sql)
+		if len(got) != len(tt.want) {
+			t.Errorf("DMLScopes(%q) = %+v, want %+v", tt.sql, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("DMLScopes(%q)[%d] = %+v, want %+v", tt.sql, i, got[i], tt.want[i])
+			}
+		}
+		if tt.countSQL != "" && got[0].CountSQL() != tt.countSQL {
+			t.Errorf("CountSQL(%q) = %q, want %q", tt.sql, got[0].CountSQL(), tt.countSQL)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package sqlanalyzer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind classifies a Token.
+type TokenKind int
+
+const (
+	TokenSpace       TokenKind = iota // whitespace
+	TokenWord                         // identifier or keyword: letter followed by letters, digits, _, $ or #
+	TokenQuotedIdent                  // "Quoted Identifier"
+	TokenString                       // 'text', N'text', q'[text]', nq'{text}'
+	TokenNumber                       // 42, 3.14, .5, 1e-3, 2.5f
+	TokenBind                         // :name or :1
+	TokenComment                      // -- line comment or /* block comment */
+	TokenHint                         // optimizer hint: /*+ ... */ or --+ ...
+	TokenOperator                     // operators and punctuation: ; ( ) , := => || <= etc.
+)
+
+// Token is one lexical element of Oracle SQL or PL/SQL. Lex returns every byte of its input in some token, so
+// concatenating the tokens' Text gives the input back.
+type Token struct {
+	Kind TokenKind
+	Text string
+	Pos  int // byte offset in the input
+	Line int // line of the first byte, from 1
+}
+
+// Significant reports whether the token is part of the statement itself (not whitespace, a comment or a hint).
+func (t Token) Significant() bool {
+	return t.Kind != TokenSpace && t.Kind != TokenComment && t.Kind != TokenHint
+}
+
+// IsWord reports whether the token is the (unquoted, case-insensitive) word w.
+func (t Token) IsWord(w string) bool {
+	return t.Kind == TokenWord && strings.EqualFold(t.Text, w)
+}
+
+// multiCharOperators are the operators of more than one character, longest first where they overlap.
+var multiCharOperators = []string{":=", "=>", "||", "**", "..", "<<", ">>", "<=", ">=", "<>", "!=", "^=", "~="}
+
+// qQuoteClosers maps the opening delimiters of q-quote literals that have a distinct closing one.
+var qQuoteClosers = map[rune]rune{'[': ']', '{': '}', '(': ')', '<': '>'}
+
+// Lex splits sql into tokens. It never fails: an unterminated string, quoted identifier or comment extends to the
+// end of the input, and any other character is an operator token of its own.
+func Lex(sql string) []Token {
+	var tokens []Token
+	line := 1
+	for pos := 0; pos < len(sql); {
+		kind, n := lexOne(sql[pos:])
+		text := sql[pos : pos+n]
+		tokens = append(tokens, Token{Kind: kind, Text: text, Pos: pos, Line: line})
+		line += strings.Count(text, "\n")
+		pos += n
+	}
+	return tokens
+}
+
+// lexOne returns the kind and byte length of the token at the start of s (s is not empty).
+func lexOne(s string) (TokenKind, int) {
+	r, size := utf8.DecodeRuneInString(s)
+	switch {
+	case unicode.IsSpace(r):
+		n := size
+		for n < len(s) {
+			r, size := utf8.DecodeRuneInString(s[n:])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			n += size
+		}
+		return TokenSpace, n
+	case strings.HasPrefix(s, "--"):
+		n := strings.IndexByte(s, '\n')
+		if n < 0 {
+			n = len(s)
+		}
+		if strings.HasPrefix(s, "--+") {
+			return TokenHint, n
+		}
+		return TokenComment, n
+	case strings.HasPrefix(s, "/*"):
+		n := strings.Index(s[2:], "*/")
+		if n < 0 {
+			n = len(s)
+		} else {
+			n += 4
+		}
+		if strings.HasPrefix(s, "/*+") {
+			return TokenHint, n
+		}
+		return TokenComment, n
+	case r == '\'':
+		return TokenString, quotedLength(s, '\'')
+	case r == '"':
+		n := strings.IndexByte(s[1:], '"')
+		if n < 0 {
+			return TokenQuotedIdent, len(s)
+		}
+		return TokenQuotedIdent, n + 2
+	case isDigit(r) || (r == '.' && len(s) > 1 && isDigit(rune(s[1]))):
+		return TokenNumber, numberLength(s)
+	case r == ':' && len(s) > 1 && (isIdentStart(s[1:]) || isDigit(rune(s[1]))):
+		return TokenBind, 1 + wordLength(s[1:])
+	case isIdentStart(s):
+		n := wordLength(s)
+		if s[n:] != "" && s[n] == '\'' {
+			switch strings.ToLower(s[:n]) {
+			case "n":
+				return TokenString, n + quotedLength(s[n:], '\'')
+			case "q", "nq":
+				return TokenString, n + qQuoteLength(s[n:])
+			}
+		}
+		return TokenWord, n
+	}
+	for _, op := range multiCharOperators {
+		if strings.HasPrefix(s, op) {
+			return TokenOperator, len(op)
+		}
+	}
+	return TokenOperator, size
+}
+
+// quotedLength returns the length of the literal quoted with q at the start of s; a doubled quote is an escaped one.
+func quotedLength(s string, q byte) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] == q {
+			if i+1 < len(s) && s[i+1] == q {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// qQuoteLength returns the length of the q-quote literal body '<d>text<d>' at the start of s.
+func qQuoteLength(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	open, size := utf8.DecodeRuneInString(s[1:])
+	closer := open
+	if c, ok := qQuoteClosers[open]; ok {
+		closer = c
+	}
+	end := string(closer) + "'"
+	n := strings.Index(s[1+size:], end)
+	if n < 0 {
+		return len(s)
+	}
+	return 1 + size + n + len(end)
+}
+
+// numberLength returns the length of the numeric literal at the start of s.
+func numberLength(s string) int {
+	n := 0
+	digits := func() {
+		for n < len(s) && isDigit(rune(s[n])) {
+			n++
+		}
+	}
+	digits()
+	// A second dot is the range operator (1..10), not a fraction
+	if n < len(s) && s[n] == '.' && !strings.HasPrefix(s[n:], "..") {
+		n++
+		digits()
+	}
+	if n < len(s) && (s[n] == 'e' || s[n] == 'E') {
+		m := n + 1
+		if m < len(s) && (s[m] == '+' || s[m] == '-') {
+			m++
+		}
+		if m < len(s) && isDigit(rune(s[m])) {
+			n = m
+			digits()
+		}
+	}
+	if n < len(s) && strings.ContainsRune("fFdD", rune(s[n])) && (n+1 == len(s) || !isIdentStart(s[n+1:])) {
+		n++
+	}
+	return n
+}
+
+// wordLength returns the length of the identifier characters at the start of s.
+func wordLength(s string) int {
+	n := 0
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '$' && r != '#' {
+			break
+		}
+		n += size
+	}
+	return n
+}
+
+// isIdentStart reports whether s starts with a letter.
+func isIdentStart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsLetter(r)
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
@@ -0,0 +1,205 @@
+package sqlanalyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Token // significant tokens, comments and hints only (Pos and Line not compared)
+	}{
+		{
+			name:  "words numbers and operators",
+			input: "SELECT a1, 3.5e-2 FROM t WHERE x <> :id",
+			want: []Token{
+				{Kind: TokenWord, Text: "SELECT"}, {Kind: TokenWord, Text: "a1"}, {Kind: TokenOperator, Text: ","},
+				{Kind: TokenNumber, Text: "3.5e-2"}, {Kind: TokenWord, Text: "FROM"}, {Kind: TokenWord, Text: "t"},
+				{Kind: TokenWord, Text: "WHERE"}, {Kind: TokenWord, Text: "x"}, {Kind: TokenOperator, Text: "<>"},
+				{Kind: TokenBind, Text: ":id"},
+			},
+		},
+		{
+			name:  "identifier characters",
+			input: "sys$user#1_x",
+			want:  []Token{{Kind: TokenWord, Text: "sys$user#1_x"}},
+		},
+		{
+			name:  "quoted identifier",
+			input: `"DROP" "a""b`,
+			want:  []Token{{Kind: TokenQuotedIdent, Text: `"DROP"`}, {Kind: TokenQuotedIdent, Text: `"a"`}, {Kind: TokenQuotedIdent, Text: `"b`}},
+		},
+		{
+			name:  "string with escaped quote",
+			input: "'it''s -- not /* a */ comment'",
+			want:  []Token{{Kind: TokenString, Text: "'it''s -- not /* a */ comment'"}},
+		},
+		{
+			name:  "national string",
+			input: "n'x;y' N'z'",
+			want:  []Token{{Kind: TokenString, Text: "n'x;y'"}, {Kind: TokenString, Text: "N'z'"}},
+		},
+		{
+			name:  "q-quote brackets",
+			input: "q'[it's [a] 'b']'",
+			want:  []Token{{Kind: TokenString, Text: "q'[it's [a] 'b']'"}},
+		},
+		{
+			name:  "q-quote same delimiter",
+			input: "Q'!a'b!' nq'#c#'",
+			want:  []Token{{Kind: TokenString, Text: "Q'!a'b!'"}, {Kind: TokenString, Text: "nq'#c#'"}},
+		},
+		{
+			name:  "q as identifier",
+			input: "q.x",
+			want:  []Token{{Kind: TokenWord, Text: "q"}, {Kind: TokenOperator, Text: "."}, {Kind: TokenWord, Text: "x"}},
+		},
+		{
+			name:  "comments",
+			input: "a -- line\n/* block\n */b",
+			want: []Token{
+				{Kind: TokenWord, Text: "a"}, {Kind: TokenComment, Text: "-- line"},
+				{Kind: TokenComment, Text: "/* block\n */"}, {Kind: TokenWord, Text: "b"},
+			},
+		},
+		{
+			name:  "hints",
+			input: "SELECT /*+ FULL(t) */ --+ INDEX(t)\n1",
+			want: []Token{
+				{Kind: TokenWord, Text: "SELECT"}, {Kind: TokenHint, Text: "/*+ FULL(t) */"},
+				{Kind: TokenHint, Text: "--+ INDEX(t)"}, {Kind: TokenNumber, Text: "1"},
+			},
+		},
+		{
+			name:  "unterminated comment",
+			input: "a /* open",
+			want:  []Token{{Kind: TokenWord, Text: "a"}, {Kind: TokenComment, Text: "/* open"}},
+		},
+		{
+			name:  "range is not a fraction",
+			input: "1..10",
+			want:  []Token{{Kind: TokenNumber, Text: "1"}, {Kind: TokenOperator, Text: ".."}, {Kind: TokenNumber, Text: "10"}},
+		},
+		{
+			name:  "number suffixes",
+			input: "2.5f 3d 4from",
+			want: []Token{
+				{Kind: TokenNumber, Text: "2.5f"}, {Kind: TokenNumber, Text: "3d"},
+				{Kind: TokenNumber, Text: "4"}, {Kind: TokenWord, Text: "from"},
+			},
+		},
+		{
+			name:  "PL/SQL operators",
+			input: "x:=a||b=>c**2",
+			want: []Token{
+				{Kind: TokenWord, Text: "x"}, {Kind: TokenOperator, Text: ":="}, {Kind: TokenWord, Text: "a"},
+				{Kind: TokenOperator, Text: "||"}, {Kind: TokenWord, Text: "b"}, {Kind: TokenOperator, Text: "=>"},
+				{Kind: TokenWord, Text: "c"}, {Kind: TokenOperator, Text: "**"}, {Kind: TokenNumber, Text: "2"},
+			},
+		},
+		{
+			name:  "unicode identifiers",
+			input: "名前 'é'",
+			want:  []Token{{Kind: TokenWord, Text: "名前"}, {Kind: TokenString, Text: "'é'"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []Token
+			for _, tok := range Lex(tt.input) {
+				if tok.Kind != TokenSpace {
+					got = append(got, Token{Kind: tok.Kind, Text: tok.Text})
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Lex(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Lex(%q) token %d = %+v, want %+v", tt.input, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLex_PositionsAndLines(t *testing.T) {
+	sql := "SELECT 1\n  FROM /* a\nb */ dual"
+	var dual Token
+	for _, tok := range Lex(sql) {
+		if tok.IsWord("DUAL") {
+			dual = tok
+		}
+	}
+	if dual.Line != 3 || dual.Pos != strings.Index(sql, "dual") {
+		t.Errorf("dual token = %+v, want line 3 at offset %d", dual, strings.Index(sql, "dual"))
+	}
+}
+
+func TestAnalyzer_TokensModeIgnoresQuotedAndLiterals(t *testing.T) {
+	analyzer := NewAnalyzer([]string{"drop", "truncate"}, "tokens")
+	for _, sql := range []string{
+		`SELECT "DROP" FROM t`,
+		`SELECT q'[drop table x]' FROM dual`,
+		`SELECT nq'{truncate}' FROM dual`,
+		`SELECT '-- drop' FROM dual`,
+		`SELECT /*+ drop */ 1 FROM dual`,
+	} {
+		if r := analyzer.Analyze(sql); r.IsDangerous {
+			t.Errorf("Analyze(%q) matched %v, want no match", sql, r.MatchedKeywords)
+		}
+	}
+	if r := analyzer.Analyze("SELECT q'[x]' FROM dual; DROP TABLE t"); !r.IsDangerous {
+		t.Error("DROP after a q-quote literal should match")
+	}
+}
+
+// FuzzLex checks that lexing is lossless and that splitting never panics or yields empty statements.
+func FuzzLex(f *testing.F) {
+	for _, seed := range []string{
+		"SELECT 1 FROM dual;",
+		"BEGIN NULL; END;\n/\nSELECT 'a;b' FROM dual;",
+		"q'[unterminated",
+		"nq'",
+		"q'",
+		"'it''s'",
+		`"quoted`,
+		"/* open",
+		"--+ hint",
+		":1 :x :\"y\"",
+		"1..2 .5e+ 3e 4f",
+		"CREATE OR REPLACE PROCEDURE p AS BEGIN NULL; END;\n/\n/\n",
+		"WITH FUNCTION f RETURN NUMBER IS BEGIN RETURN 1; END; SELECT f FROM dual\n/",
+		"\xff\xfe invalid utf8 '\xff'",
+		"名前;\r\n/",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, sql string) {
+		tokens := Lex(sql)
+		var b strings.Builder
+		line := 1
+		for _, tok := range tokens {
+			if tok.Text == "" {
+				t.Fatalf("empty token at %d", tok.Pos)
+			}
+			if tok.Pos != b.Len() || tok.Line != line {
+				t.Fatalf("token %+v: want pos %d line %d", tok, b.Len(), line)
+			}
+			b.WriteString(tok.Text)
+			line += strings.Count(tok.Text, "\n")
+		}
+		if b.String() != sql {
+			t.Fatalf("tokens concatenate to %q, want %q", b.String(), sql)
+		}
+		for _, st := range SplitStatements(sql) {
+			if st.Text == "" || st.Line < 1 || st.Line > line {
+				t.Fatalf("bad statement %+v", st)
+			}
+		}
+		NewAnalyzer([]string{"drop"}, "tokens").Analyze(sql)
+	})
+}
@@ -0,0 +1,195 @@
+package sqlanalyzer
+
+import (
+	"strings"
+)
+
+// ObjectRef is a schema object named in a statement. Unquoted names are upper-cased, as Oracle stores them;
+// quoted names are kept as written.
+type ObjectRef struct {
+	Schema string // "" when the name is not qualified
+	Name   string
+}
+
+// String returns SCHEMA.NAME, or NAME when the schema is not given.
+func (o ObjectRef) String() string {
+	if o.Schema == "" {
+		return o.Name
+	}
+	return o.Schema + "." + o.Name
+}
+
+// objectTypes are the words after CREATE/ALTER/DROP that are followed by the object's name.
+var objectTypes = map[string]bool{
+	"table": true, "view": true, "index": true, "sequence": true, "synonym": true, "procedure": true,
+	"function": true, "package": true, "trigger": true, "type": true, "user": true, "role": true,
+	"tablespace": true, "directory": true, "library": true, "cluster": true, "context": true,
+	"dimension": true, "profile": true, "link": true,
+}
+
+// notNames are Oracle reserved words and the keywords that may follow the places where StatementObjects expects
+// a name (FOR UPDATE OF, THEN UPDATE SET, ON DELETE CASCADE, FROM TABLE(...), ...); they are never object names.
+var notNames = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`access add all alter and any as asc audit between by char check cluster column
+		comment compress connect create current date decimal default delete desc distinct drop else exclusive exists
+		file float for from grant group having identified immediate in increment index initial insert integer
+		intersect into is level like lock long maxextents minus mlslabel mode modify noaudit nocompress not nowait
+		null number of offline on online option or order pctfree prior public raw rename resource revoke row rowid
+		rownum rows select session set share size smallint start successful synonym sysdate table then to trigger
+		uid union unique update user validate values varchar varchar2 view whenever where with
+		join inner left right full cross natural outer using partition sample pivot unpivot lateral only if
+		cascade commit when wait skip fetch offset returning return log errors`) {
+		notNames[w] = true
+	}
+}
+
+// StatementObjects returns the schema objects a statement works on, best effort: the target of INSERT, UPDATE,
+// DELETE, MERGE, TRUNCATE, LOCK TABLE, CREATE/ALTER/DROP, RENAME, COMMENT ON and GRANT/REVOKE ... ON, and the
+// tables and views after FROM and JOIN (also inside subqueries and PL/SQL blocks). Each object is listed once,
+// in order of appearance; database links are dropped from names.
+func StatementObjects(sql string) []ObjectRef {
+	var sig []Token
+	for _, t := range Lex(sql) {
+		if t.Significant() {
+			sig = append(sig, t)
+		}
+	}
+	if len(sig) == 0 {
+		return nil
+	}
+	first := strings.ToLower(sig[0].Text)
+	var out []ObjectRef
+	seen := map[ObjectRef]bool{}
+	add := func(j int) (int, bool) {
+		ref, next, ok := objectName(sig, j, false)
+		if ok && !seen[ref] {
+			seen[ref] = true
+			out = append(out, ref)
+		}
+		return next, ok
+	}
+	onSeen := false
+	for i, t := range sig {
+		switch {
+		case t.IsWord("from") || t.IsWord("join"):
+			// FROM a x, b y: each name may have an alias; a name followed by "(" is a table function
+			j := i + 1
+			for {
+				ref, next, ok := objectName(sig, j, false)
+				if !ok || (next < len(sig) && sig[next].Text == "(") {
+					break
+				}
+				if !seen[ref] {
+					seen[ref] = true
+					out = append(out, ref)
+				}
+				j = next
+				if j < len(sig) && sig[j].Kind == TokenWord && !notNames[strings.ToLower(sig[j].Text)] {
+					j++
+				}
+				if !t.IsWord("from") || j >= len(sig) || sig[j].Text != "," {
+					break
+				}
+				j++
+			}
+		case t.IsWord("into"):
+			if first == "insert" || (i > 0 && (sig[i-1].IsWord("insert") || sig[i-1].IsWord("merge"))) {
+				add(i + 1)
+			}
+		case t.IsWord("update"), t.IsWord("delete"):
+			add(i + 1)
+		case t.IsWord("using"):
+			if first == "merge" {
+				if j := i + 1; j < len(sig) && sig[j].Text != "(" {
+					add(j)
+				}
+			}
+		case (t.IsWord("truncate") || t.IsWord("lock")) && i+1 < len(sig):
+			add(i + 2)
+		case i == 0 && (t.IsWord("create") || t.IsWord("alter") || t.IsWord("drop")):
+			for k := 1; k < len(sig) && k < 8; k++ {
+				if sig[k].Kind != TokenWord || !objectTypes[strings.ToLower(sig[k].Text)] {
+					continue
+				}
+				j := k + 1
+				for j < len(sig) && (sig[j].IsWord("body") || sig[j].IsWord("link") || sig[j].IsWord("view")) {
+					j++
+				}
+				if j+1 < len(sig) && sig[j].IsWord("if") {
+					j += 2 // IF EXISTS
+					if j < len(sig) && sig[j-1].IsWord("not") {
+						j++ // IF NOT EXISTS
+					}
+				}
+				add(j)
+				break
+			}
+		case i == 0 && t.IsWord("rename"):
+			if next, ok := add(1); ok && next < len(sig) && sig[next].IsWord("to") {
+				add(next + 1)
+			}
+		case t.IsWord("on") && !onSeen && (first == "grant" || first == "revoke" || first == "comment" || first == "create"):
+			onSeen = true
+			j := i + 1
+			column := j < len(sig) && sig[j].IsWord("column")
+			for j < len(sig) && (sig[j].IsWord("table") || sig[j].IsWord("column") || sig[j].IsWord("materialized") ||
+				sig[j].IsWord("view") || sig[j].IsWord("directory")) {
+				j++
+			}
+			ref, _, ok := objectName(sig, j, column)
+			if ok && !seen[ref] {
+				seen[ref] = true
+				out = append(out, ref)
+			}
+		}
+	}
+	return out
+}
+
+// objectName reads a [schema.]name at sig[j] and returns it with the index after it (and after any @dblink).
+// With column set, the name is [schema.]table.column and the table is returned.
+func objectName(sig []Token, j int, column bool) (ObjectRef, int, bool) {
+	var parts []string
+	for {
+		if j >= len(sig) || !isName(sig[j]) {
+			return ObjectRef{}, j, false
+		}
+		parts = append(parts, identifierText(sig[j]))
+		j++
+		if len(parts) == 3 || j+1 >= len(sig) || sig[j].Text != "." {
+			break
+		}
+		j++
+	}
+	if j < len(sig) && sig[j].Text == "@" {
+		j++
+		for j < len(sig) && (sig[j].Kind == TokenWord || sig[j].Kind == TokenQuotedIdent || sig[j].Text == ".") {
+			j++
+		}
+	}
+	if column && len(parts) > 1 {
+		parts = parts[:len(parts)-1]
+	}
+	switch len(parts) {
+	case 1:
+		return ObjectRef{Name: parts[0]}, j, true
+	default:
+		return ObjectRef{Schema: parts[0], Name: parts[1]}, j, true
+	}
+}
+
+// isName reports whether the token can be an object name.
+func isName(t Token) bool {
+	return t.Kind == TokenQuotedIdent || (t.Kind == TokenWord && !notNames[strings.ToLower(t.Text)])
+}
+
+// identifierText returns the name a token stands for: quoted identifiers without their quotes, other names
+// upper-cased.
+func identifierText(t Token) string {
+	if t.Kind == TokenQuotedIdent {
+		return strings.Trim(t.Text, `"`)
+	}
+	return strings.ToUpper(t.Text)
+}
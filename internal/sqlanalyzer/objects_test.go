@@ -0,0 +1,48 @@
+package sqlanalyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStatementObjects(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{"SELECT * FROM hr.employees e JOIN departments d ON d.id = e.dept_id", []string{"HR.EMPLOYEES", "DEPARTMENTS"}},
+		{"SELECT a FROM t1 x, \"Mixed\".t2 WHERE x.id IN (SELECT id FROM t3)", []string{"T1", "Mixed.T2", "T3"}},
+		{"SELECT * FROM TABLE(pkg.rows()) r, dual", nil},
+		{"SELECT * FROM emp FOR UPDATE OF sal NOWAIT", []string{"EMP"}},
+		{"INSERT INTO audit_log (id) VALUES (1)", []string{"AUDIT_LOG"}},
+		{"INSERT ALL INTO a VALUES (1) INTO b VALUES (2) SELECT * FROM dual", []string{"A", "B", "DUAL"}},
+		{"UPDATE hr.emp@remote SET sal = 0", []string{"HR.EMP"}},
+		{"DELETE FROM emp WHERE id = 1", []string{"EMP"}},
+		{"DELETE emp", []string{"EMP"}},
+		{"MERGE INTO tgt t USING src s ON (t.id = s.id) WHEN MATCHED THEN UPDATE SET t.v = s.v", []string{"TGT", "SRC"}},
+		{"TRUNCATE TABLE sales", []string{"SALES"}},
+		{"DROP TABLE IF EXISTS hr.old_emp PURGE", []string{"HR.OLD_EMP"}},
+		{"DROP USER scott CASCADE", []string{"SCOTT"}},
+		{"DROP PUBLIC DATABASE LINK remote_db", []string{"REMOTE_DB"}},
+		{"CREATE OR REPLACE PACKAGE BODY app.pkg AS BEGIN NULL; END;", []string{"APP.PKG"}},
+		{"CREATE UNIQUE INDEX emp_ix ON hr.emp (id)", []string{"EMP_IX", "HR.EMP"}},
+		{"CREATE TABLE t2 AS SELECT * FROM t1", []string{"T2", "T1"}},
+		{"CREATE OR REPLACE TRIGGER trg BEFORE INSERT OR UPDATE ON emp FOR EACH ROW BEGIN NULL; END;", []string{"TRG", "EMP"}},
+		{"ALTER SYSTEM SET open_cursors = 500", nil},
+		{"GRANT SELECT, UPDATE ON hr.emp TO app", []string{"HR.EMP"}},
+		{"COMMENT ON COLUMN hr.emp.sal IS 'x'", []string{"HR.EMP"}},
+		{"RENAME old_t TO new_t", []string{"OLD_T", "NEW_T"}},
+		{"LOCK TABLE emp IN EXCLUSIVE MODE", []string{"EMP"}},
+		{"BEGIN DELETE FROM log_t; UPDATE cfg SET v = 1; END;", []string{"LOG_T", "CFG"}},
+		{"SELECT 'FROM secret' FROM dual -- FROM other", []string{"DUAL"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, obj := range StatementObjects(tt.sql) {
+			got = append(got, obj.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("StatementObjects(%q) = %v, want %v", tt.sql, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package sqlanalyzer
+
+import (
+	"strings"
+)
+
+// Statement is one statement of a script, as split by SplitStatements.
+type Statement struct {
+	// Text is the statement, trimmed, with leading comments kept. SQL statements lose their terminating ";";
+	// PL/SQL keeps everything up to the "/" line, including the final "END;".
+	Text string
+	// Line is the line of the script on which Text starts, from 1.
+	Line int
+	// PLSQL is set for anonymous blocks and CREATE PROCEDURE/FUNCTION/PACKAGE/TRIGGER/TYPE (and WITH FUNCTION queries).
+	PLSQL bool
+}
+
+// SplitStatements splits a script the way SQL*Plus and SQLcl do. A SQL statement ends at a ";" outside strings,
+// quoted identifiers and comments, or at a line holding only "/". PL/SQL contains semicolons, so a block or
+// PL/SQL unit runs to the next "/" line or the end of the script. A "/" line after a terminated statement, and
+// statements that are only comments, are dropped.
+func SplitStatements(sql string) []Statement {
+	tokens := Lex(sql)
+	var out []Statement
+	start := -1      // index of the first token of the current statement (whitespace skipped)
+	plsql := false   // the current statement is PL/SQL
+	decided := false // plsql has been set from the statement's first words
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		if text := strings.TrimSpace(joinTokens(tokens[start:end])); text != "" && hasSignificant(tokens[start:end]) {
+			out = append(out, Statement{Text: text, Line: tokens[start].Line, PLSQL: plsql})
+		}
+		start, plsql, decided = -1, false, false
+	}
+	for i, t := range tokens {
+		if t.Kind == TokenOperator && t.Text == "/" && isSlashLine(tokens, i) {
+			flush(i)
+			continue
+		}
+		if start < 0 {
+			if t.Kind == TokenSpace {
+				continue
+			}
+			start = i
+		}
+		if !decided && t.Significant() {
+			plsql, decided = startsPLSQL(tokens[i:]), true
+		}
+		if !plsql && t.Kind == TokenOperator && t.Text == ";" {
+			flush(i)
+		}
+	}
+	flush(len(tokens))
+	return out
+}
+
+// startsPLSQL reports whether the statement beginning at tokens[0] is PL/SQL: BEGIN, DECLARE, a <<label>>
+// before them, CREATE [OR REPLACE] [[NON]EDITIONABLE] PROCEDURE/FUNCTION/PACKAGE/TRIGGER/TYPE, or
+// WITH FUNCTION/PROCEDURE.
+func startsPLSQL(tokens []Token) bool {
+	words := significantWords(tokens, 6)
+	if len(words) == 0 {
+		return false
+	}
+	switch words[0] {
+	case "begin", "declare", "<<":
+		return true
+	case "with":
+		return len(words) > 1 && (words[1] == "function" || words[1] == "procedure")
+	case "create":
+		i := 1
+		if len(words) > 2 && words[1] == "or" && words[2] == "replace" {
+			i = 3
+		}
+		if i < len(words) && (words[i] == "editionable" || words[i] == "noneditionable") {
+			i++
+		}
+		if i < len(words) {
+			switch words[i] {
+			case "procedure", "function", "package", "trigger", "type":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// significantWords returns up to n significant tokens from the start of tokens, lower-cased.
+func significantWords(tokens []Token, n int) []string {
+	var words []string
+	for _, t := range tokens {
+		if len(words) == n {
+			break
+		}
+		if t.Significant() {
+			words = append(words, strings.ToLower(t.Text))
+		}
+	}
+	return words
+}
+
+// isSlashLine reports whether the "/" token tokens[i] is alone on its line (apart from whitespace).
+func isSlashLine(tokens []Token, i int) bool {
+	lineBreak := func(j int, edge bool) bool {
+		return tokens[j].Kind == TokenSpace && (edge || strings.Contains(tokens[j].Text, "\n"))
+	}
+	before := i == 0 || lineBreak(i-1, i == 1)
+	after := i == len(tokens)-1 || lineBreak(i+1, i == len(tokens)-2)
+	return before && after
+}
+
+// firstSignificant returns the index of the first significant token at or after from, or -1.
+func firstSignificant(tokens []Token, from int) int {
+	for i := from; i < len(tokens); i++ {
+		if tokens[i].Significant() {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasSignificant reports whether any of the tokens is significant.
+func hasSignificant(tokens []Token) bool {
+	return firstSignificant(tokens, 0) >= 0
+}
+
+// joinTokens concatenates the tokens' text.
+func joinTokens(tokens []Token) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString(t.Text)
+	}
+	return b.String()
+}
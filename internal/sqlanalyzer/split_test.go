@@ -0,0 +1,129 @@
+package sqlanalyzer
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the .golden files of testdata/corpus")
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Statement
+	}{
+		{
+			name:  "one line two statements",
+			input: "SELECT 1 FROM dual; SELECT 2 FROM dual",
+			want:  []Statement{{Text: "SELECT 1 FROM dual", Line: 1}, {Text: "SELECT 2 FROM dual", Line: 1}},
+		},
+		{
+			name:  "semicolon in q-quote",
+			input: "SELECT q'[a;b]' FROM dual;\nSELECT 2 FROM dual;",
+			want:  []Statement{{Text: "SELECT q'[a;b]' FROM dual", Line: 1}, {Text: "SELECT 2 FROM dual", Line: 2}},
+		},
+		{
+			name:  "block runs to slash line",
+			input: "BEGIN\n  NULL;\nEND;\n/\nSELECT 1 FROM dual;",
+			want:  []Statement{{Text: "BEGIN\n  NULL;\nEND;", Line: 1, PLSQL: true}, {Text: "SELECT 1 FROM dual", Line: 5}},
+		},
+		{
+			name:  "slash line ends SQL",
+			input: "UPDATE t SET a = 1\n/\n",
+			want:  []Statement{{Text: "UPDATE t SET a = 1", Line: 1}},
+		},
+		{
+			name:  "division is not a slash line",
+			input: "SELECT a\n  / b FROM t",
+			want:  []Statement{{Text: "SELECT a\n  / b FROM t", Line: 1}},
+		},
+		{
+			name:  "trigger",
+			input: "CREATE TRIGGER trg BEFORE INSERT ON t FOR EACH ROW BEGIN :new.a := 1; END;",
+			want:  []Statement{{Text: "CREATE TRIGGER trg BEFORE INSERT ON t FOR EACH ROW BEGIN :new.a := 1; END;", Line: 1, PLSQL: true}},
+		},
+		{
+			name:  "with function",
+			input: "WITH FUNCTION f RETURN NUMBER IS BEGIN RETURN 1; END;\nSELECT f FROM dual\n/",
+			want:  []Statement{{Text: "WITH FUNCTION f RETURN NUMBER IS BEGIN RETURN 1; END;\nSELECT f FROM dual", Line: 1, PLSQL: true}},
+		},
+		{
+			name:  "comment only statements dropped",
+			input: "-- header\nSELECT 1 FROM dual; -- trailing\n",
+			want:  []Statement{{Text: "-- header\nSELECT 1 FROM dual", Line: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitStatements(tt.input)
+			if len(got) != len(tt.want) {
+				t.Fatalf("SplitStatements(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("statement %d = %+v, want %+v", i+1, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestCorpus splits and analyzes each testdata/corpus/*.sql script and compares the outcome with the .golden file
+// next to it. Run with -update to rewrite the golden files after an intended change, then review the diff.
+func TestCorpus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "corpus", "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no corpus files")
+	}
+	analyzer := NewAnalyzer([]string{"drop", "truncate", "delete", "alter system", "grant"}, "tokens")
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".sql")
+		t.Run(name, func(t *testing.T) {
+			sql, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := describeScript(analyzer, string(sql))
+			golden := strings.TrimSuffix(file, ".sql") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -run TestCorpus -update to create it)", err)
+			}
+			if got != string(want) {
+				t.Errorf("%s: got\n%s\nwant\n%s", file, got, want)
+			}
+		})
+	}
+}
+
+// describeScript renders the analysis and statements of a script in the golden file format.
+func describeScript(analyzer *Analyzer, sql string) string {
+	r := analyzer.Analyze(sql)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# multi_statement=%t contains_plsql=%t plsql_creation_ddl=%t ddl=%t explain_plan=%t\n",
+		r.IsMultiStatement, r.ContainsPLSQL, r.IsPLSQLCreationDDL, r.IsDDL, r.IsExplainPlan)
+	fmt.Fprintf(&b, "# single_statement_block=%t matched=%s\n", IsSingleStatementBlock(sql), strings.Join(r.MatchedKeywords, ","))
+	for i, st := range SplitStatements(sql) {
+		kind := "sql"
+		if st.PLSQL {
+			kind = "plsql"
+		}
+		fmt.Fprintf(&b, "--- %d line=%d %s %s\n%s\n", i+1, st.Line, kind, GetStatementType(st.Text), st.Text)
+	}
+	return b.String()
+}
@@ -0,0 +1 @@
+SELECT * FROM employees WHERE department_id = 10
\ No newline at end of file
@@ -0,0 +1 @@
+SELECT * FROM employees;
\ No newline at end of file
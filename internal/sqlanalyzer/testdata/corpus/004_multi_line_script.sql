@@ -0,0 +1,4 @@
+INSERT INTO t (id) VALUES (1);
+INSERT INTO t (id) VALUES (2);
+UPDATE t SET id = id + 1;
+COMMIT;
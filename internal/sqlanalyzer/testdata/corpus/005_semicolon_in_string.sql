@@ -0,0 +1,2 @@
+INSERT INTO notes (txt) VALUES ('first; second');
+SELECT COUNT(*) FROM notes;
\ No newline at end of file
@@ -0,0 +1,2 @@
+SELECT 'it''s; fine' AS s FROM dual;
+DELETE FROM t WHERE name = 'O''Brien';
\ No newline at end of file
@@ -0,0 +1,2 @@
+SELECT q'[it's; a 'drop' test]' FROM dual;
+SELECT 2 FROM dual;
\ No newline at end of file
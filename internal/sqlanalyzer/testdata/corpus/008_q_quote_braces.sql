@@ -0,0 +1 @@
+SELECT Q'{ ; } ' }' AS v FROM dual;
\ No newline at end of file
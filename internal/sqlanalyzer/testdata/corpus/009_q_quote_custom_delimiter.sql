@@ -0,0 +1 @@
+SELECT q'!don't; stop!' FROM dual; SELECT nq'#national; text#' FROM dual
\ No newline at end of file
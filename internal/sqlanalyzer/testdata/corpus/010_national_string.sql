@@ -0,0 +1 @@
+SELECT N'truncate; me' FROM dual
\ No newline at end of file
@@ -0,0 +1 @@
+SELECT "DROP", "select;from" FROM "My Table"
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT 1 -- no split here; really
+FROM dual;
+SELECT 2 FROM dual;
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT /* ; drop table x; */ 1 FROM dual;
+/* whole ; line */
+SELECT 3 FROM dual
\ No newline at end of file
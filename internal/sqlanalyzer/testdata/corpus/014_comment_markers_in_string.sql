@@ -0,0 +1,2 @@
+SELECT '-- not a comment', '/* nor this */' FROM dual;
+DROP TABLE audit_tmp;
\ No newline at end of file
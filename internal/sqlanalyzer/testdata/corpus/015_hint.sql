@@ -0,0 +1,3 @@
+SELECT /*+ INDEX(e emp_idx) */ * FROM employees e;
+SELECT --+ FULL(d)
+ * FROM departments d;
\ No newline at end of file
@@ -0,0 +1,3 @@
+BEGIN
+  DBMS_OUTPUT.PUT_LINE('hello; world');
+END;
\ No newline at end of file
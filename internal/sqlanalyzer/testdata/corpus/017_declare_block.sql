@@ -0,0 +1,10 @@
+DECLARE
+  n NUMBER := 0;
+BEGIN
+  FOR i IN 1..10 LOOP
+    n := n + i;
+  END LOOP;
+  IF n > 10 THEN
+    NULL;
+  END IF;
+END;
\ No newline at end of file
@@ -0,0 +1,5 @@
+BEGIN
+  NULL;
+END;
+/
+SELECT 1 FROM dual;
\ No newline at end of file
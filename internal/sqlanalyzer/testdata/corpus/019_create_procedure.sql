@@ -0,0 +1,4 @@
+CREATE OR REPLACE PROCEDURE raise_salary(p_id NUMBER, p_pct NUMBER) AS
+BEGIN
+  UPDATE employees SET salary = salary * (1 + p_pct / 100) WHERE employee_id = p_id;
+END raise_salary;
\ No newline at end of file
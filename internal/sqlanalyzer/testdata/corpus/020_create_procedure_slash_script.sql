@@ -0,0 +1,9 @@
+CREATE TABLE log_t (msg VARCHAR2(100));
+
+CREATE OR REPLACE PROCEDURE log_it(p VARCHAR2) IS
+BEGIN
+  INSERT INTO log_t VALUES (p);
+END;
+/
+
+GRANT EXECUTE ON log_it TO app_user;
@@ -0,0 +1,10 @@
+CREATE OR REPLACE PACKAGE pkg AS
+  PROCEDURE p;
+  FUNCTION f RETURN NUMBER;
+END pkg;
+/
+CREATE OR REPLACE PACKAGE BODY pkg AS
+  PROCEDURE p IS BEGIN NULL; END;
+  FUNCTION f RETURN NUMBER IS BEGIN RETURN CASE WHEN 1 = 1 THEN 1 ELSE 0 END; END;
+END pkg;
+/
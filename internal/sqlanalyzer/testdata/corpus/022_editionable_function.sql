@@ -0,0 +1 @@
+CREATE OR REPLACE EDITIONABLE FUNCTION f(x DATE) RETURN DATE AS BEGIN RETURN x; END f;
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE OR REPLACE TRIGGER emp_bi
+BEFORE INSERT ON employees
+FOR EACH ROW
+BEGIN
+  :new.created := SYSDATE;
+END;
+/
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE OR REPLACE TYPE point AS OBJECT (
+  x NUMBER,
+  y NUMBER,
+  MEMBER FUNCTION dist RETURN NUMBER
+);
+/
+CREATE OR REPLACE TYPE BODY point AS
+  MEMBER FUNCTION dist RETURN NUMBER IS
+  BEGIN
+    RETURN SQRT(x * x + y * y);
+  END;
+END;
+/
\ No newline at end of file
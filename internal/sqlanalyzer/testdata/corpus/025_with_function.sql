@@ -0,0 +1,6 @@
+WITH FUNCTION twice(n NUMBER) RETURN NUMBER IS
+BEGIN
+  RETURN n * 2;
+END;
+SELECT twice(21) FROM dual
+/
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- file header
+-- more
+
+BEGIN
+  NULL;
+END;
\ No newline at end of file
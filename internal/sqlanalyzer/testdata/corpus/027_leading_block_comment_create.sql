@@ -0,0 +1,4 @@
+/* deploy script
+   version 2 */
+CREATE OR REPLACE PROCEDURE p AS BEGIN NULL; END;
+/
\ No newline at end of file
@@ -0,0 +1,4 @@
+UPDATE t SET a = 1
+/
+DELETE FROM t WHERE a = 2
+/
\ No newline at end of file
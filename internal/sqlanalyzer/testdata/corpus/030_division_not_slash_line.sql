@@ -0,0 +1,3 @@
+SELECT 10 / 2 FROM dual;
+SELECT a
+  / b FROM t;
\ No newline at end of file
@@ -0,0 +1,5 @@
+SELECT 1 FROM dual;
+/*
+/
+*/
+SELECT 2 FROM dual;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- nothing to run
+/* still nothing */
@@ -0,0 +1,2 @@
+SELECT 1 FROM dual; -- first
+SELECT 2 FROM dual; -- second
\ No newline at end of file
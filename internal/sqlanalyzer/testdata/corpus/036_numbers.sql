@@ -0,0 +1 @@
+SELECT 1, 1.5, .5, 2e10, 3.1E-2, 4f, 5.0d, 1..3 FROM dual
\ No newline at end of file
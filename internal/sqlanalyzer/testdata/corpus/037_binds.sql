@@ -0,0 +1 @@
+UPDATE emp SET sal = :new_sal WHERE id = :1 AND code = :"Code";
\ No newline at end of file
@@ -0,0 +1 @@
+BEGIN x := a || b; y := a ** 2; IF a <> b AND a != c AND a ^= d AND a >= e THEN f(p => 1); END IF; END;
\ No newline at end of file
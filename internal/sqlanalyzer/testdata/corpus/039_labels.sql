@@ -0,0 +1,4 @@
+<<outer>>
+BEGIN
+  NULL;
+END outer;
\ No newline at end of file
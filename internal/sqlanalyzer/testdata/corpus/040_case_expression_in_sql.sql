@@ -0,0 +1,2 @@
+SELECT CASE WHEN a = 1 THEN 'end;' ELSE 'x' END FROM t;
+SELECT 'done' FROM dual;
\ No newline at end of file
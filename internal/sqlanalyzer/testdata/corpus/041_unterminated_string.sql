@@ -0,0 +1 @@
+SELECT 'never closed; DROP TABLE x FROM dual;
\ No newline at end of file
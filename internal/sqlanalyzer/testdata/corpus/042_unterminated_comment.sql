@@ -0,0 +1 @@
+SELECT 1 FROM dual; /* open comment ; DROP TABLE x
\ No newline at end of file
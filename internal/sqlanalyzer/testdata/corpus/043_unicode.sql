@@ -0,0 +1,2 @@
+SELECT 名前, 'naïve; ☃' FROM 社員 WHERE 部門 = 'é';
+SELECT 1 FROM dual;
\ No newline at end of file
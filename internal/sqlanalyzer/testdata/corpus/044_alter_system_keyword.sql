@@ -0,0 +1 @@
+ALTER /* quiet */ SYSTEM SET open_cursors = 500 SCOPE = BOTH;
\ No newline at end of file
@@ -0,0 +1 @@
+SELECT dropped_at, "DROP" FROM drop_log
\ No newline at end of file
@@ -0,0 +1,2 @@
+SELECT begin_date, end_date FROM periods;
+SELECT 2 FROM dual;
\ No newline at end of file
@@ -0,0 +1,3 @@
+MERGE INTO t USING s ON (t.id = s.id)
+WHEN MATCHED THEN UPDATE SET t.v = s.v
+WHEN NOT MATCHED THEN INSERT (id, v) VALUES (s.id, s.v);
\ No newline at end of file
@@ -0,0 +1 @@
+EXPLAIN PLAN FOR DELETE FROM employees WHERE id = 1;
\ No newline at end of file
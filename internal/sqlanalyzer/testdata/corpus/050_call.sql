@@ -0,0 +1,2 @@
+CALL dbms_stats.gather_table_stats('HR', 'EMPLOYEES');
+EXEC_NOT_SQLPLUS;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- setup
+CREATE SEQUENCE s START WITH 1;
+CREATE TABLE t (id NUMBER DEFAULT s.NEXTVAL, txt VARCHAR2(10) DEFAULT 'a;b');
+DECLARE
+  v t.txt%TYPE;
+BEGIN
+  INSERT INTO t (txt) VALUES ('x');
+END;
+/
+SELECT * FROM t;
+TRUNCATE TABLE t;
+DROP TABLE t PURGE;
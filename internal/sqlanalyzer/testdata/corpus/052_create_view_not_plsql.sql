@@ -0,0 +1,2 @@
+CREATE OR REPLACE VIEW v AS SELECT 'procedure' AS kind FROM dual;
+SELECT * FROM v;
\ No newline at end of file
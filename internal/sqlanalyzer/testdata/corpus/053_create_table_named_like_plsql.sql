@@ -0,0 +1,2 @@
+CREATE TABLE procedure_log (id NUMBER, function_name VARCHAR2(30));
+CREATE TABLE package_list (id NUMBER);
\ No newline at end of file
@@ -0,0 +1,2 @@
+GRANT SELECT ON hr.employees TO reporting;
+REVOKE DELETE ON hr.employees FROM reporting;
\ No newline at end of file
@@ -4,12 +4,17 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/alvin/oracle-mcp-server/internal/audit"
 	"github.com/alvin/oracle-mcp-server/internal/config"
+	"github.com/alvin/oracle-mcp-server/internal/confirm"
 	"github.com/alvin/oracle-mcp-server/internal/mcp"
 )
 
@@ -20,6 +25,15 @@ var (
 )
 
 func main() {
+	transport := flag.String("transport", "", `override server.transport ("stdio" or "http")`)
+	listen := flag.String("listen", "", "override server.listen for the http transport (host:port)")
+	verifyAudit := flag.String("verify-audit", "", "verify the hash chain of the JSONL audit log at this path (logging.log_file) and exit")
+	flag.Parse()
+
+	if *verifyAudit != "" {
+		os.Exit(runVerifyAudit(*verifyAudit))
+	}
+
 	// Handle signals for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -33,25 +47,68 @@ func main() {
 	}()
 
 	// Run the server
-	if err := run(ctx); err != nil {
+	if err := run(ctx, *transport, *listen); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func run(ctx context.Context) error {
+func run(ctx context.Context, transport, listen string) error {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	if transport != "" || listen != "" {
+		if transport != "" {
+			cfg.Server.Transport = strings.ToLower(strings.TrimSpace(transport))
+		}
+		if listen != "" {
+			cfg.Server.Listen = listen
+		}
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+	}
 
 	// Create and start MCP server
-	server, err := mcp.NewServer(cfg)
+	server, err := mcp.NewServer(cfg, newConfirmer(cfg))
 	if err != nil {
 		return fmt.Errorf("failed to create server: %w", err)
 	}
 
+	if cfg.Server.Transport == "http" {
+		// Serve HTTP clients until the context is cancelled
+		return server.RunHTTP(ctx)
+	}
 	// Run the server (blocks until context is cancelled or stdin is closed)
 	return server.Run(ctx)
 }
+
+// runVerifyAudit checks the JSONL audit log at logFile and its rotated files and returns the exit code:
+// 0 when the chain is intact, 1 when a record was edited, removed or cut short.
+func runVerifyAudit(logFile string) int {
+	res, err := audit.Verify(logFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Audit log verification failed: %v\n", err)
+		return 1
+	}
+	for _, path := range res.Skipped {
+		fmt.Printf("Skipped %s (text format, no hashes)\n", path)
+	}
+	fmt.Printf("OK: %d records in %d files, seq %d to %d\n", res.Records, res.Files, res.FirstSeq, res.LastSeq)
+	if res.FirstSeq > 1 {
+		fmt.Printf("The chain starts at record %d; older files were removed.\n", res.FirstSeq)
+	}
+	fmt.Printf("Head: %s (compare with an earlier run to detect records removed from the end)\n", res.Head)
+	return 0
+}
+
+// newConfirmer returns the review backend for security.confirm_mode. "elicitation" is layered on top
+// by mcp.NewServer, with this confirmer (the OS dialog) as its fallback.
+func newConfirmer(cfg *config.Config) confirm.Confirmer {
+	if cfg.Security.ConfirmMode == "browser" {
+		return confirm.NewBrowserConfirmer(time.Duration(cfg.Security.BrowserConfirmTimeoutSeconds) * time.Second)
+	}
+	return confirm.NewDialogConfirmer()
+}